@@ -39,6 +39,8 @@ import (
 	"github.com/kubernetes-sigs/federation-v2/pkg/controller/ingressdns"
 	"github.com/kubernetes-sigs/federation-v2/pkg/controller/schedulingmanager"
 	"github.com/kubernetes-sigs/federation-v2/pkg/controller/servicedns"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/webhook"
 	"github.com/kubernetes-sigs/federation-v2/pkg/features"
 	"github.com/kubernetes-sigs/federation-v2/pkg/inject"
 	"github.com/kubernetes-sigs/federation-v2/pkg/version"
@@ -104,6 +106,11 @@ func Run(opts *options.Options) error {
 		glog.Info("Federation will target all namespaces")
 	}
 
+	if opts.ClusterWriteQPS > 0 {
+		opts.Config.ClusterWriteRateLimiter = util.NewClusterRateLimiter(opts.ClusterWriteQPS, opts.ClusterWriteBurst)
+		glog.Infof("Writes to each member cluster will be limited to %v per second", opts.ClusterWriteQPS)
+	}
+
 	if err := federatedcluster.StartClusterController(opts.Config, stopChan, opts.ClusterMonitorPeriod); err != nil {
 		glog.Fatalf("Error starting cluster controller: %v", err)
 	}
@@ -134,12 +141,34 @@ func Run(opts *options.Options) error {
 		}
 	}
 
+	// The PlacementPolicies and namespaces are cached once for the
+	// sync controllers of all federated types and the admission
+	// webhooks.  Policies are cluster-scoped and so are not supported
+	// if federation is limited to a single namespace.
+	var placementPolicyManager *util.PlacementPolicyManager
+	if !opts.LimitedScope {
+		var err error
+		placementPolicyManager, err = util.NewPlacementPolicyManager(opts.Config.KubeConfig)
+		if err != nil {
+			glog.Fatalf("Error creating placement policy manager: %v", err)
+		}
+		placementPolicyManager.Run(stopChan)
+	}
+
 	if utilfeature.DefaultFeatureGate.Enabled(features.PushReconciler) {
-		if err := federatedtypeconfig.StartController(opts.Config, stopChan); err != nil {
+		if err := federatedtypeconfig.StartController(opts.Config, stopChan, placementPolicyManager); err != nil {
 			glog.Fatalf("Error starting federated type config controller: %v", err)
 		}
 	}
 
+	if len(opts.Webhook.CertDir) > 0 {
+		if opts.LimitedScope {
+			glog.Warning("Admission webhooks will not be served since they cannot be limited to the federation namespace")
+		} else if err := webhook.StartWebhookServer(opts.Config, opts.Webhook, placementPolicyManager, stopChan); err != nil {
+			glog.Fatalf("Error starting admission webhook server: %v", err)
+		}
+	}
+
 	// Blockforever
 	select {}
 }
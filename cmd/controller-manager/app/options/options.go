@@ -26,6 +26,7 @@ import (
 	flagutil "k8s.io/apiserver/pkg/util/flag"
 
 	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/webhook"
 )
 
 // Options contains everything necessary to create and run controller-manager.
@@ -35,6 +36,9 @@ type Options struct {
 	ClusterMonitorPeriod time.Duration
 	LimitedScope         bool
 	InstallCRDs          bool
+	ClusterWriteQPS      float32
+	ClusterWriteBurst    int
+	Webhook              webhook.ServerOptions
 }
 
 // AddFlags adds flags to fs and binds them to options.
@@ -47,6 +51,15 @@ func (o *Options) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.Config.ClusterNamespace, "registry-namespace", util.MulticlusterPublicNamespace, "The cluster registry namespace.")
 	fs.DurationVar(&o.Config.ClusterAvailableDelay, "cluster-available-delay", util.DefaultClusterAvailableDelay, "Time to wait before reconciling on a healthy cluster.")
 	fs.DurationVar(&o.Config.ClusterUnavailableDelay, "cluster-unavailable-delay", util.DefaultClusterUnavailableDelay, "Time to wait before giving up on an unhealthy cluster.")
+	fs.IntVar(&o.Config.MaxConcurrentSyncReconciles, "max-concurrent-sync-reconciles", 1, "The number of federated resources of each type that may be reconciled concurrently.")
+	fs.IntVar(&o.Config.MaxConcurrentClusterOperations, "max-concurrent-cluster-operations", 0, "The number of member clusters a federated resource may be written to concurrently. Not limited if 0.")
+	fs.Float32Var(&o.ClusterWriteQPS, "cluster-write-qps", 0, "The maximum rate of writes to each member cluster. Not limited if 0.")
+	fs.IntVar(&o.ClusterWriteBurst, "cluster-write-burst", 10, "The maximum burst of writes to each member cluster when --cluster-write-qps is set.")
+
+	fs.StringVar(&o.Webhook.CertDir, "webhook-cert-dir", "", "The directory containing the tls.crt and tls.key used to serve admission webhooks, and the ca.crt used to verify them. Admission webhooks are not served if not set.")
+	fs.IntVar(&o.Webhook.Port, "webhook-port", 8443, "The port to serve admission webhooks on.")
+	fs.StringVar(&o.Webhook.ServiceName, "webhook-service-name", "federation-controller-manager-webhook", "The name of the service in the federation namespace that routes to the admission webhooks.")
+	fs.BoolVar(&o.Webhook.ValidateTemplates, "validate-templates", false, "Whether the template of a federated resource is validated against the schema of its target type by the admission webhook.")
 
 	fs.BoolVar(&o.LimitedScope, "limited-scope", false, "Whether the federation namespace will be the only target for federation.")
 	fs.DurationVar(&o.ClusterMonitorPeriod, "cluster-monitor-period", time.Second*40, "How often to monitor the cluster health")
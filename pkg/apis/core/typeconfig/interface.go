@@ -18,6 +18,8 @@ package typeconfig
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
 )
 
 // Interface defines how to interact with a FederatedTypeConfig
@@ -29,5 +31,16 @@ type Interface interface {
 	GetFederatedType() metav1.APIResource
 	GetStatus() *metav1.APIResource
 	GetEnableStatus() bool
+	GetStatusAggregator() v1alpha1.StatusAggregator
+	GetStatusFields() []string
+	GetIgnoredStatusFields() []string
+	GetStatusMode() v1alpha1.StatusMode
 	GetFederatedNamespaced() bool
+	GetRetainedFields() []v1alpha1.RetainedField
+	GetUpdateWebhook() *v1alpha1.UpdateWebhook
+	GetPropagationMode() v1alpha1.PropagationMode
+	GetDriftPolicy() v1alpha1.DriftPolicy
+	GetRevisionHistoryLimit() int32
+	GetConflictPolicy() v1alpha1.ConflictPolicy
+	GetClusterCache() v1alpha1.ClusterCacheMode
 }
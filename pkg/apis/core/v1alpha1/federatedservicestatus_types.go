@@ -23,8 +23,14 @@ import (
 
 // FederatedServiceClusterStatus is the observed status of the resource for a named cluster
 type FederatedServiceClusterStatus struct {
-	ClusterName string               `json:"clusterName,omitempty"`
-	Status      corev1.ServiceStatus `json:"status,omitempty"`
+	ClusterName string `json:"clusterName,omitempty"`
+	// The state of the resource in the cluster.  One of Present,
+	// NoStatus, Missing, NotPlaced or ClusterNotReady.
+	State  string               `json:"state,omitempty"`
+	Status corev1.ServiceStatus `json:"status,omitempty"`
+	// The time at which the status was last observed to change in
+	// the cluster.
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
 }
 
 // +genclient
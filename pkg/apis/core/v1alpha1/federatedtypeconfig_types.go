@@ -51,6 +51,217 @@ type FederatedTypeConfigSpec struct {
 	// Whether or not Status object should be populated.
 	// +optional
 	EnableStatus bool `json:"enableStatus,omitempty"`
+	// How the status of target resources in member clusters is
+	// aggregated into the status object of a federated resource
+	// alongside the status for each cluster.  One of Deployment,
+	// ReplicaSet, Job or Service.  The status is not aggregated if
+	// not set.
+	// +optional
+	StatusAggregator StatusAggregator `json:"statusAggregator,omitempty"`
+	// Dot-separated paths of the fields of the status of target
+	// resources that are collected from member clusters (e.g.
+	// readyReplicas or loadBalancer.ingress).  The entire status is
+	// collected if not set.  A status aggregator only sees the
+	// collected fields.
+	// +optional
+	StatusFields []string `json:"statusFields,omitempty"`
+	// Dot-separated paths of collected status fields whose changes
+	// alone do not update the collected status (e.g. fields recording
+	// timestamps).  The last recorded value of an ignored field is
+	// reported until another field of the status changes.
+	// +optional
+	IgnoredStatusFields []string `json:"ignoredStatusFields,omitempty"`
+	// Where the status of a federated resource is written.  One of
+	// Object or Subresource.  Defaults to Object.  The Subresource
+	// mode requires the CRD of the federated type to enable the status
+	// subresource.
+	// +optional
+	StatusMode StatusMode `json:"statusMode,omitempty"`
+	// Fields of the target type whose values in member clusters
+	// should be retained when target resources are updated.  Fields
+	// populated by controllers in member clusters (e.g. the
+	// volumeName of a PersistentVolumeClaim) should be retained to
+	// avoid the sync controller continually clearing them.
+	// +optional
+	RetainedFields []RetainedField `json:"retainedFields,omitempty"`
+	// A webhook that is called to compute the object used to update a
+	// target resource.  It is called after retained fields have been
+	// applied.
+	// +optional
+	UpdateWebhook *UpdateWebhook `json:"updateWebhook,omitempty"`
+	// How target resources are updated in member clusters.  One of
+	// Replace or Merge.  Defaults to Replace.
+	// +optional
+	PropagationMode PropagationMode `json:"propagationMode,omitempty"`
+	// How changes made in member clusters to propagated target
+	// resources are handled.  One of Correct or Report.  Defaults to
+	// Correct.  Can be overridden for a federated resource by the
+	// federation.k8s.io/drift-policy annotation.
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+	// The number of revisions of the template and overrides of each
+	// federated resource to retain for rollback.  Revisions are
+	// recorded as ControllerRevisions.  Defaults to 0, which disables
+	// the recording of revisions.
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+	// How a resource in a member cluster that has the name of a
+	// federated resource but is not managed by federation is handled.
+	// One of Adopt, Skip or Fail.  Defaults to Adopt.  Can be
+	// overridden for a federated resource by the
+	// federation.k8s.io/conflict-policy annotation.
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+	// How target resources in member clusters are cached by the sync
+	// controller.  One of Trimmed or Full.  Defaults to Trimmed.
+	// +optional
+	ClusterCache ClusterCacheMode `json:"clusterCache,omitempty"`
+}
+
+// PropagationMode determines how the sync controller updates target
+// resources in member clusters.
+type PropagationMode string
+
+const (
+	// PropagationModeReplace replaces the target resource with the
+	// desired resource, reverting changes made in the member cluster
+	// to fields other than retained fields.
+	PropagationModeReplace PropagationMode = "Replace"
+	// PropagationModeMerge patches only the fields of the target
+	// resource that were set by federation.  The last desired state
+	// propagated to a member cluster is recorded in an annotation on
+	// the target resource and used to compute a three-way merge
+	// patch, so fields set in the member cluster (e.g. by admission
+	// webhooks or controllers) are left alone.
+	PropagationModeMerge PropagationMode = "Merge"
+)
+
+// DriftPolicy determines how the sync controller handles drift: a
+// change made in a member cluster to a target resource after it was
+// propagated.
+type DriftPolicy string
+
+const (
+	// DriftPolicyCorrect reverts drift by updating the target resource.
+	DriftPolicyCorrect DriftPolicy = "Correct"
+	// DriftPolicyReport records drift in the status of the federated
+	// resource and emits an event without updating the target
+	// resource.  Changes to the federated resource are still
+	// propagated.
+	DriftPolicyReport DriftPolicy = "Report"
+)
+
+// ConflictPolicy determines how the sync controller handles a
+// pre-existing resource in a member cluster that is not managed by
+// federation.  Resources propagated by federation are labeled as
+// managed.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyAdopt adopts the resource by updating it to the
+	// desired state and labeling it as managed.
+	ConflictPolicyAdopt ConflictPolicy = "Adopt"
+	// ConflictPolicySkip leaves the resource alone and records the
+	// conflict in the status of the federated resource.  The
+	// federated resource is still propagated to other clusters.
+	ConflictPolicySkip ConflictPolicy = "Skip"
+	// ConflictPolicyFail leaves the resource alone and fails
+	// propagation of the federated resource to all clusters.
+	ConflictPolicyFail ConflictPolicy = "Fail"
+)
+
+// ClusterCacheMode determines which target resources in member
+// clusters the sync controller caches, and which of their fields.
+type ClusterCacheMode string
+
+const (
+	// ClusterCacheTrimmed caches only the target resources labeled as
+	// managed by federation, and only their metadata, status, replica
+	// count and retained fields.  The full target resource is
+	// retrieved from the member cluster when required, e.g. to report
+	// drift or to detect a conflict with an unmanaged resource.  The
+	// full target resource is always cached for the Merge propagation
+	// mode or if an update webhook is configured.
+	ClusterCacheTrimmed ClusterCacheMode = "Trimmed"
+	// ClusterCacheFull caches all target resources in full.
+	ClusterCacheFull ClusterCacheMode = "Full"
+)
+
+// StatusAggregator identifies a built-in aggregation of the status of
+// target resources in member clusters.
+type StatusAggregator string
+
+const (
+	// StatusAggregatorDeployment sums the replica counts of
+	// Deployments and reports whether any are unavailable.
+	StatusAggregatorDeployment StatusAggregator = "Deployment"
+	// StatusAggregatorReplicaSet sums the replica counts of
+	// ReplicaSets and reports whether any are not ready.
+	StatusAggregatorReplicaSet StatusAggregator = "ReplicaSet"
+	// StatusAggregatorJob sums the pod counts of Jobs and reports
+	// whether any have failed.
+	StatusAggregatorJob StatusAggregator = "Job"
+	// StatusAggregatorService combines the load balancer ingress
+	// points of Services.
+	StatusAggregatorService StatusAggregator = "Service"
+)
+
+// StatusMode determines where the status of a federated resource is
+// written.
+type StatusMode string
+
+const (
+	// StatusModeObject writes the propagation status with a regular
+	// update of the federated resource and the status collected from
+	// member clusters to a separate status object owned by the
+	// federated resource.
+	StatusModeObject StatusMode = "Object"
+	// StatusModeSubresource writes both the propagation status and
+	// the status collected from member clusters to the status
+	// subresource of the federated resource.  Status updates do not
+	// change the generation of the resource, so the observed
+	// generation is reported, and the conditions of the collected
+	// status are reported alongside the propagation conditions (e.g.
+	// for use with `kubectl wait`).
+	StatusModeSubresource StatusMode = "Subresource"
+)
+
+// RetainedField identifies a field whose value in a member cluster
+// should be retained when the target resource is updated.  A value is
+// retained if the resource in the member cluster has a non-empty
+// value for the field.
+type RetainedField struct {
+	// Dot-separated path of the field (e.g. spec.clusterIP).  A path
+	// element suffixed with '[]' indicates a list whose elements will
+	// be matched by MatchKeys (e.g. spec.ports[].nodePort).
+	Path string `json:"path"`
+	// Fields used to match elements of the lists in Path between the
+	// desired resource and the resource in the member cluster.  If not
+	// provided, list elements are matched by index.
+	// +optional
+	MatchKeys []string `json:"matchKeys,omitempty"`
+	// Whether to retain the value only if the desired resource does
+	// not specify a non-empty value.
+	// +optional
+	OnlyIfUnset bool `json:"onlyIfUnset,omitempty"`
+}
+
+// UpdateWebhook configures a webhook that computes the object used to
+// update a target resource in a member cluster.
+//
+// The webhook receives an HTTP POST of an UpdateWebhookRequest and
+// should respond with an UpdateWebhookResponse.
+type UpdateWebhook struct {
+	// The URL of the webhook.
+	URL string `json:"url"`
+	// PEM-encoded CA bundle used to verify the certificate of the
+	// webhook.  If not provided, the system trust roots are used.
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
+	// How long to wait for a response from the webhook.  Defaults to
+	// 10 seconds.
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
 }
 
 // APIResource defines how to configure the dynamic client for an API resource.
@@ -191,6 +402,68 @@ func (f *FederatedTypeConfig) GetEnableStatus() bool {
 	return f.Spec.EnableStatus
 }
 
+func (f *FederatedTypeConfig) GetRetainedFields() []RetainedField {
+	return f.Spec.RetainedFields
+}
+
+func (f *FederatedTypeConfig) GetUpdateWebhook() *UpdateWebhook {
+	return f.Spec.UpdateWebhook
+}
+
+func (f *FederatedTypeConfig) GetPropagationMode() PropagationMode {
+	if len(f.Spec.PropagationMode) == 0 {
+		return PropagationModeReplace
+	}
+	return f.Spec.PropagationMode
+}
+
+func (f *FederatedTypeConfig) GetDriftPolicy() DriftPolicy {
+	if len(f.Spec.DriftPolicy) == 0 {
+		return DriftPolicyCorrect
+	}
+	return f.Spec.DriftPolicy
+}
+
+func (f *FederatedTypeConfig) GetRevisionHistoryLimit() int32 {
+	if f.Spec.RevisionHistoryLimit == nil {
+		return 0
+	}
+	return *f.Spec.RevisionHistoryLimit
+}
+
+func (f *FederatedTypeConfig) GetConflictPolicy() ConflictPolicy {
+	if len(f.Spec.ConflictPolicy) == 0 {
+		return ConflictPolicyAdopt
+	}
+	return f.Spec.ConflictPolicy
+}
+
+func (f *FederatedTypeConfig) GetStatusAggregator() StatusAggregator {
+	return f.Spec.StatusAggregator
+}
+
+func (f *FederatedTypeConfig) GetStatusFields() []string {
+	return f.Spec.StatusFields
+}
+
+func (f *FederatedTypeConfig) GetIgnoredStatusFields() []string {
+	return f.Spec.IgnoredStatusFields
+}
+
+func (f *FederatedTypeConfig) GetStatusMode() StatusMode {
+	if len(f.Spec.StatusMode) == 0 {
+		return StatusModeObject
+	}
+	return f.Spec.StatusMode
+}
+
+func (f *FederatedTypeConfig) GetClusterCache() ClusterCacheMode {
+	if len(f.Spec.ClusterCache) == 0 {
+		return ClusterCacheTrimmed
+	}
+	return f.Spec.ClusterCache
+}
+
 // TODO(marun) Remove in favor of using 'true' for namespaces and the
 // value from target otherwise.
 func (f *FederatedTypeConfig) GetFederatedNamespaced() bool {
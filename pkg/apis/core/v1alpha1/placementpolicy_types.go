@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PlacementPolicySpec defines the federated resources a
+// PlacementPolicy applies to and how it affects their placement.
+type PlacementPolicySpec struct {
+	// Selects the namespaces containing the federated resources the
+	// policy applies to by the labels of the namespace.  If not
+	// provided, the policy applies to federated resources in all
+	// namespaces and to cluster-scoped federated resources.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Selects the federated resources the policy applies to by their
+	// labels.  If not provided, the policy applies to all federated
+	// resources selected by NamespaceSelector.
+	// +optional
+	ResourceSelector *metav1.LabelSelector `json:"resourceSelector,omitempty"`
+	// Constrains placement to the clusters whose labels match the
+	// selector.  If not provided, placement is not constrained by
+	// cluster labels.
+	// +optional
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+	// The names of clusters that may never be selected.
+	// +optional
+	ExcludedClusters []string `json:"excludedClusters,omitempty"`
+	// The placement of a federated resource that specifies neither
+	// clusterNames nor a clusterSelector.
+	// +optional
+	DefaultPlacement *DefaultPlacement `json:"defaultPlacement,omitempty"`
+}
+
+// DefaultPlacement is the placement applied by a PlacementPolicy to a
+// federated resource that does not specify placement.
+type DefaultPlacement struct {
+	// +optional
+	ClusterNames []string `json:"clusterNames,omitempty"`
+	// +optional
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient:nonNamespaced
+
+// PlacementPolicy constrains or defaults the placement of the
+// federated resources it selects.  The clusters selected for a
+// federated resource are the intersection of its own placement and
+// the constraints of every policy that applies to it.
+//
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=placementpolicies
+type PlacementPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PlacementPolicySpec `json:"spec,omitempty"`
+}
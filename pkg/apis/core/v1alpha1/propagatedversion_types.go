@@ -40,6 +40,13 @@ type ClusterObjectVersion struct {
 	// The last version produced for the resource by a federation
 	// operation.
 	Version string `json:"version,omitempty"`
+	// The generation of the federated resource from which the
+	// version was produced.  Only recorded if the status of the
+	// federated type is written to its status subresource.
+	Generation int64 `json:"generation,omitempty"`
+	// The hash of the object rendered for the cluster.  Only
+	// recorded if the federated resource renders cluster variables.
+	RenderedVersion string `json:"renderedVersion,omitempty"`
 }
 
 // +genclient
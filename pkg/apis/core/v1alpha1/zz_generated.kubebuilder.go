@@ -52,6 +52,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&FederatedServiceStatusList{},
 		&FederatedTypeConfig{},
 		&FederatedTypeConfigList{},
+		&PlacementPolicy{},
+		&PlacementPolicyList{},
 		&PropagatedVersion{},
 		&PropagatedVersionList{},
 	)
@@ -93,6 +95,14 @@ type FederatedTypeConfigList struct {
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+type PlacementPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PlacementPolicy `json:"items"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 type PropagatedVersionList struct {
 	metav1.TypeMeta `json:",inline"`
 	metav1.ListMeta `json:"metadata,omitempty"`
@@ -150,6 +160,10 @@ var (
 												"clusterName": v1beta1.JSONSchemaProps{
 													Type: "string",
 												},
+												"generation": v1beta1.JSONSchemaProps{
+													Type:   "integer",
+													Format: "int64",
+												},
 												"version": v1beta1.JSONSchemaProps{
 													Type: "string",
 												},
@@ -291,6 +305,13 @@ var (
 										"clusterName": v1beta1.JSONSchemaProps{
 											Type: "string",
 										},
+										"lastUpdateTime": v1beta1.JSONSchemaProps{
+											Type:   "string",
+											Format: "date-time",
+										},
+										"state": v1beta1.JSONSchemaProps{
+											Type: "string",
+										},
 										"status": v1beta1.JSONSchemaProps{
 											Type:       "object",
 											Properties: map[string]v1beta1.JSONSchemaProps{},
@@ -338,6 +359,15 @@ var (
 						"spec": v1beta1.JSONSchemaProps{
 							Type: "object",
 							Properties: map[string]v1beta1.JSONSchemaProps{
+								"clusterCache": v1beta1.JSONSchemaProps{
+									Type: "string",
+								},
+								"conflictPolicy": v1beta1.JSONSchemaProps{
+									Type: "string",
+								},
+								"driftPolicy": v1beta1.JSONSchemaProps{
+									Type: "string",
+								},
 								"enableStatus": v1beta1.JSONSchemaProps{
 									Type: "boolean",
 								},
@@ -360,12 +390,53 @@ var (
 									Required: []string{
 										"kind",
 									}},
+								"ignoredStatusFields": v1beta1.JSONSchemaProps{
+									Type: "array",
+									Items: &v1beta1.JSONSchemaPropsOrArray{
+										Schema: &v1beta1.JSONSchemaProps{
+											Type: "string",
+										},
+									},
+								},
 								"namespaced": v1beta1.JSONSchemaProps{
 									Type: "boolean",
 								},
 								"propagationEnabled": v1beta1.JSONSchemaProps{
 									Type: "boolean",
 								},
+								"propagationMode": v1beta1.JSONSchemaProps{
+									Type: "string",
+								},
+								"retainedFields": v1beta1.JSONSchemaProps{
+									Type: "array",
+									Items: &v1beta1.JSONSchemaPropsOrArray{
+										Schema: &v1beta1.JSONSchemaProps{
+											Type: "object",
+											Properties: map[string]v1beta1.JSONSchemaProps{
+												"matchKeys": v1beta1.JSONSchemaProps{
+													Type: "array",
+													Items: &v1beta1.JSONSchemaPropsOrArray{
+														Schema: &v1beta1.JSONSchemaProps{
+															Type: "string",
+														},
+													},
+												},
+												"onlyIfUnset": v1beta1.JSONSchemaProps{
+													Type: "boolean",
+												},
+												"path": v1beta1.JSONSchemaProps{
+													Type: "string",
+												},
+											},
+											Required: []string{
+												"path",
+											}},
+									},
+								},
+								"revisionHistoryLimit": v1beta1.JSONSchemaProps{
+									Type:   "integer",
+									Format: "int32",
+								},
 								"status": v1beta1.JSONSchemaProps{
 									Type: "object",
 									Properties: map[string]v1beta1.JSONSchemaProps{
@@ -385,6 +456,20 @@ var (
 									Required: []string{
 										"kind",
 									}},
+								"statusAggregator": v1beta1.JSONSchemaProps{
+									Type: "string",
+								},
+								"statusFields": v1beta1.JSONSchemaProps{
+									Type: "array",
+									Items: &v1beta1.JSONSchemaPropsOrArray{
+										Schema: &v1beta1.JSONSchemaProps{
+											Type: "string",
+										},
+									},
+								},
+								"statusMode": v1beta1.JSONSchemaProps{
+									Type: "string",
+								},
 								"target": v1beta1.JSONSchemaProps{
 									Type: "object",
 									Properties: map[string]v1beta1.JSONSchemaProps{
@@ -404,6 +489,24 @@ var (
 									Required: []string{
 										"kind",
 									}},
+								"updateWebhook": v1beta1.JSONSchemaProps{
+									Type: "object",
+									Properties: map[string]v1beta1.JSONSchemaProps{
+										"caBundle": v1beta1.JSONSchemaProps{
+											Type:   "string",
+											Format: "byte",
+										},
+										"timeoutSeconds": v1beta1.JSONSchemaProps{
+											Type:   "integer",
+											Format: "int32",
+										},
+										"url": v1beta1.JSONSchemaProps{
+											Type: "string",
+										},
+									},
+									Required: []string{
+										"url",
+									}},
 							},
 							Required: []string{
 								"target",
@@ -435,6 +538,74 @@ var (
 		},
 	}
 	// Define CRDs for resources
+	PlacementPolicyCRD = v1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "placementpolicies.core.federation.k8s.io",
+		},
+		Spec: v1beta1.CustomResourceDefinitionSpec{
+			Group:   "core.federation.k8s.io",
+			Version: "v1alpha1",
+			Names: v1beta1.CustomResourceDefinitionNames{
+				Kind:   "PlacementPolicy",
+				Plural: "placementpolicies",
+			},
+			Scope: "Cluster",
+			Validation: &v1beta1.CustomResourceValidation{
+				OpenAPIV3Schema: &v1beta1.JSONSchemaProps{
+					Properties: map[string]v1beta1.JSONSchemaProps{
+						"apiVersion": v1beta1.JSONSchemaProps{
+							Type: "string",
+						},
+						"kind": v1beta1.JSONSchemaProps{
+							Type: "string",
+						},
+						"metadata": v1beta1.JSONSchemaProps{
+							Type: "object",
+						},
+						"spec": v1beta1.JSONSchemaProps{
+							Type: "object",
+							Properties: map[string]v1beta1.JSONSchemaProps{
+								"clusterSelector": v1beta1.JSONSchemaProps{
+									Type: "object",
+								},
+								"defaultPlacement": v1beta1.JSONSchemaProps{
+									Type: "object",
+									Properties: map[string]v1beta1.JSONSchemaProps{
+										"clusterNames": v1beta1.JSONSchemaProps{
+											Type: "array",
+											Items: &v1beta1.JSONSchemaPropsOrArray{
+												Schema: &v1beta1.JSONSchemaProps{
+													Type: "string",
+												},
+											},
+										},
+										"clusterSelector": v1beta1.JSONSchemaProps{
+											Type: "object",
+										},
+									},
+								},
+								"excludedClusters": v1beta1.JSONSchemaProps{
+									Type: "array",
+									Items: &v1beta1.JSONSchemaPropsOrArray{
+										Schema: &v1beta1.JSONSchemaProps{
+											Type: "string",
+										},
+									},
+								},
+								"namespaceSelector": v1beta1.JSONSchemaProps{
+									Type: "object",
+								},
+								"resourceSelector": v1beta1.JSONSchemaProps{
+									Type: "object",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	// Define CRDs for resources
 	PropagatedVersionCRD = v1beta1.CustomResourceDefinition{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "propagatedversions.core.federation.k8s.io",
@@ -475,6 +646,10 @@ var (
 												"clusterName": v1beta1.JSONSchemaProps{
 													Type: "string",
 												},
+												"generation": v1beta1.JSONSchemaProps{
+													Type:   "integer",
+													Format: "int64",
+												},
 												"version": v1beta1.JSONSchemaProps{
 													Type: "string",
 												},
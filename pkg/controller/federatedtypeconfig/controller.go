@@ -57,6 +57,10 @@ type Controller struct {
 	stopChannels map[string]chan struct{}
 	lock         sync.RWMutex
 
+	// Sources the PlacementPolicies for the sync controllers.  Nil
+	// if federation is limited to a single namespace.
+	placementPolicyManager *util.PlacementPolicyManager
+
 	// Store for the FederatedTypeConfig objects
 	store cache.Store
 	// Informer for the FederatedTypeConfig objects
@@ -66,8 +70,8 @@ type Controller struct {
 }
 
 // StartController starts the Controller for managing FederatedTypeConfig objects.
-func StartController(config *util.ControllerConfig, stopChan <-chan struct{}) error {
-	controller, err := newController(config)
+func StartController(config *util.ControllerConfig, stopChan <-chan struct{}, placementPolicyManager *util.PlacementPolicyManager) error {
+	controller, err := newController(config, placementPolicyManager)
 	if err != nil {
 		return err
 	}
@@ -77,7 +81,7 @@ func StartController(config *util.ControllerConfig, stopChan <-chan struct{}) er
 }
 
 // newController returns a new controller to manage FederatedTypeConfig objects.
-func newController(config *util.ControllerConfig) (*Controller, error) {
+func newController(config *util.ControllerConfig, placementPolicyManager *util.PlacementPolicyManager) (*Controller, error) {
 	userAgent := "FederatedTypeConfig"
 	kubeConfig := config.KubeConfig
 	restclient.AddUserAgent(kubeConfig, userAgent)
@@ -87,9 +91,10 @@ func newController(config *util.ControllerConfig) (*Controller, error) {
 	}
 
 	c := &Controller{
-		controllerConfig: config,
-		client:           genericclient,
-		stopChannels:     make(map[string]chan struct{}),
+		controllerConfig:       config,
+		client:                 genericclient,
+		stopChannels:           make(map[string]chan struct{}),
+		placementPolicyManager: placementPolicyManager,
 	}
 
 	c.worker = util.NewReconcileWorker(c.reconcile, util.WorkerTiming{})
@@ -207,6 +212,20 @@ func (c *Controller) reconcile(qualifiedName util.QualifiedName) util.Reconcilia
 		return util.StatusError
 	}
 
+	// The sync and status controllers are configured from the spec
+	// when they are started, so running controllers are restarted
+	// to apply a change to the spec.
+	if typeConfig.Generation != typeConfig.Status.ObservedGeneration {
+		if syncRunning {
+			c.stopController(typeConfig.Name, syncStopChan)
+			syncRunning = false
+		}
+		if statusRunning {
+			c.stopController(statusKey, statusStopChan)
+			statusRunning = false
+		}
+	}
+
 	startNewSyncController := !syncRunning && syncEnabled
 	stopSyncController := syncRunning && !syncEnabled
 	if startNewSyncController {
@@ -278,7 +297,7 @@ func (c *Controller) startSyncController(tc *corev1a1.FederatedTypeConfig) error
 	}
 	kind := tc.Spec.FederatedType.Kind
 	stopChan := make(chan struct{})
-	err = synccontroller.StartFederationSyncController(c.controllerConfig, stopChan, tc, fedNamespaceAPIResource)
+	err = synccontroller.StartFederationSyncController(c.controllerConfig, stopChan, tc, fedNamespaceAPIResource, c.store, c.placementPolicyManager)
 	if err != nil {
 		close(stopChan)
 		return errors.Wrapf(err, "Error starting sync controller for %q", kind)
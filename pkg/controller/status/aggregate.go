@@ -0,0 +1,277 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kubernetes-sigs/federation-v2/pkg/apis/core/typeconfig"
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+)
+
+// statusAggregator computes the status of a federated resource
+// aggregated from the status of its target resources in member
+// clusters.  Only the status of resources in ready clusters is
+// aggregated, and clusters that do not report a status are ignored.
+// The propagation status of the federated resource determines the
+// clusters in which the resource is placed.
+type statusAggregator func(clusterStatuses []util.ResourceClusterStatus, propagationStatus *util.PropagationStatus) (interface{}, error)
+
+var statusAggregators = map[fedv1a1.StatusAggregator]statusAggregator{
+	fedv1a1.StatusAggregatorDeployment: aggregateDeploymentStatus,
+	fedv1a1.StatusAggregatorReplicaSet: aggregateReplicaSetStatus,
+	fedv1a1.StatusAggregatorJob:        aggregateJobStatus,
+	fedv1a1.StatusAggregatorService:    aggregateServiceStatus,
+}
+
+// statusAggregatorFor returns the status aggregator configured for
+// the given type, or nil if the status of the type is not aggregated.
+func statusAggregatorFor(typeConfig typeconfig.Interface) (statusAggregator, error) {
+	name := typeConfig.GetStatusAggregator()
+	if len(name) == 0 {
+		return nil, nil
+	}
+	aggregator, ok := statusAggregators[name]
+	if !ok {
+		return nil, errors.Errorf("Unknown status aggregator %q", name)
+	}
+	return aggregator, nil
+}
+
+type deploymentAggregatedStatus struct {
+	Replicas            int32                            `json:"replicas"`
+	UpdatedReplicas     int32                            `json:"updatedReplicas"`
+	ReadyReplicas       int32                            `json:"readyReplicas"`
+	AvailableReplicas   int32                            `json:"availableReplicas"`
+	UnavailableReplicas int32                            `json:"unavailableReplicas"`
+	Conditions          []util.AggregatedStatusCondition `json:"conditions"`
+}
+
+// aggregateDeploymentStatus sums the replica counts of Deployments.
+// A Deployment is degraded if it has unavailable replicas or is not
+// available.
+func aggregateDeploymentStatus(clusterStatuses []util.ResourceClusterStatus, propagationStatus *util.PropagationStatus) (interface{}, error) {
+	aggregated := &deploymentAggregatedStatus{}
+	health := &healthAggregation{}
+	for _, clusterStatus := range clusterStatuses {
+		if !health.observeState(clusterStatus, propagationStatus) {
+			continue
+		}
+		status := appsv1.DeploymentStatus{}
+		err := decodeClusterStatus(clusterStatus, &status)
+		if err != nil {
+			return nil, err
+		}
+		aggregated.Replicas += status.Replicas
+		aggregated.UpdatedReplicas += status.UpdatedReplicas
+		aggregated.ReadyReplicas += status.ReadyReplicas
+		aggregated.AvailableReplicas += status.AvailableReplicas
+		aggregated.UnavailableReplicas += status.UnavailableReplicas
+
+		degraded := status.UnavailableReplicas > 0
+		for _, condition := range status.Conditions {
+			if condition.Type == appsv1.DeploymentAvailable && condition.Status == apiv1.ConditionFalse {
+				degraded = true
+			}
+		}
+		health.observe(clusterStatus.ClusterName, degraded)
+	}
+	aggregated.Conditions = []util.AggregatedStatusCondition{health.condition()}
+	return aggregated, nil
+}
+
+type replicaSetAggregatedStatus struct {
+	Replicas             int32                            `json:"replicas"`
+	FullyLabeledReplicas int32                            `json:"fullyLabeledReplicas"`
+	ReadyReplicas        int32                            `json:"readyReplicas"`
+	AvailableReplicas    int32                            `json:"availableReplicas"`
+	Conditions           []util.AggregatedStatusCondition `json:"conditions"`
+}
+
+// aggregateReplicaSetStatus sums the replica counts of ReplicaSets.
+// A ReplicaSet is degraded if not all of its replicas are available
+// or if it failed to create or delete replicas.
+func aggregateReplicaSetStatus(clusterStatuses []util.ResourceClusterStatus, propagationStatus *util.PropagationStatus) (interface{}, error) {
+	aggregated := &replicaSetAggregatedStatus{}
+	health := &healthAggregation{}
+	for _, clusterStatus := range clusterStatuses {
+		if !health.observeState(clusterStatus, propagationStatus) {
+			continue
+		}
+		status := appsv1.ReplicaSetStatus{}
+		err := decodeClusterStatus(clusterStatus, &status)
+		if err != nil {
+			return nil, err
+		}
+		aggregated.Replicas += status.Replicas
+		aggregated.FullyLabeledReplicas += status.FullyLabeledReplicas
+		aggregated.ReadyReplicas += status.ReadyReplicas
+		aggregated.AvailableReplicas += status.AvailableReplicas
+
+		degraded := status.AvailableReplicas < status.Replicas
+		for _, condition := range status.Conditions {
+			if condition.Type == appsv1.ReplicaSetReplicaFailure && condition.Status == apiv1.ConditionTrue {
+				degraded = true
+			}
+		}
+		health.observe(clusterStatus.ClusterName, degraded)
+	}
+	aggregated.Conditions = []util.AggregatedStatusCondition{health.condition()}
+	return aggregated, nil
+}
+
+type jobAggregatedStatus struct {
+	Active     int32                            `json:"active"`
+	Succeeded  int32                            `json:"succeeded"`
+	Failed     int32                            `json:"failed"`
+	Conditions []util.AggregatedStatusCondition `json:"conditions"`
+}
+
+// aggregateJobStatus sums the pod counts of Jobs.  A Job is degraded
+// if it has failed.
+func aggregateJobStatus(clusterStatuses []util.ResourceClusterStatus, propagationStatus *util.PropagationStatus) (interface{}, error) {
+	aggregated := &jobAggregatedStatus{}
+	health := &healthAggregation{}
+	for _, clusterStatus := range clusterStatuses {
+		if !health.observeState(clusterStatus, propagationStatus) {
+			continue
+		}
+		status := batchv1.JobStatus{}
+		err := decodeClusterStatus(clusterStatus, &status)
+		if err != nil {
+			return nil, err
+		}
+		aggregated.Active += status.Active
+		aggregated.Succeeded += status.Succeeded
+		aggregated.Failed += status.Failed
+
+		degraded := false
+		for _, condition := range status.Conditions {
+			if condition.Type == batchv1.JobFailed && condition.Status == apiv1.ConditionTrue {
+				degraded = true
+			}
+		}
+		health.observe(clusterStatus.ClusterName, degraded)
+	}
+	aggregated.Conditions = []util.AggregatedStatusCondition{health.condition()}
+	return aggregated, nil
+}
+
+type serviceAggregatedStatus struct {
+	LoadBalancer apiv1.LoadBalancerStatus `json:"loadBalancer"`
+}
+
+// aggregateServiceStatus combines the load balancer ingress points of
+// Services in the order of their clusters.  The health of a Service
+// cannot be determined from its status, so no condition is reported.
+func aggregateServiceStatus(clusterStatuses []util.ResourceClusterStatus, propagationStatus *util.PropagationStatus) (interface{}, error) {
+	aggregated := &serviceAggregatedStatus{}
+	for _, clusterStatus := range clusterStatuses {
+		if clusterStatus.State != util.ResourceClusterStatePresent {
+			continue
+		}
+		status := apiv1.ServiceStatus{}
+		err := decodeClusterStatus(clusterStatus, &status)
+		if err != nil {
+			return nil, err
+		}
+		aggregated.LoadBalancer.Ingress = append(aggregated.LoadBalancer.Ingress, status.LoadBalancer.Ingress...)
+	}
+	return aggregated, nil
+}
+
+func decodeClusterStatus(clusterStatus util.ResourceClusterStatus, status interface{}) error {
+	err := pkgruntime.DefaultUnstructuredConverter.FromUnstructured(clusterStatus.Status, status)
+	if err != nil {
+		return errors.Wrapf(err, "Failed to decode status for cluster %q", clusterStatus.ClusterName)
+	}
+	return nil
+}
+
+// healthAggregation records the clusters in which a resource is
+// degraded, missing or of unknown health to determine the Healthy
+// condition.
+type healthAggregation struct {
+	observed         bool
+	degradedClusters []string
+	missingClusters  []string
+	unreadyClusters  []string
+}
+
+// observeState records the health implied by the state of the given
+// cluster status, and returns whether the status of the resource is
+// present in the cluster and should be observed.  A resource missing
+// from a cluster is degraded, and the health of a resource placed in
+// a cluster that is not ready is unknown.
+func (h *healthAggregation) observeState(clusterStatus util.ResourceClusterStatus, propagationStatus *util.PropagationStatus) bool {
+	switch clusterStatus.State {
+	case util.ResourceClusterStatePresent:
+		return true
+	case util.ResourceClusterStateMissing:
+		h.missingClusters = append(h.missingClusters, clusterStatus.ClusterName)
+	case util.ResourceClusterStateClusterNotReady:
+		if expectedInCluster(propagationStatus, clusterStatus.ClusterName) {
+			h.unreadyClusters = append(h.unreadyClusters, clusterStatus.ClusterName)
+		}
+	}
+	return false
+}
+
+func (h *healthAggregation) observe(clusterName string, degraded bool) {
+	h.observed = true
+	if degraded {
+		h.degradedClusters = append(h.degradedClusters, clusterName)
+	}
+}
+
+func (h *healthAggregation) condition() util.AggregatedStatusCondition {
+	condition := util.AggregatedStatusCondition{
+		Type:   util.AggregatedStatusConditionHealthy,
+		Status: apiv1.ConditionTrue,
+	}
+	switch {
+	case len(h.degradedClusters) > 0 || len(h.missingClusters) > 0:
+		condition.Status = apiv1.ConditionFalse
+		condition.Reason = "Degraded"
+		messages := []string{}
+		if len(h.degradedClusters) > 0 {
+			messages = append(messages, fmt.Sprintf("Degraded in clusters: %s", strings.Join(h.degradedClusters, ", ")))
+		}
+		if len(h.missingClusters) > 0 {
+			messages = append(messages, fmt.Sprintf("Missing from clusters: %s", strings.Join(h.missingClusters, ", ")))
+		}
+		condition.Message = strings.Join(messages, "; ")
+	case len(h.unreadyClusters) > 0:
+		condition.Status = apiv1.ConditionUnknown
+		condition.Reason = "ClusterNotReady"
+		condition.Message = fmt.Sprintf("Health is unknown in clusters that are not ready: %s", strings.Join(h.unreadyClusters, ", "))
+	case !h.observed:
+		condition.Status = apiv1.ConditionUnknown
+		condition.Reason = "NoClusterStatus"
+		condition.Message = "No cluster reports a status"
+	}
+	return condition
+}
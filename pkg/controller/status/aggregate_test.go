@@ -0,0 +1,237 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+)
+
+func TestStatusAggregators(t *testing.T) {
+	healthy := util.AggregatedStatusCondition{
+		Type:   util.AggregatedStatusConditionHealthy,
+		Status: apiv1.ConditionTrue,
+	}
+	degradedIn := func(message string) util.AggregatedStatusCondition {
+		return util.AggregatedStatusCondition{
+			Type:    util.AggregatedStatusConditionHealthy,
+			Status:  apiv1.ConditionFalse,
+			Reason:  "Degraded",
+			Message: message,
+		}
+	}
+
+	testCases := map[string]struct {
+		aggregator      fedv1a1.StatusAggregator
+		clusterStatuses map[string]string
+		clusterStates   map[string]util.ResourceClusterState
+		placedClusters  []string
+		expectedStatus  interface{}
+	}{
+		"deployment replicas are summed": {
+			aggregator: fedv1a1.StatusAggregatorDeployment,
+			clusterStatuses: map[string]string{
+				"c1": `{"replicas": 2, "updatedReplicas": 2, "readyReplicas": 2, "availableReplicas": 2, "conditions": [{"type": "Available", "status": "True", "lastUpdateTime": "2019-01-01T00:00:00Z"}]}`,
+				"c2": `{"replicas": 3, "updatedReplicas": 3, "readyReplicas": 1, "availableReplicas": 1, "unavailableReplicas": 2}`,
+				"c3": ``,
+			},
+			expectedStatus: &deploymentAggregatedStatus{
+				Replicas:            5,
+				UpdatedReplicas:     5,
+				ReadyReplicas:       3,
+				AvailableReplicas:   3,
+				UnavailableReplicas: 2,
+				Conditions:          []util.AggregatedStatusCondition{degradedIn("Degraded in clusters: c2")},
+			},
+		},
+		"unavailable deployment is degraded": {
+			aggregator: fedv1a1.StatusAggregatorDeployment,
+			clusterStatuses: map[string]string{
+				"c1": `{"conditions": [{"type": "Available", "status": "False"}]}`,
+			},
+			expectedStatus: &deploymentAggregatedStatus{
+				Conditions: []util.AggregatedStatusCondition{degradedIn("Degraded in clusters: c1")},
+			},
+		},
+		"health is unknown without a cluster status": {
+			aggregator: fedv1a1.StatusAggregatorDeployment,
+			clusterStatuses: map[string]string{
+				"c1": ``,
+			},
+			expectedStatus: &deploymentAggregatedStatus{
+				Conditions: []util.AggregatedStatusCondition{{
+					Type:    util.AggregatedStatusConditionHealthy,
+					Status:  apiv1.ConditionUnknown,
+					Reason:  "NoClusterStatus",
+					Message: "No cluster reports a status",
+				}},
+			},
+		},
+		"missing deployment is degraded": {
+			aggregator: fedv1a1.StatusAggregatorDeployment,
+			clusterStatuses: map[string]string{
+				"c1": `{"replicas": 1, "updatedReplicas": 1, "readyReplicas": 1, "availableReplicas": 1}`,
+				"c3": `{"replicas": 1, "updatedReplicas": 1, "readyReplicas": 1, "unavailableReplicas": 1}`,
+			},
+			clusterStates: map[string]util.ResourceClusterState{
+				"c2": util.ResourceClusterStateMissing,
+			},
+			expectedStatus: &deploymentAggregatedStatus{
+				Replicas:            2,
+				UpdatedReplicas:     2,
+				ReadyReplicas:       2,
+				AvailableReplicas:   1,
+				UnavailableReplicas: 1,
+				Conditions:          []util.AggregatedStatusCondition{degradedIn("Degraded in clusters: c3; Missing from clusters: c2")},
+			},
+		},
+		"health is unknown in a placed cluster that is not ready": {
+			aggregator: fedv1a1.StatusAggregatorReplicaSet,
+			clusterStatuses: map[string]string{
+				"c1": `{"replicas": 1, "availableReplicas": 1}`,
+			},
+			clusterStates: map[string]util.ResourceClusterState{
+				"c2": util.ResourceClusterStateClusterNotReady,
+				"c3": util.ResourceClusterStateClusterNotReady,
+			},
+			placedClusters: []string{"c1", "c2"},
+			expectedStatus: &replicaSetAggregatedStatus{
+				Replicas:          1,
+				AvailableReplicas: 1,
+				Conditions: []util.AggregatedStatusCondition{{
+					Type:    util.AggregatedStatusConditionHealthy,
+					Status:  apiv1.ConditionUnknown,
+					Reason:  "ClusterNotReady",
+					Message: "Health is unknown in clusters that are not ready: c2",
+				}},
+			},
+		},
+		"unplaced cluster that is not ready is ignored": {
+			aggregator: fedv1a1.StatusAggregatorJob,
+			clusterStatuses: map[string]string{
+				"c1": `{"succeeded": 1}`,
+			},
+			clusterStates: map[string]util.ResourceClusterState{
+				"c2": util.ResourceClusterStateClusterNotReady,
+			},
+			placedClusters: []string{"c1"},
+			expectedStatus: &jobAggregatedStatus{
+				Succeeded:  1,
+				Conditions: []util.AggregatedStatusCondition{healthy},
+			},
+		},
+		"replica set replicas are summed": {
+			aggregator: fedv1a1.StatusAggregatorReplicaSet,
+			clusterStatuses: map[string]string{
+				"c1": `{"replicas": 2, "fullyLabeledReplicas": 2, "readyReplicas": 2, "availableReplicas": 2}`,
+				"c2": `{"replicas": 1, "fullyLabeledReplicas": 1, "readyReplicas": 1, "availableReplicas": 1}`,
+			},
+			expectedStatus: &replicaSetAggregatedStatus{
+				Replicas:             3,
+				FullyLabeledReplicas: 3,
+				ReadyReplicas:        3,
+				AvailableReplicas:    3,
+				Conditions:           []util.AggregatedStatusCondition{healthy},
+			},
+		},
+		"failed job is degraded": {
+			aggregator: fedv1a1.StatusAggregatorJob,
+			clusterStatuses: map[string]string{
+				"c1": `{"succeeded": 1}`,
+				"c2": `{"failed": 2, "conditions": [{"type": "Failed", "status": "True"}]}`,
+			},
+			expectedStatus: &jobAggregatedStatus{
+				Succeeded:  1,
+				Failed:     2,
+				Conditions: []util.AggregatedStatusCondition{degradedIn("Degraded in clusters: c2")},
+			},
+		},
+		"service ingress is combined": {
+			aggregator: fedv1a1.StatusAggregatorService,
+			clusterStatuses: map[string]string{
+				"c1": `{"loadBalancer": {"ingress": [{"ip": "10.0.0.1"}]}}`,
+				"c2": `{"loadBalancer": {"ingress": [{"hostname": "example.com"}]}}`,
+			},
+			expectedStatus: &serviceAggregatedStatus{
+				LoadBalancer: apiv1.LoadBalancerStatus{
+					Ingress: []apiv1.LoadBalancerIngress{{IP: "10.0.0.1"}, {Hostname: "example.com"}},
+				},
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			clusterStatuses := []util.ResourceClusterStatus{}
+			for _, clusterName := range []string{"c1", "c2", "c3"} {
+				if state, ok := testCase.clusterStates[clusterName]; ok {
+					clusterStatuses = append(clusterStatuses, util.ResourceClusterStatus{ClusterName: clusterName, State: state})
+					continue
+				}
+				content, ok := testCase.clusterStatuses[clusterName]
+				if !ok {
+					continue
+				}
+				clusterStatus := util.ResourceClusterStatus{ClusterName: clusterName, State: util.ResourceClusterStateNoStatus}
+				if len(content) > 0 {
+					clusterStatus.State = util.ResourceClusterStatePresent
+					err := json.Unmarshal([]byte(content), &clusterStatus.Status)
+					if err != nil {
+						t.Fatalf("Unexpected error: %v", err)
+					}
+				}
+				clusterStatuses = append(clusterStatuses, clusterStatus)
+			}
+
+			aggregator, err := statusAggregatorFor(&fedv1a1.FederatedTypeConfig{
+				Spec: fedv1a1.FederatedTypeConfigSpec{StatusAggregator: testCase.aggregator},
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			propagationStatus := &util.PropagationStatus{}
+			for _, clusterName := range testCase.placedClusters {
+				propagationStatus.Clusters = append(propagationStatus.Clusters, util.ClusterPropagationStatus{
+					Cluster: clusterName,
+					State:   util.ClusterPropagationPlaced,
+				})
+			}
+			status, err := aggregator(clusterStatuses, propagationStatus)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(testCase.expectedStatus, status) {
+				t.Fatalf("Expected %#v, got %#v", testCase.expectedStatus, status)
+			}
+		})
+	}
+}
+
+func TestUnknownStatusAggregator(t *testing.T) {
+	_, err := statusAggregatorFor(&fedv1a1.FederatedTypeConfig{
+		Spec: fedv1a1.FederatedTypeConfigSpec{StatusAggregator: "Unknown"},
+	})
+	if err == nil {
+		t.Fatalf("Expected an error for an unknown status aggregator")
+	}
+}
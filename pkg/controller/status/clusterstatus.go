@@ -0,0 +1,176 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kubernetes-sigs/federation-v2/pkg/apis/core/typeconfig"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+)
+
+// statusFieldFilter selects the fields of the status of a target
+// resource that are collected and determines whether a change to the
+// collected status is recorded.  The zero value collects the entire
+// status and records every change.
+type statusFieldFilter struct {
+	// The paths of the collected fields, or nil to collect the
+	// entire status.
+	collected [][]string
+	// The paths of the fields whose changes alone are not recorded.
+	ignored [][]string
+}
+
+// newStatusFieldFilter returns the filter for the status fields
+// configured for the given type.
+func newStatusFieldFilter(typeConfig typeconfig.Interface) (*statusFieldFilter, error) {
+	collected, err := parseStatusFieldPaths(typeConfig.GetStatusFields())
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid status fields")
+	}
+	ignored, err := parseStatusFieldPaths(typeConfig.GetIgnoredStatusFields())
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid ignored status fields")
+	}
+	return &statusFieldFilter{collected: collected, ignored: ignored}, nil
+}
+
+func parseStatusFieldPaths(paths []string) ([][]string, error) {
+	var fields [][]string
+	for _, path := range paths {
+		elements := strings.Split(path, ".")
+		for _, element := range elements {
+			if len(element) == 0 {
+				return nil, errors.Errorf("Path %q has an empty element", path)
+			}
+		}
+		fields = append(fields, elements)
+	}
+	return fields, nil
+}
+
+// collect returns the collected fields of the given status, or nil if
+// none of the fields are present.
+func (f *statusFieldFilter) collect(status map[string]interface{}) map[string]interface{} {
+	if f.collected == nil {
+		return status
+	}
+	collected := make(map[string]interface{})
+	for _, path := range f.collected {
+		value, found, err := unstructured.NestedFieldNoCopy(status, path...)
+		if err != nil || !found {
+			continue
+		}
+		// An error can only result from a conflicting path (e.g. a
+		// and a.b), in which case the first path wins.
+		_ = unstructured.SetNestedField(collected, value, path...)
+	}
+	if len(collected) == 0 {
+		return nil
+	}
+	return collected
+}
+
+// equal indicates whether the given collected statuses differ in
+// fields other than the ignored fields.
+func (f *statusFieldFilter) equal(a, b map[string]interface{}) bool {
+	if f.ignored == nil || a == nil || b == nil {
+		return reflect.DeepEqual(a, b)
+	}
+	a = pkgruntime.DeepCopyJSON(a)
+	b = pkgruntime.DeepCopyJSON(b)
+	for _, path := range f.ignored {
+		unstructured.RemoveNestedField(a, path...)
+		unstructured.RemoveNestedField(b, path...)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// readyClusterStatus returns the status of a resource in a ready
+// cluster from the resource found in the cluster, if any.  A resource
+// that was not found is missing if the propagation status of the
+// federated resource indicates that it should exist in the cluster.
+// Only the fields of the status selected by the filter are collected.
+// The previous status and its update time are retained if the state
+// is unchanged and the status changed only in ignored fields.
+func readyClusterStatus(clusterName string, clusterObj *unstructured.Unstructured, propagationStatus *util.PropagationStatus,
+	filter *statusFieldFilter, previous *util.ResourceClusterStatus, now metav1.Time) (util.ResourceClusterStatus, error) {
+
+	current := util.ResourceClusterStatus{ClusterName: clusterName}
+	switch {
+	case clusterObj != nil:
+		status, found, err := unstructured.NestedMap(clusterObj.Object, util.StatusField)
+		if err != nil {
+			return current, errors.Wrapf(err, "Failed to get status of resource in cluster %q", clusterName)
+		}
+		current.State = util.ResourceClusterStateNoStatus
+		if found {
+			current.Status = filter.collect(status)
+		}
+		if current.Status != nil {
+			current.State = util.ResourceClusterStatePresent
+		}
+		current.LastUpdateTime = &now
+		if previous != nil && previous.State == current.State && previous.LastUpdateTime != nil &&
+			filter.equal(previous.Status, current.Status) {
+			current.Status = previous.Status
+			current.LastUpdateTime = previous.LastUpdateTime
+		}
+	case expectedInCluster(propagationStatus, clusterName):
+		current.State = util.ResourceClusterStateMissing
+	default:
+		current.State = util.ResourceClusterStateNotPlaced
+	}
+	return current, nil
+}
+
+// unreadyClusterStatus returns the status of a resource in an unready
+// cluster, retaining the last known status of the resource.
+func unreadyClusterStatus(clusterName string, previous *util.ResourceClusterStatus) util.ResourceClusterStatus {
+	current := util.ResourceClusterStatus{
+		ClusterName: clusterName,
+		State:       util.ResourceClusterStateClusterNotReady,
+	}
+	if previous != nil {
+		current.Status = previous.Status
+		current.LastUpdateTime = previous.LastUpdateTime
+	}
+	return current
+}
+
+// expectedInCluster indicates whether the propagation status of a
+// federated resource reports that the resource should exist in the
+// named cluster.
+func expectedInCluster(propagationStatus *util.PropagationStatus, clusterName string) bool {
+	clusterStatus := propagationStatus.ClusterStatus(clusterName)
+	if clusterStatus == nil {
+		return false
+	}
+	switch clusterStatus.State {
+	case util.ClusterPropagationDeleted, util.ClusterPropagationOrphaned,
+		util.ClusterPropagationRetained, util.ClusterPropagationDenied:
+		return false
+	}
+	return true
+}
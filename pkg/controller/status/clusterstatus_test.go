@@ -0,0 +1,267 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+)
+
+func TestReadyClusterStatus(t *testing.T) {
+	then := metav1.NewTime(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC))
+	now := metav1.NewTime(then.Add(time.Hour))
+	status := map[string]interface{}{"replicas": int64(1)}
+	propagationStatus := &util.PropagationStatus{
+		Clusters: []util.ClusterPropagationStatus{
+			{Cluster: "placed", State: util.ClusterPropagationPlaced},
+			{Cluster: "deleted", State: util.ClusterPropagationDeleted},
+		},
+	}
+
+	filtered := &statusFieldFilter{
+		collected: [][]string{{"replicas"}, {"loadBalancer", "ingress"}, {"observedTime"}},
+		ignored:   [][]string{{"observedTime"}},
+	}
+	filteredStatus := map[string]interface{}{
+		"replicas":     int64(2),
+		"conditions":   []interface{}{},
+		"loadBalancer": map[string]interface{}{"ingress": []interface{}{"10.0.0.1"}, "other": "value"},
+		"observedTime": "2019-01-01T01:00:00Z",
+	}
+
+	testCases := map[string]struct {
+		clusterName    string
+		clusterObj     *unstructured.Unstructured
+		filter         *statusFieldFilter
+		previous       *util.ResourceClusterStatus
+		expectedStatus util.ResourceClusterStatus
+	}{
+		"status is reported": {
+			clusterName: "placed",
+			clusterObj:  &unstructured.Unstructured{Object: map[string]interface{}{"status": status}},
+			expectedStatus: util.ResourceClusterStatus{
+				ClusterName:    "placed",
+				State:          util.ResourceClusterStatePresent,
+				Status:         status,
+				LastUpdateTime: &now,
+			},
+		},
+		"update time of unchanged status is retained": {
+			clusterName: "placed",
+			clusterObj:  &unstructured.Unstructured{Object: map[string]interface{}{"status": status}},
+			previous: &util.ResourceClusterStatus{
+				ClusterName:    "placed",
+				State:          util.ResourceClusterStatePresent,
+				Status:         map[string]interface{}{"replicas": int64(1)},
+				LastUpdateTime: &then,
+			},
+			expectedStatus: util.ResourceClusterStatus{
+				ClusterName:    "placed",
+				State:          util.ResourceClusterStatePresent,
+				Status:         status,
+				LastUpdateTime: &then,
+			},
+		},
+		"resource without status": {
+			clusterName: "placed",
+			clusterObj:  &unstructured.Unstructured{Object: map[string]interface{}{"data": map[string]interface{}{}}},
+			expectedStatus: util.ResourceClusterStatus{
+				ClusterName:    "placed",
+				State:          util.ResourceClusterStateNoStatus,
+				LastUpdateTime: &now,
+			},
+		},
+		"only selected fields are collected": {
+			clusterName: "placed",
+			clusterObj:  &unstructured.Unstructured{Object: map[string]interface{}{"status": filteredStatus}},
+			filter:      filtered,
+			expectedStatus: util.ResourceClusterStatus{
+				ClusterName: "placed",
+				State:       util.ResourceClusterStatePresent,
+				Status: map[string]interface{}{
+					"replicas":     int64(2),
+					"loadBalancer": map[string]interface{}{"ingress": []interface{}{"10.0.0.1"}},
+					"observedTime": "2019-01-01T01:00:00Z",
+				},
+				LastUpdateTime: &now,
+			},
+		},
+		"change to ignored field is not recorded": {
+			clusterName: "placed",
+			clusterObj:  &unstructured.Unstructured{Object: map[string]interface{}{"status": filteredStatus}},
+			filter:      filtered,
+			previous: &util.ResourceClusterStatus{
+				ClusterName: "placed",
+				State:       util.ResourceClusterStatePresent,
+				Status: map[string]interface{}{
+					"replicas":     int64(2),
+					"loadBalancer": map[string]interface{}{"ingress": []interface{}{"10.0.0.1"}},
+					"observedTime": "2019-01-01T00:00:00Z",
+				},
+				LastUpdateTime: &then,
+			},
+			expectedStatus: util.ResourceClusterStatus{
+				ClusterName: "placed",
+				State:       util.ResourceClusterStatePresent,
+				Status: map[string]interface{}{
+					"replicas":     int64(2),
+					"loadBalancer": map[string]interface{}{"ingress": []interface{}{"10.0.0.1"}},
+					"observedTime": "2019-01-01T00:00:00Z",
+				},
+				LastUpdateTime: &then,
+			},
+		},
+		"change to collected field is recorded": {
+			clusterName: "placed",
+			clusterObj:  &unstructured.Unstructured{Object: map[string]interface{}{"status": filteredStatus}},
+			filter:      filtered,
+			previous: &util.ResourceClusterStatus{
+				ClusterName: "placed",
+				State:       util.ResourceClusterStatePresent,
+				Status: map[string]interface{}{
+					"replicas":     int64(1),
+					"loadBalancer": map[string]interface{}{"ingress": []interface{}{"10.0.0.1"}},
+					"observedTime": "2019-01-01T00:00:00Z",
+				},
+				LastUpdateTime: &then,
+			},
+			expectedStatus: util.ResourceClusterStatus{
+				ClusterName: "placed",
+				State:       util.ResourceClusterStatePresent,
+				Status: map[string]interface{}{
+					"replicas":     int64(2),
+					"loadBalancer": map[string]interface{}{"ingress": []interface{}{"10.0.0.1"}},
+					"observedTime": "2019-01-01T01:00:00Z",
+				},
+				LastUpdateTime: &now,
+			},
+		},
+		"resource without selected fields has no status": {
+			clusterName: "placed",
+			clusterObj:  &unstructured.Unstructured{Object: map[string]interface{}{"status": status}},
+			filter:      &statusFieldFilter{collected: [][]string{{"loadBalancer"}}},
+			expectedStatus: util.ResourceClusterStatus{
+				ClusterName:    "placed",
+				State:          util.ResourceClusterStateNoStatus,
+				LastUpdateTime: &now,
+			},
+		},
+		"placed resource is missing": {
+			clusterName: "placed",
+			expectedStatus: util.ResourceClusterStatus{
+				ClusterName: "placed",
+				State:       util.ResourceClusterStateMissing,
+			},
+		},
+		"deleted resource is not placed": {
+			clusterName: "deleted",
+			expectedStatus: util.ResourceClusterStatus{
+				ClusterName: "deleted",
+				State:       util.ResourceClusterStateNotPlaced,
+			},
+		},
+		"unreported resource is not placed": {
+			clusterName: "unreported",
+			expectedStatus: util.ResourceClusterStatus{
+				ClusterName: "unreported",
+				State:       util.ResourceClusterStateNotPlaced,
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			filter := testCase.filter
+			if filter == nil {
+				filter = &statusFieldFilter{}
+			}
+			clusterStatus, err := readyClusterStatus(testCase.clusterName, testCase.clusterObj, propagationStatus, filter, testCase.previous, now)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(testCase.expectedStatus, clusterStatus) {
+				t.Fatalf("Expected %v, got %v", testCase.expectedStatus, clusterStatus)
+			}
+		})
+	}
+}
+
+func TestUnreadyClusterStatus(t *testing.T) {
+	then := metav1.NewTime(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC))
+	previous := &util.ResourceClusterStatus{
+		ClusterName:    "c1",
+		State:          util.ResourceClusterStatePresent,
+		Status:         map[string]interface{}{"replicas": int64(1)},
+		LastUpdateTime: &then,
+	}
+	expectedStatus := util.ResourceClusterStatus{
+		ClusterName:    "c1",
+		State:          util.ResourceClusterStateClusterNotReady,
+		Status:         previous.Status,
+		LastUpdateTime: &then,
+	}
+	clusterStatus := unreadyClusterStatus("c1", previous)
+	if !reflect.DeepEqual(expectedStatus, clusterStatus) {
+		t.Fatalf("Expected %v, got %v", expectedStatus, clusterStatus)
+	}
+}
+
+func TestNewStatusFieldFilter(t *testing.T) {
+	testCases := map[string]struct {
+		statusFields  []string
+		ignoredFields []string
+		expectErr     bool
+	}{
+		"no fields": {},
+		"valid paths": {
+			statusFields:  []string{"replicas", "loadBalancer.ingress"},
+			ignoredFields: []string{"observedTime"},
+		},
+		"empty status field element": {
+			statusFields: []string{"loadBalancer..ingress"},
+			expectErr:    true,
+		},
+		"empty ignored field": {
+			ignoredFields: []string{""},
+			expectErr:     true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			_, err := newStatusFieldFilter(&fedv1a1.FederatedTypeConfig{
+				Spec: fedv1a1.FederatedTypeConfigSpec{
+					StatusFields:        testCase.statusFields,
+					IgnoredStatusFields: testCase.ignoredFields,
+				},
+			})
+			if testCase.expectErr && err == nil {
+				t.Fatalf("Expected an error")
+			}
+			if !testCase.expectErr && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
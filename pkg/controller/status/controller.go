@@ -42,6 +42,10 @@ const (
 	allClustersKey = "ALL_CLUSTERS"
 )
 
+// statusFields are the fields of the collected status written by the
+// controller.
+var statusFields = []string{util.ClusterStatusField, util.AggregatedStatusField}
+
 // FederationStatusController collects the status of a federated type
 // from clusters that are members of the federation.
 type FederationStatusController struct {
@@ -57,7 +61,9 @@ type FederationStatusController struct {
 	// Informer for the federated type
 	federatedController cache.Controller
 
-	// Store for the status of the federated type
+	// Store for the status of the federated type.  Not used if the
+	// status is written to the status subresource of the federated
+	// type.
 	statusStore cache.Store
 	// Informer for the status of the federated type
 	statusController cache.Controller
@@ -70,8 +76,20 @@ type FederationStatusController struct {
 
 	typeConfig typeconfig.Interface
 
-	client       genericclient.Client
-	statusClient util.ResourceClient
+	// Where the status of a federated resource is written.
+	statusMode fedv1a1.StatusMode
+
+	// Aggregates the status of target resources in member clusters,
+	// or nil if status is not aggregated for the type.
+	aggregator statusAggregator
+
+	// Selects the fields of the status of target resources that are
+	// collected.
+	statusFilter *statusFieldFilter
+
+	client              genericclient.Client
+	federatedTypeClient util.ResourceClient
+	statusClient        util.ResourceClient
 
 	fedNamespace string
 }
@@ -85,7 +103,7 @@ func StartFederationStatusController(controllerConfig *util.ControllerConfig, st
 	if controllerConfig.MinimizeLatency {
 		controller.minimizeLatency()
 	}
-	glog.Infof(fmt.Sprintf("Starting status controller for %q", typeConfig.GetFederatedType().Kind))
+	glog.Infof("Starting status controller for %q", typeConfig.GetFederatedType().Kind)
 	controller.Run(stopChan)
 	return nil
 }
@@ -93,8 +111,19 @@ func StartFederationStatusController(controllerConfig *util.ControllerConfig, st
 // newFederationStatusController returns a new status controller for the federated type
 func newFederationStatusController(controllerConfig *util.ControllerConfig, typeConfig typeconfig.Interface) (*FederationStatusController, error) {
 	federatedAPIResource := typeConfig.GetFederatedType()
+	statusMode := typeConfig.GetStatusMode()
+	if statusMode != fedv1a1.StatusModeObject && statusMode != fedv1a1.StatusModeSubresource {
+		return nil, errors.Errorf("Invalid status mode %q: must be %q or %q", statusMode, fedv1a1.StatusModeObject, fedv1a1.StatusModeSubresource)
+	}
 	statusAPIResource := typeConfig.GetStatus()
-	userAgent := fmt.Sprintf("%s-controller", strings.ToLower(statusAPIResource.Kind))
+	if statusMode == fedv1a1.StatusModeObject && statusAPIResource == nil {
+		return nil, errors.Errorf("A status type must be configured for the %q status mode", statusMode)
+	}
+
+	userAgent := fmt.Sprintf("%sstatus-controller", strings.ToLower(federatedAPIResource.Kind))
+	if statusAPIResource != nil {
+		userAgent = fmt.Sprintf("%s-controller", strings.ToLower(statusAPIResource.Kind))
+	}
 	client := genericclient.NewForConfigOrDieWithUserAgent(controllerConfig.KubeConfig, userAgent)
 
 	federatedTypeClient, err := util.NewResourceClient(controllerConfig.KubeConfig, &federatedAPIResource)
@@ -102,7 +131,20 @@ func newFederationStatusController(controllerConfig *util.ControllerConfig, type
 		return nil, err
 	}
 
-	statusClient, err := util.NewResourceClient(controllerConfig.KubeConfig, statusAPIResource)
+	var statusClient util.ResourceClient
+	if statusMode == fedv1a1.StatusModeObject {
+		statusClient, err = util.NewResourceClient(controllerConfig.KubeConfig, statusAPIResource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	aggregator, err := statusAggregatorFor(typeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	statusFilter, err := newStatusFieldFilter(typeConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +154,11 @@ func newFederationStatusController(controllerConfig *util.ControllerConfig, type
 		clusterUnavailableDelay: controllerConfig.ClusterUnavailableDelay,
 		smallDelay:              time.Second * 3,
 		typeConfig:              typeConfig,
+		statusMode:              statusMode,
+		aggregator:              aggregator,
+		statusFilter:            statusFilter,
 		client:                  client,
+		federatedTypeClient:     federatedTypeClient,
 		statusClient:            statusClient,
 		fedNamespace:            controllerConfig.FederationNamespace,
 	}
@@ -130,7 +176,9 @@ func newFederationStatusController(controllerConfig *util.ControllerConfig, type
 	targetNamespace := controllerConfig.TargetNamespace
 
 	s.federatedStore, s.federatedController = util.NewResourceInformer(federatedTypeClient, targetNamespace, enqueueObj)
-	s.statusStore, s.statusController = util.NewResourceInformer(statusClient, targetNamespace, enqueueObj)
+	if statusClient != nil {
+		s.statusStore, s.statusController = util.NewResourceInformer(statusClient, targetNamespace, enqueueObj)
+	}
 
 	targetAPIResource := typeConfig.GetTarget()
 
@@ -172,7 +220,9 @@ func (s *FederationStatusController) minimizeLatency() {
 // Run runs the status controller
 func (s *FederationStatusController) Run(stopChan <-chan struct{}) {
 	go s.federatedController.Run(stopChan)
-	go s.statusController.Run(stopChan)
+	if s.statusController != nil {
+		go s.statusController.Run(stopChan)
+	}
 	s.informer.Start()
 	s.clusterDeliverer.StartWithHandler(func(_ *util.DelayingDelivererItem) {
 		s.reconcileOnClusterChange()
@@ -199,7 +249,7 @@ func (s *FederationStatusController) isSynced() bool {
 		glog.V(2).Infof("Federated type not synced")
 		return false
 	}
-	if !s.statusController.HasSynced() {
+	if s.statusController != nil && !s.statusController.HasSynced() {
 		glog.V(2).Infof("Status not synced")
 		return false
 	}
@@ -232,7 +282,10 @@ func (s *FederationStatusController) reconcile(qualifiedName util.QualifiedName)
 	}
 
 	federatedKind := s.typeConfig.GetFederatedType().Kind
-	statusKind := s.typeConfig.GetStatus().Kind
+	statusKind := federatedKind
+	if s.statusMode == fedv1a1.StatusModeObject {
+		statusKind = s.typeConfig.GetStatus().Kind
+	}
 	key := qualifiedName.String()
 
 	glog.V(4).Infof("Starting to reconcile %v %v", statusKind, key)
@@ -250,31 +303,67 @@ func (s *FederationStatusController) reconcile(qualifiedName util.QualifiedName)
 		return util.StatusAllOK
 	}
 
-	clusterNames, err := s.clusterNames()
-	if err != nil {
-		runtime.HandleError(errors.Wrap(err, "Failed to get cluster list"))
-		return util.StatusNotSynced
+	var existingStatus *unstructured.Unstructured
+	var previousStatus map[string]interface{}
+	if s.statusMode == fedv1a1.StatusModeSubresource {
+		previousStatus, _, err = unstructured.NestedMap(fedObject.Object, util.StatusField)
+		if err != nil {
+			runtime.HandleError(errors.Wrapf(err, "Failed to read status of %s %q", federatedKind, key))
+			return util.StatusError
+		}
+	} else {
+		existingStatus, err = s.objFromCache(s.statusStore, statusKind, key)
+		if err != nil {
+			return util.StatusError
+		}
+		if existingStatus != nil {
+			previousStatus = existingStatus.Object
+		}
 	}
 
-	clusterStatus, err := s.clusterStatuses(clusterNames, key)
+	propagationStatus, err := util.GetPropagationStatus(fedObject)
 	if err != nil {
+		runtime.HandleError(errors.Wrapf(err, "Failed to read propagation status of %s %q", federatedKind, key))
 		return util.StatusError
 	}
 
-	existingStatus, err := s.objFromCache(s.statusStore, statusKind, key)
+	clusterStatus, err := s.clusterStatuses(previousStatus, propagationStatus, key)
 	if err != nil {
+		runtime.HandleError(errors.Wrapf(err, "Failed to collect cluster status for %s %q", statusKind, key))
 		return util.StatusError
 	}
 
+	var aggregatedStatus interface{}
+	if s.aggregator != nil {
+		aggregatedStatus, err = s.aggregator(clusterStatus, propagationStatus)
+		if err != nil {
+			runtime.HandleError(errors.Wrapf(err, "Failed to aggregate status for %s %q", statusKind, key))
+			return util.StatusError
+		}
+	}
+
+	if s.statusMode == fedv1a1.StatusModeSubresource {
+		return s.updateStatusSubresource(fedObject, clusterStatus, aggregatedStatus)
+	}
+	return s.updateStatusObject(fedObject, existingStatus, clusterStatus, aggregatedStatus)
+}
+
+// updateStatusObject creates or updates the status object of the
+// given federated resource.
+func (s *FederationStatusController) updateStatusObject(fedObject, existingStatus *unstructured.Unstructured,
+	clusterStatus []util.ResourceClusterStatus, aggregatedStatus interface{}) util.ReconciliationStatus {
+
+	statusKind := s.typeConfig.GetStatus().Kind
+	key := util.NewQualifiedName(fedObject).String()
 	resourceGroupVersion := schema.GroupVersion{Group: s.typeConfig.GetStatus().Group, Version: s.typeConfig.GetStatus().Version}
 	federatedResource := util.FederatedResource{
 		TypeMeta: metav1.TypeMeta{
-			Kind:       s.typeConfig.GetStatus().Kind,
+			Kind:       statusKind,
 			APIVersion: resourceGroupVersion.String(),
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      qualifiedName.Name,
-			Namespace: qualifiedName.Namespace,
+			Name:      fedObject.GetName(),
+			Namespace: fedObject.GetNamespace(),
 			// Add ownership of status object to corresponding
 			// federated object, so that status object is deleted when
 			// the federated object is deleted.
@@ -285,7 +374,8 @@ func (s *FederationStatusController) reconcile(qualifiedName util.QualifiedName)
 				UID:        fedObject.GetUID(),
 			}},
 		},
-		ClusterStatus: clusterStatus,
+		ClusterStatus:    clusterStatus,
+		AggregatedStatus: aggregatedStatus,
 	}
 	status, err := util.GetUnstructured(federatedResource)
 	if err != nil {
@@ -294,14 +384,20 @@ func (s *FederationStatusController) reconcile(qualifiedName util.QualifiedName)
 	}
 
 	if existingStatus == nil {
-		_, err = s.statusClient.Resources(qualifiedName.Namespace).Create(status, metav1.CreateOptions{})
+		_, err = s.statusClient.Resources(fedObject.GetNamespace()).Create(status, metav1.CreateOptions{})
 		if err != nil {
 			runtime.HandleError(errors.Wrapf(err, "Failed to create status object for federated type %s %q", statusKind, key))
 			return util.StatusNeedsRecheck
 		}
-	} else if !reflect.DeepEqual(existingStatus.Object["clusterStatus"], status.Object["clusterStatus"]) {
-		existingStatus.Object["clusterStatus"] = status.Object["clusterStatus"]
-		_, err = s.statusClient.Resources(qualifiedName.Namespace).Update(existingStatus, metav1.UpdateOptions{})
+	} else if !statusFieldsEqual(existingStatus, status) {
+		for _, field := range statusFields {
+			if value, ok := status.Object[field]; ok {
+				existingStatus.Object[field] = value
+			} else {
+				delete(existingStatus.Object, field)
+			}
+		}
+		_, err = s.statusClient.Resources(fedObject.GetNamespace()).Update(existingStatus, metav1.UpdateOptions{})
 		if err != nil {
 			runtime.HandleError(errors.Wrapf(err, "Failed to update status object for federated type %s %q", statusKind, key))
 			return util.StatusNeedsRecheck
@@ -311,6 +407,77 @@ func (s *FederationStatusController) reconcile(qualifiedName util.QualifiedName)
 	return util.StatusAllOK
 }
 
+// updateStatusSubresource writes the collected status to the status
+// subresource of the given federated resource.  The propagation
+// status written by the sync controller is retained, and the
+// conditions of the aggregated status are reported alongside the
+// propagation conditions.
+func (s *FederationStatusController) updateStatusSubresource(fedObject *unstructured.Unstructured,
+	clusterStatus []util.ResourceClusterStatus, aggregatedStatus interface{}) util.ReconciliationStatus {
+
+	federatedKind := s.typeConfig.GetFederatedType().Kind
+	key := util.NewQualifiedName(fedObject).String()
+	collected, err := util.GetUnstructured(util.FederatedResource{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       fedObject.GetKind(),
+			APIVersion: fedObject.GetAPIVersion(),
+		},
+		ClusterStatus:    clusterStatus,
+		AggregatedStatus: aggregatedStatus,
+	})
+	if err != nil {
+		glog.Errorf("Failed to convert to Unstructured: %s %q: %v", federatedKind, key, err)
+		return util.StatusError
+	}
+
+	existingStatus, _, err := unstructured.NestedMap(fedObject.Object, util.StatusField)
+	if err != nil {
+		runtime.HandleError(errors.Wrapf(err, "Failed to read status of %s %q", federatedKind, key))
+		return util.StatusError
+	}
+	status := make(map[string]interface{})
+	for field, value := range existingStatus {
+		status[field] = value
+	}
+	for _, field := range statusFields {
+		if value, ok := collected.Object[field]; ok {
+			status[field] = value
+		} else {
+			delete(status, field)
+		}
+	}
+	conditions, _, err := unstructured.NestedSlice(collected.Object, util.AggregatedStatusField, util.ConditionsField)
+	if err != nil {
+		runtime.HandleError(errors.Wrapf(err, "Failed to read aggregated conditions of %s %q", federatedKind, key))
+		return util.StatusError
+	}
+	util.SetStatusConditions(status, conditions, func(conditionType string) bool {
+		return !util.IsPropagationCondition(conditionType)
+	})
+	if reflect.DeepEqual(existingStatus, status) {
+		return util.StatusAllOK
+	}
+
+	fedObject.Object[util.StatusField] = status
+	_, err = s.federatedTypeClient.Resources(fedObject.GetNamespace()).UpdateStatus(fedObject, metav1.UpdateOptions{})
+	if err != nil {
+		runtime.HandleError(errors.Wrapf(err, "Failed to update status of federated type %s %q", federatedKind, key))
+		return util.StatusNeedsRecheck
+	}
+	return util.StatusAllOK
+}
+
+// statusFieldsEqual indicates whether the given status objects have
+// the same values for the fields written by the controller.
+func statusFieldsEqual(a, b *unstructured.Unstructured) bool {
+	for _, field := range statusFields {
+		if !reflect.DeepEqual(a.Object[field], b.Object[field]) {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *FederationStatusController) rawObjFromCache(store cache.Store, kind, key string) (pkgruntime.Object, error) {
 	cachedObj, exist, err := store.GetByKey(key)
 	if err != nil {
@@ -335,46 +502,52 @@ func (s *FederationStatusController) objFromCache(store cache.Store, kind, key s
 	return obj.(*unstructured.Unstructured), nil
 }
 
-func (s *FederationStatusController) clusterNames() ([]string, error) {
-	clusters, err := s.informer.GetReadyClusters()
+// clusterStatuses returns the status of the resource in each ready
+// and unready member cluster.  The last known status of the resource
+// in an unready cluster is retained from the previously written
+// status, if any.
+func (s *FederationStatusController) clusterStatuses(previousStatus map[string]interface{}, propagationStatus *util.PropagationStatus, key string) ([]util.ResourceClusterStatus, error) {
+	readyClusters, err := s.informer.GetReadyClusters()
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrap(err, "Failed to get ready clusters")
 	}
-	clusterNames := []string{}
-	for _, cluster := range clusters {
-		clusterNames = append(clusterNames, cluster.Name)
+	unreadyClusters, err := s.informer.GetUnreadyClusters()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to get unready clusters")
+	}
+	previousStatuses := make(map[string]*util.ResourceClusterStatus)
+	if previousStatus != nil {
+		existing := util.FederatedResource{}
+		err := pkgruntime.DefaultUnstructuredConverter.FromUnstructured(previousStatus, &existing)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to decode existing status")
+		}
+		for i := range existing.ClusterStatus {
+			previousStatuses[existing.ClusterStatus[i].ClusterName] = &existing.ClusterStatus[i]
+		}
 	}
 
-	return clusterNames, nil
-}
-
-// clusterStatuses returns the resource status in member cluster.
-func (s *FederationStatusController) clusterStatuses(clusterNames []string, key string) ([]util.ResourceClusterStatus, error) {
 	clusterStatus := []util.ResourceClusterStatus{}
-
+	now := metav1.Now()
 	targetKind := s.typeConfig.GetTarget().Kind
-	for _, clusterName := range clusterNames {
-		clusterObj, exist, err := s.informer.GetTargetStore().GetByKey(clusterName, key)
+	for _, cluster := range readyClusters {
+		clusterObj, exist, err := s.informer.GetTargetStore().GetByKey(cluster.Name, key)
 		if err != nil {
-			wrappedErr := errors.Wrapf(err, "Failed to get %s %q from cluster %q", targetKind, key, clusterName)
-			runtime.HandleError(wrappedErr)
-			return nil, wrappedErr
+			return nil, errors.Wrapf(err, "Failed to get %s %q from cluster %q", targetKind, key, cluster.Name)
 		}
-
-		var status map[string]interface{}
+		var obj *unstructured.Unstructured
 		if exist {
-			clusterObj := clusterObj.(*unstructured.Unstructured)
-
-			var found bool
-			status, found, err = unstructured.NestedMap(clusterObj.Object, "status")
-			if err != nil || !found {
-				wrappedErr := errors.Wrapf(err, "Failed to get status of cluster resource object %s %q for cluster %q", targetKind, key, clusterName)
-				runtime.HandleError(wrappedErr)
-			}
+			obj = clusterObj.(*unstructured.Unstructured)
+		}
+		resourceClusterStatus, err := readyClusterStatus(cluster.Name, obj, propagationStatus, s.statusFilter, previousStatuses[cluster.Name], now)
+		if err != nil {
+			return nil, err
 		}
-		resourceClusterStatus := util.ResourceClusterStatus{ClusterName: clusterName, Status: status}
 		clusterStatus = append(clusterStatus, resourceClusterStatus)
 	}
+	for _, cluster := range unreadyClusters {
+		clusterStatus = append(clusterStatus, unreadyClusterStatus(cluster.Name, previousStatuses[cluster.Name]))
+	}
 
 	sort.Slice(clusterStatus, func(i, j int) bool {
 		return clusterStatus[i].ClusterName < clusterStatus[j].ClusterName
@@ -25,6 +25,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/kubernetes-sigs/federation-v2/pkg/apis/core/typeconfig"
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
 	genericclient "github.com/kubernetes-sigs/federation-v2/pkg/client/generic"
 	"github.com/kubernetes-sigs/federation-v2/pkg/controller/sync/version"
 	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
@@ -50,6 +51,10 @@ type resourceAccessor struct {
 	federatedStore      cache.Store
 	federatedController cache.Controller
 
+	// The client for the federated type, used to update the status of
+	// federated resources.
+	federatedClient util.ResourceClient
+
 	// The informer used to source namespaces for templates of
 	// federated namespaces.  Will only be initialized if
 	// targetIsNamespace=true.
@@ -64,6 +69,15 @@ type resourceAccessor struct {
 	fedNamespaceStore      cache.Store
 	fedNamespaceController cache.Controller
 
+	// Sources the PlacementPolicies that constrain placement.  Shared
+	// by the controllers of all federated types, and nil if
+	// federation is limited to a single namespace since policies are
+	// cluster-scoped.
+	placementPolicyManager *util.PlacementPolicyManager
+	// Enqueues the resources affected by a change to a policy or to
+	// the labels of a namespace.
+	enqueueNamespace func(namespace string)
+
 	// Manages propagated versions
 	versionManager *version.VersionManager
 
@@ -78,7 +92,8 @@ func NewFederatedResourceAccessor(
 	client genericclient.Client,
 	enqueueObj func(pkgruntime.Object),
 	informer util.FederatedInformer,
-	updater util.FederatedUpdater) (FederatedResourceAccessor, error) {
+	updater util.FederatedUpdater,
+	placementPolicyManager *util.PlacementPolicyManager) (FederatedResourceAccessor, error) {
 
 	a := &resourceAccessor{
 		limitedScope:            controllerConfig.LimitedScope(),
@@ -96,6 +111,7 @@ func NewFederatedResourceAccessor(
 		return nil, err
 	}
 	a.federatedStore, a.federatedController = util.NewResourceInformer(federatedTypeClient, targetNamespace, enqueueObj)
+	a.federatedClient = federatedTypeClient
 
 	if a.targetIsNamespace {
 		// Initialize an informer for namespaces.  The namespace
@@ -136,6 +152,19 @@ func NewFederatedResourceAccessor(
 		a.fedNamespaceStore, a.fedNamespaceController = util.NewResourceInformer(fedNamespaceClient, targetNamespace, fedNamespaceEnqueue)
 	}
 
+	if !a.limitedScope && placementPolicyManager != nil {
+		a.placementPolicyManager = placementPolicyManager
+		a.enqueueNamespace = func(namespace string) {
+			for _, rawObj := range a.federatedStore.List() {
+				obj := rawObj.(pkgruntime.Object)
+				qualifiedName := util.NewQualifiedName(obj)
+				if len(namespace) == 0 || qualifiedName.Namespace == namespace {
+					enqueueObj(obj)
+				}
+			}
+		}
+	}
+
 	a.versionManager = version.NewVersionManager(
 		client,
 		typeConfig.GetFederatedNamespaced(),
@@ -168,6 +197,20 @@ func (a *resourceAccessor) Run(stopChan <-chan struct{}) {
 	if a.fedNamespaceController != nil {
 		go a.fedNamespaceController.Run(stopChan)
 	}
+	if a.placementPolicyManager != nil {
+		// A change to a policy or to the labels of a namespace may
+		// change the policies that apply to a resource.
+		removeListener := a.placementPolicyManager.AddListener(
+			func() {
+				a.enqueueNamespace("")
+			},
+			a.enqueueNamespace,
+		)
+		go func() {
+			<-stopChan
+			removeListener()
+		}()
+	}
 }
 
 func (a *resourceAccessor) HasSynced() bool {
@@ -188,6 +231,10 @@ func (a *resourceAccessor) HasSynced() bool {
 		glog.V(2).Infof("FederatedNamespace informer for %s not synced", kind)
 		return false
 	}
+	if a.placementPolicyManager != nil && !a.placementPolicyManager.HasSynced() {
+		glog.V(2).Infof("PlacementPolicy informers for %s not synced", kind)
+		return false
+	}
 	return true
 }
 
@@ -267,6 +314,14 @@ func (a *resourceAccessor) FederatedResource(eventSource util.QualifiedName) (Fe
 		// will be removed.
 	}
 
+	var placementPolicies []*fedv1a1.PlacementPolicy
+	if a.placementPolicyManager != nil {
+		placementPolicies, err = a.placementPolicyManager.PoliciesFor(resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &federatedResource{
 		limitedScope:      a.limitedScope,
 		typeConfig:        a.typeConfig,
@@ -275,10 +330,12 @@ func (a *resourceAccessor) FederatedResource(eventSource util.QualifiedName) (Fe
 		federatedKind:     kind,
 		federatedName:     federatedName,
 		federatedResource: resource,
+		federatedClient:   a.federatedClient,
 		versionManager:    a.versionManager,
 		deletionHelper:    a.deletionHelper,
 		namespace:         namespace,
 		fedNamespace:      fedNamespace,
+		placementPolicies: placementPolicies,
 	}, nil
 }
 
@@ -18,6 +18,7 @@ package sync
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -29,18 +30,30 @@ import (
 	genericclient "github.com/kubernetes-sigs/federation-v2/pkg/client/generic"
 	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	kubeclient "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 )
 
 const (
 	allClustersKey = "ALL_CLUSTERS"
+
+	// The reason reported for removal of a target resource from a
+	// cluster.
+	unselectedReason = "The cluster is no longer selected"
+
+	// The reason reported for a cluster containing a target resource
+	// that is not managed by federation and was not adopted.
+	unmanagedReason = "A resource not managed by federation already exists in the cluster"
 )
 
 // FederationSyncController synchronizes the state of a federated type
@@ -68,12 +81,49 @@ type FederationSyncController struct {
 
 	typeConfig typeconfig.Interface
 
+	// Fields of target resources whose values in member clusters are
+	// retained when updating.
+	retainedFields []fedv1a1.RetainedField
+
+	// Optional webhook for computing the object used to update a
+	// target resource.
+	updateWebhook *util.UpdateWebhookClient
+
+	// How target resources are updated in member clusters.
+	propagationMode fedv1a1.PropagationMode
+
+	// The default handling of changes made to target resources in
+	// member clusters.
+	driftPolicy fedv1a1.DriftPolicy
+
+	// The default handling of unmanaged resources in member clusters
+	// that have the name of a federated resource.
+	conflictPolicy fedv1a1.ConflictPolicy
+
+	// Whether the informer caches only the target resources managed
+	// by federation, and whether it caches only some of their fields.
+	cacheManagedOnly bool
+	cacheTrimmed     bool
+
+	// The number of revisions of each federated resource to retain.
+	// Revisions are not recorded if zero.
+	revisionHistoryLimit int32
+	// The namespace of the revisions of cluster-scoped resources.
+	federationNamespace string
+	// For recording revisions.
+	kubeClient kubeclient.Interface
+
+	// FederatedTypeConfigs for resolving the types of the
+	// dependencies of federated resources.
+	typeConfigStore cache.Store
+
 	fedAccessor FederatedResourceAccessor
 }
 
 // StartFederationSyncController starts a new sync controller for a type config
-func StartFederationSyncController(controllerConfig *util.ControllerConfig, stopChan <-chan struct{}, typeConfig typeconfig.Interface, fedNamespaceAPIResource *metav1.APIResource) error {
-	controller, err := newFederationSyncController(controllerConfig, typeConfig, fedNamespaceAPIResource)
+func StartFederationSyncController(controllerConfig *util.ControllerConfig, stopChan <-chan struct{}, typeConfig typeconfig.Interface,
+	fedNamespaceAPIResource *metav1.APIResource, typeConfigStore cache.Store, placementPolicyManager *util.PlacementPolicyManager) error {
+	controller, err := newFederationSyncController(controllerConfig, typeConfig, fedNamespaceAPIResource, typeConfigStore, placementPolicyManager)
 	if err != nil {
 		return err
 	}
@@ -86,7 +136,8 @@ func StartFederationSyncController(controllerConfig *util.ControllerConfig, stop
 }
 
 // newFederationSyncController returns a new sync controller for the configuration
-func newFederationSyncController(controllerConfig *util.ControllerConfig, typeConfig typeconfig.Interface, fedNamespaceAPIResource *metav1.APIResource) (*FederationSyncController, error) {
+func newFederationSyncController(controllerConfig *util.ControllerConfig, typeConfig typeconfig.Interface, fedNamespaceAPIResource *metav1.APIResource,
+	typeConfigStore cache.Store, placementPolicyManager *util.PlacementPolicyManager) (*FederationSyncController, error) {
 	federatedTypeAPIResource := typeConfig.GetFederatedType()
 	userAgent := fmt.Sprintf("%s-controller", strings.ToLower(federatedTypeAPIResource.Kind))
 
@@ -105,10 +156,14 @@ func newFederationSyncController(controllerConfig *util.ControllerConfig, typeCo
 		updateTimeout:           time.Second * 30,
 		eventRecorder:           recorder,
 		typeConfig:              typeConfig,
+		federationNamespace:     controllerConfig.FederationNamespace,
+		kubeClient:              kubeClient,
+		typeConfigStore:         typeConfigStore,
 	}
 
 	s.worker = util.NewReconcileWorker(s.reconcile, util.WorkerTiming{
-		ClusterSyncDelay: s.clusterAvailableDelay,
+		ClusterSyncDelay:        s.clusterAvailableDelay,
+		MaxConcurrentReconciles: controllerConfig.MaxConcurrentSyncReconciles,
 	})
 
 	// Build deliverer for triggering cluster reconciliations.
@@ -116,33 +171,86 @@ func newFederationSyncController(controllerConfig *util.ControllerConfig, typeCo
 
 	targetAPIResource := typeConfig.GetTarget()
 
+	retainedFields, err := util.RetainedFieldsForType(targetAPIResource.Kind, typeConfig.GetRetainedFields())
+	if err != nil {
+		return nil, err
+	}
+	s.retainedFields = retainedFields
+
+	if webhook := typeConfig.GetUpdateWebhook(); webhook != nil {
+		s.updateWebhook, err = util.NewUpdateWebhookClient(webhook)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.propagationMode = typeConfig.GetPropagationMode()
+	if s.propagationMode != fedv1a1.PropagationModeReplace && s.propagationMode != fedv1a1.PropagationModeMerge {
+		return nil, errors.Errorf("Invalid propagation mode %q: must be %q or %q", s.propagationMode, fedv1a1.PropagationModeReplace, fedv1a1.PropagationModeMerge)
+	}
+	s.driftPolicy = typeConfig.GetDriftPolicy()
+	if s.driftPolicy != fedv1a1.DriftPolicyCorrect && s.driftPolicy != fedv1a1.DriftPolicyReport {
+		return nil, errors.Errorf("Invalid drift policy %q: must be %q or %q", s.driftPolicy, fedv1a1.DriftPolicyCorrect, fedv1a1.DriftPolicyReport)
+	}
+	s.conflictPolicy = typeConfig.GetConflictPolicy()
+	if !util.IsValidConflictPolicy(s.conflictPolicy) {
+		return nil, errors.Errorf("Invalid conflict policy %q: must be %q, %q or %q", s.conflictPolicy,
+			fedv1a1.ConflictPolicyAdopt, fedv1a1.ConflictPolicySkip, fedv1a1.ConflictPolicyFail)
+	}
+	s.revisionHistoryLimit = typeConfig.GetRevisionHistoryLimit()
+	if s.revisionHistoryLimit < 0 {
+		return nil, errors.Errorf("Invalid revision history limit %d: must not be negative", s.revisionHistoryLimit)
+	}
+
+	clusterCache := typeConfig.GetClusterCache()
+	if clusterCache != fedv1a1.ClusterCacheTrimmed && clusterCache != fedv1a1.ClusterCacheFull {
+		return nil, errors.Errorf("Invalid cluster cache mode %q: must be %q or %q", clusterCache, fedv1a1.ClusterCacheTrimmed, fedv1a1.ClusterCacheFull)
+	}
+	statusMode := typeConfig.GetStatusMode()
+	if statusMode != fedv1a1.StatusModeObject && statusMode != fedv1a1.StatusModeSubresource {
+		return nil, errors.Errorf("Invalid status mode %q: must be %q or %q", statusMode, fedv1a1.StatusModeObject, fedv1a1.StatusModeSubresource)
+	}
+
 	// Federated informer on the resource type in members of federation.
-	var err error
-	s.informer, err = util.NewFederatedInformer(
-		controllerConfig,
-		client,
-		&targetAPIResource,
-		func(obj pkgruntime.Object) {
-			qualifiedName := util.NewQualifiedName(obj)
-			s.worker.EnqueueForRetry(qualifiedName)
+	triggerFunc := func(obj pkgruntime.Object) {
+		qualifiedName := util.NewQualifiedName(obj)
+		s.worker.EnqueueForRetry(qualifiedName)
+	}
+	clusterLifecycle := &util.ClusterLifecycleHandlerFuncs{
+		ClusterAvailable: func(cluster *fedv1a1.FederatedCluster) {
+			// When new cluster becomes available process all the target resources again.
+			s.clusterDeliverer.DeliverAt(allClustersKey, nil, time.Now().Add(s.clusterAvailableDelay))
 		},
-		&util.ClusterLifecycleHandlerFuncs{
-			ClusterAvailable: func(cluster *fedv1a1.FederatedCluster) {
-				// When new cluster becomes available process all the target resources again.
-				s.clusterDeliverer.DeliverAt(allClustersKey, nil, time.Now().Add(s.clusterAvailableDelay))
-			},
-			// When a cluster becomes unavailable process all the target resources again.
-			ClusterUnavailable: func(cluster *fedv1a1.FederatedCluster, _ []interface{}) {
-				s.clusterDeliverer.DeliverAt(allClustersKey, nil, time.Now().Add(s.clusterUnavailableDelay))
-			},
+		// When a cluster becomes unavailable process all the target resources again.
+		ClusterUnavailable: func(cluster *fedv1a1.FederatedCluster, _ []interface{}) {
+			s.clusterDeliverer.DeliverAt(allClustersKey, nil, time.Now().Add(s.clusterUnavailableDelay))
 		},
-	)
+	}
+	if clusterCache == fedv1a1.ClusterCacheFull {
+		s.informer, err = util.NewFederatedInformer(controllerConfig, client, &targetAPIResource, triggerFunc, clusterLifecycle)
+	} else {
+		s.cacheManagedOnly = true
+		trimFunc := func(obj *unstructured.Unstructured) *unstructured.Unstructured {
+			return obj
+		}
+		// Computing a merge patch and calling the update webhook
+		// require the full target resource.
+		if s.propagationMode == fedv1a1.PropagationModeReplace && s.updateWebhook == nil {
+			s.cacheTrimmed = true
+			trimFunc, err = util.NewTrimFunc(s.retainedFields)
+			if err != nil {
+				return nil, err
+			}
+		}
+		s.informer, err = util.NewTrimmedFederatedInformer(controllerConfig, client, &targetAPIResource, trimFunc, triggerFunc, clusterLifecycle)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	// Federated updater along with Create/Update/Delete operations.
-	s.updater = util.NewFederatedUpdater(s.informer, targetAPIResource.Kind, s.updateTimeout, s.eventRecorder,
+	s.updater = util.NewFederatedUpdater(s.informer, targetAPIResource.Kind, s.updateTimeout,
+		controllerConfig.MaxConcurrentClusterOperations, controllerConfig.ClusterWriteRateLimiter, s.eventRecorder,
 		func(client util.ResourceClient, rawObj pkgruntime.Object) (string, error) {
 			obj := rawObj.(*unstructured.Unstructured)
 			createdObj, err := client.Resources(obj.GetNamespace()).Create(obj, metav1.CreateOptions{})
@@ -163,11 +271,19 @@ func newFederationSyncController(controllerConfig *util.ControllerConfig, typeCo
 			qualifiedName := util.NewQualifiedName(obj)
 			orphanDependents := false
 			return "", client.Resources(qualifiedName.Namespace).Delete(qualifiedName.Name, &metav1.DeleteOptions{OrphanDependents: &orphanDependents})
+		},
+		func(client util.ResourceClient, obj pkgruntime.Object, patchType types.PatchType, patch []byte) (string, error) {
+			qualifiedName := util.NewQualifiedName(obj)
+			patchedObj, err := client.Resources(qualifiedName.Namespace).Patch(qualifiedName.Name, patchType, patch, metav1.UpdateOptions{})
+			if err != nil {
+				return "", err
+			}
+			return util.ObjectVersion(patchedObj), err
 		})
 
 	s.fedAccessor, err = NewFederatedResourceAccessor(
 		controllerConfig, typeConfig, fedNamespaceAPIResource,
-		client, s.worker.EnqueueObject, s.informer, s.updater)
+		client, s.worker.EnqueueObject, s.informer, s.updater, placementPolicyManager)
 	if err != nil {
 		return nil, err
 	}
@@ -281,7 +397,7 @@ func (s *FederationSyncController) reconcile(qualifiedName util.QualifiedName) u
 }
 
 // syncToClusters ensures that the state of the given object is synchronized to
-// member clusters.
+// member clusters and records the outcome in the status of the object.
 func (s *FederationSyncController) syncToClusters(fedResource FederatedResource) util.ReconciliationStatus {
 	kind := s.typeConfig.GetFederatedType().Kind
 	key := fedResource.FederatedName().String()
@@ -291,29 +407,384 @@ func (s *FederationSyncController) syncToClusters(fedResource FederatedResource)
 		runtime.HandleError(errors.Wrap(err, "Failed to get cluster list"))
 		return util.StatusNotSynced
 	}
+	unreadyClusters, err := s.informer.GetUnreadyClusters()
+	if err != nil {
+		runtime.HandleError(errors.Wrap(err, "Failed to get cluster list"))
+		return util.StatusNotSynced
+	}
+
+	previousStatus, err := util.GetPropagationStatus(fedResource.Object())
+	if err != nil {
+		runtime.HandleError(errors.Wrapf(err, "Failed to read propagation status for %s %q", kind, key))
+		return util.StatusError
+	}
+
+	result := newPropagationResult()
+	result.setObservedGeneration(fedResource.ObservedGeneration())
+	s.recordRevision(fedResource, previousStatus, result)
+	reconciliationStatus := s.propagate(fedResource, clusters, previousStatus, result)
+	rollbackPending, err := s.rollbackOnFailure(fedResource, previousStatus, result)
+	if err != nil {
+		runtime.HandleError(errors.Wrapf(err, "Failed to roll back %s %q", kind, key))
+		reconciliationStatus = util.StatusError
+	} else if rollbackPending && reconciliationStatus == util.StatusAllOK {
+		reconciliationStatus = util.StatusNeedsRecheck
+	}
 
-	selectedClusters, unselectedClusters, err := fedResource.ComputePlacement(clusters)
+	status := computePropagationStatus(previousStatus, result, clusters, unreadyClusters, metav1.Now())
+	s.recordDriftEvents(fedResource, previousStatus, status)
+	if propagationStatusEqual(previousStatus, status) {
+		return reconciliationStatus
+	}
+	err = fedResource.UpdatePropagationStatus(status)
 	if err != nil {
-		runtime.HandleError(errors.Wrapf(err, "Failed to compute placement for %s %q", kind, key))
+		runtime.HandleError(errors.Wrapf(err, "Failed to update propagation status for %s %q", kind, key))
 		return util.StatusError
 	}
 
+	return reconciliationStatus
+}
+
+// recordRevision records the current template and overrides of the
+// given federated resource as its latest revision if revision history
+// is enabled for the type.
+func (s *FederationSyncController) recordRevision(fedResource FederatedResource, previousStatus *util.PropagationStatus, result *propagationResult) {
+	// The template of a federated namespace is the containing
+	// namespace, which cannot be restored from a revision.
+	if s.revisionHistoryLimit == 0 || s.typeConfig.GetTarget().Kind == util.NamespaceKind {
+		return
+	}
+	kind := s.typeConfig.GetFederatedType().Kind
+	key := fedResource.FederatedName().String()
+	obj := fedResource.Object()
+
+	data, err := util.RevisionData(obj)
+	if err == nil && util.RevisionName(obj, data) == previousStatus.CurrentRevision {
+		result.setCurrentRevision(previousStatus.CurrentRevision)
+		return
+	}
+	revision, err := util.RecordRevision(s.kubeClient, util.RevisionNamespace(obj, s.federationNamespace), obj, s.revisionHistoryLimit)
+	if err != nil {
+		// Failure to record a revision does not prevent propagation
+		// but precludes rollback from the current revision.
+		runtime.HandleError(errors.Wrapf(err, "Failed to record revision for %s %q", kind, key))
+		return
+	}
+	result.setCurrentRevision(revision.Name)
+}
+
+// rollbackOnFailure restores the previous revision of the given
+// federated resource if it specifies automatic rollback and the
+// current revision failed to update or is unhealthy in enough of the
+// selected clusters, or its rollout was halted.  A revision that was
+// restored by a rollback is not itself rolled back.  Returns whether
+// a failure has not yet persisted for long enough to trigger a
+// rollback, in which case the resource should be rechecked.
+func (s *FederationSyncController) rollbackOnFailure(fedResource FederatedResource, previousStatus *util.PropagationStatus, result *propagationResult) (bool, error) {
+	currentRevision := result.currentRevision
+	if len(currentRevision) == 0 {
+		return false, nil
+	}
+	if previousStatus.Rollback != nil && previousStatus.Rollback.RestoredRevision == currentRevision {
+		return false, nil
+	}
+	obj := fedResource.Object()
+	threshold, err := util.GetFailureThresholdPercent(obj)
+	if err != nil || threshold == 0 {
+		return false, err
+	}
+	deadline, err := util.GetRollbackDeadline(obj)
+	if err != nil {
+		return false, err
+	}
+	unhealthyClusters, err := s.unhealthyClusters(fedResource, result)
+	if err != nil {
+		return false, err
+	}
+
+	reason, persisted := rollbackReason(previousStatus, result, unhealthyClusters, threshold, deadline, metav1.Now())
+	if len(reason) == 0 {
+		return false, nil
+	}
+	if !persisted {
+		return true, nil
+	}
+
+	revisions, err := util.ListRevisions(s.kubeClient, util.RevisionNamespace(obj, s.federationNamespace), obj)
+	if err != nil {
+		return false, err
+	}
+	previousRevision := util.PreviousRevision(revisions, currentRevision)
+	if previousRevision == nil {
+		// There is no revision to roll back to.
+		return false, nil
+	}
+	err = fedResource.RestoreRevision(previousRevision)
+	if err != nil {
+		return false, err
+	}
+	result.setRevisionFailure(nil)
+	result.setRollback(&util.RollbackStatus{
+		FailedRevision:   currentRevision,
+		RestoredRevision: previousRevision.Name,
+		Reason:           reason,
+		Time:             metav1.Now(),
+	})
+	s.eventRecorder.Eventf(fedResource.Object(), corev1.EventTypeWarning, "RolledBack",
+		"Rolled back from revision %q to revision %q: %s", currentRevision, previousRevision.Name, reason)
+	return false, nil
+}
+
+// unhealthyClusters returns the reason that the target resource is
+// not healthy for each selected cluster in which it is in the desired
+// state.
+func (s *FederationSyncController) unhealthyClusters(fedResource FederatedResource, result *propagationResult) (map[string]string, error) {
+	targetKey := fedResource.TargetName().String()
+	unhealthyClusters := make(map[string]string)
+	for _, clusterName := range result.selectedClusters {
+		if result.clusterStates[clusterName].state != util.ClusterPropagationPlaced {
+			continue
+		}
+		clusterObj, found, err := s.informer.GetTargetStore().GetByKey(clusterName, targetKey)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		if healthy, reason := util.TargetHealthy(clusterObj.(*unstructured.Unstructured)); !healthy {
+			unhealthyClusters[clusterName] = reason
+		}
+	}
+	return unhealthyClusters, nil
+}
+
+// rollbackReason returns why the current revision should be rolled
+// back, if it should, and whether it should be rolled back now.  A
+// halted rollout is rolled back immediately.  Otherwise the revision
+// must have failed to update or been unhealthy in enough of the
+// selected clusters for the given deadline, so that a transient
+// failure (e.g. while the updated resources become available) does
+// not trigger a rollback.  When the failure of the current revision
+// began is recorded in the result.
+func rollbackReason(previousStatus *util.PropagationStatus, result *propagationResult, unhealthyClusters map[string]string,
+	threshold int32, deadline time.Duration, now metav1.Time) (string, bool) {
+
+	halted := result.rolloutComputed && result.rollout != nil && result.rollout.Halted
+	failedClusters := result.failedClusters()
+	for clusterName := range unhealthyClusters {
+		failedClusters = append(failedClusters, clusterName)
+	}
+	sort.Strings(failedClusters)
+	selectedCount := len(result.selectedClusters)
+
+	var reason string
+	switch {
+	case halted:
+		reason = fmt.Sprintf("The rollout was halted: %s", result.rollout.Message)
+	case len(failedClusters) == 0 || len(failedClusters)*100 < int(threshold)*selectedCount:
+		result.setRevisionFailure(nil)
+		return "", false
+	case len(unhealthyClusters) > 0:
+		reason = fmt.Sprintf("Failed to update or unhealthy in %d of %d selected clusters: %s",
+			len(failedClusters), selectedCount, strings.Join(failedClusters, ", "))
+	default:
+		reason = fmt.Sprintf("Failed to update %d of %d selected clusters: %s",
+			len(failedClusters), selectedCount, strings.Join(failedClusters, ", "))
+	}
+
+	failure := &util.RevisionFailure{Revision: result.currentRevision, StartTime: now}
+	if previous := previousStatus.RevisionFailure; previous != nil && previous.Revision == result.currentRevision {
+		failure.StartTime = previous.StartTime
+	}
+	result.setRevisionFailure(failure)
+	return reason, halted || !now.Time.Before(failure.StartTime.Add(deadline))
+}
+
+// recordDriftEvents emits an event for each cluster in which drift was
+// newly detected.
+func (s *FederationSyncController) recordDriftEvents(fedResource FederatedResource, previousStatus, status *util.PropagationStatus) {
+	kind := s.typeConfig.GetTarget().Kind
+	key := fedResource.TargetName().String()
+	for _, clusterStatus := range status.Clusters {
+		if clusterStatus.State != util.ClusterPropagationDrifted {
+			continue
+		}
+		if previous := previousStatus.ClusterStatus(clusterStatus.Cluster); previous != nil && previous.State == util.ClusterPropagationDrifted {
+			continue
+		}
+		s.eventRecorder.Eventf(fedResource.Object(), corev1.EventTypeWarning, "DriftDetected",
+			"%s %q in cluster %q differs from the desired state: %s", kind, key, clusterStatus.Cluster, clusterStatus.Reason)
+	}
+}
+
+// propagate performs the operations required to propagate the given
+// object to the selected clusters and records the outcome in the
+// provided result.
+func (s *FederationSyncController) propagate(fedResource FederatedResource, clusters []*fedv1a1.FederatedCluster, previousStatus *util.PropagationStatus, result *propagationResult) util.ReconciliationStatus {
+	kind := s.typeConfig.GetFederatedType().Kind
+	key := fedResource.FederatedName().String()
+
+	selectedClusters, unselectedClusters, deniedClusters, err := fedResource.ComputePlacement(clusters)
+	if err != nil {
+		wrappedErr := errors.Wrapf(err, "Failed to compute placement for %s %q", kind, key)
+		runtime.HandleError(wrappedErr)
+		result.setFailure(ComputePlacementFailed, wrappedErr)
+		return util.StatusError
+	}
+	result.setSelectedClusters(selectedClusters)
+	for clusterName, reason := range deniedClusters {
+		result.setClusterState(clusterName, util.ClusterPropagationDenied, reason)
+	}
+
 	glog.V(3).Infof("Syncing %s %q in underlying clusters, selected clusters are: %s, unselected clusters are: %s",
 		kind, key, selectedClusters, unselectedClusters)
 
-	operations, err := s.clusterOperations(selectedClusters, unselectedClusters, fedResource)
+	var operations []util.FederatedOperation
+	var drift map[string]clusterDrift
+	var conflicts map[string]fedv1a1.ConflictPolicy
+	var webhookFailures map[string]error
+	removalPolicy, err := util.GetRemovalPolicy(fedResource.Object())
+	if err != nil {
+		s.eventRecorder.Eventf(fedResource.Object(), corev1.EventTypeWarning, "FedClusterOperationsError",
+			"Error obtaining sync operations for %s %q: %v", kind, key, err)
+		result.setFailure(ComputeOperationsFailed, err)
+		return util.StatusError
+	}
+	correctDrift, correctionRequest, err := s.driftCorrection(fedResource, previousStatus)
+	if err == nil {
+		operations, drift, conflicts, webhookFailures, err = s.clusterOperations(selectedClusters, unselectedClusters, fedResource, correctDrift, removalPolicy)
+	}
 	if err != nil {
 		s.eventRecorder.Eventf(fedResource.Object(), corev1.EventTypeWarning, "FedClusterOperationsError",
 			"Error obtaining sync operations for %s %q: %v", kind, key, err)
+		result.setFailure(ComputeOperationsFailed, err)
 		return util.StatusError
 	}
 
-	if len(operations) == 0 {
+	// Clusters that do not require an operation are assumed to be in
+	// the desired state.  A cluster for which the update webhook
+	// failed requires an update that could not be determined.
+	operationClusters := sets.NewString()
+	for _, operation := range operations {
+		operationClusters.Insert(operation.ClusterName)
+	}
+	for _, clusterName := range selectedClusters {
+		if err, failed := webhookFailures[clusterName]; failed {
+			result.setClusterState(clusterName, util.ClusterPropagationUpdateFailed, err.Error())
+			continue
+		}
+		if !operationClusters.Has(clusterName) {
+			result.setClusterState(clusterName, util.ClusterPropagationPlaced, "")
+		}
+	}
+	for clusterName, clusterDrift := range drift {
+		result.setClusterDrift(clusterName, clusterDrift.reason, clusterDrift.fields)
+	}
+	pauseReason := fedResource.PauseReason()
+	if failed := s.handleConflicts(fedResource, conflicts, len(pauseReason) > 0, result); failed {
+		return util.StatusError
+	}
+	targetKey := fedResource.TargetName().String()
+	for _, clusterName := range unselectedClusters {
+		if operationClusters.Has(clusterName) {
+			continue
+		}
+		// A target resource that is not found has been removed, and
+		// a cluster denied by a placement policy remains reported as
+		// denied once it has been.  A managed target resource that
+		// was found without requiring removal has been retained by
+		// the removal policy.  Any other target resource that was
+		// found has been skipped or orphaned and its state is not
+		// changed.  A skipped or orphaned resource is not found if
+		// only managed resources are cached.
+		clusterObj, found, err := s.informer.GetTargetStore().GetByKey(clusterName, targetKey)
+		switch {
+		case err != nil:
+		case !found && len(deniedClusters[clusterName]) > 0:
+		case !found && s.cacheManagedOnly && unmanagedState(previousStatus.ClusterStatus(clusterName)):
+		case !found:
+			result.setClusterState(clusterName, util.ClusterPropagationDeleted, unselectedReason)
+		case removalPolicy == util.RemovalPolicyRetain && util.IsManagedByFederation(clusterObj.(*unstructured.Unstructured)):
+			result.setClusterState(clusterName, util.ClusterPropagationRetained, unselectedReason)
+		}
+	}
+
+	// While propagation is paused, the operations that would have
+	// been performed are reported but not performed.  Drift has
+	// already been reported for clusters that would have been
+	// corrected.
+	if len(pauseReason) > 0 {
+		for _, operation := range operations {
+			result.setClusterState(operation.ClusterName, util.ClusterPropagationPaused, pauseReason)
+		}
 		return util.StatusAllOK
 	}
 
-	// TODO(marun) raise the visibility of operationErrors to aid in debugging
+	operations, awaitingDependencies, err := s.awaitDependencies(fedResource, operations, result)
+	if err != nil {
+		wrappedErr := errors.Wrapf(err, "Failed to check dependencies of %s %q", kind, key)
+		runtime.HandleError(wrappedErr)
+		result.setFailure(ComputeOperationsFailed, wrappedErr)
+		return util.StatusError
+	}
+
+	operations, plan, err := s.rollout(fedResource, clusters, selectedClusters, operations, webhookFailures, previousStatus, result)
+	if err != nil {
+		wrappedErr := errors.Wrapf(err, "Failed to plan rollout for %s %q", kind, key)
+		runtime.HandleError(wrappedErr)
+		result.setFailure(ComputeOperationsFailed, wrappedErr)
+		return util.StatusError
+	}
+	// Drift is only corrected once all operations have been allowed
+	// to proceed.
+	allowed := !awaitingDependencies && len(webhookFailures) == 0 && (plan == nil || (plan.haltErr == nil && len(plan.withheldClusters) == 0))
+
+	// Dependencies in member clusters are not watched, so a resource
+	// awaiting its dependencies is checked again after a delay.
+	reconciliationStatus := util.StatusAllOK
+	if awaitingDependencies || (plan != nil && plan.inProgress) {
+		reconciliationStatus = util.StatusNeedsRecheck
+	}
+	// A failed update webhook is retried like a failed operation.
+	if len(webhookFailures) > 0 {
+		reconciliationStatus = util.StatusError
+	}
+
+	if len(operations) == 0 {
+		if allowed {
+			result.setCorrectedDrift(correctionRequest)
+		}
+		return reconciliationStatus
+	}
+
 	versionMap, operationErrors := s.updater.Update(operations)
+	if len(operationErrors) == 0 && allowed {
+		result.setCorrectedDrift(correctionRequest)
+	}
+
+	for _, operation := range operations {
+		clusterName := operation.ClusterName
+		err, failed := operationErrors[clusterName]
+		switch {
+		case failed && errors.Cause(err) == util.ErrOperationTimeout:
+			result.setClusterState(clusterName, util.ClusterPropagationPending, err.Error())
+		case failed:
+			result.setClusterState(clusterName, util.ClusterPropagationUpdateFailed, err.Error())
+		case operation.Type == util.OperationTypeDelete:
+			result.setClusterState(clusterName, util.ClusterPropagationDeleted, unselectedReason)
+		case operation.Type == util.OperationTypeOrphan:
+			result.setClusterState(clusterName, util.ClusterPropagationOrphaned, unselectedReason)
+		default:
+			result.setClusterState(clusterName, util.ClusterPropagationPlaced, "")
+		}
+		// A failure to update a cluster halts the rollout.
+		if plan != nil && failed && !isRemoval(operation) &&
+			errors.Cause(err) != util.ErrOperationTimeout && plan.haltErr == nil {
+			plan.halt(fmt.Sprintf("Failed to update cluster %q: %v", clusterName, err))
+			result.setFailure(RolloutHalted, plan.haltErr)
+		}
+	}
 
 	err = fedResource.UpdateVersions(selectedClusters, versionMap)
 	if err != nil {
@@ -329,12 +800,185 @@ func (s *FederationSyncController) syncToClusters(fedResource FederatedResource)
 		return util.StatusError
 	}
 
-	return util.StatusAllOK
+	return reconciliationStatus
+}
+
+// rollout applies the rollout strategy of the given federated
+// resource, if any, to the given operations.  Add and update
+// operations withheld by the strategy are removed from the returned
+// operations and their clusters are recorded as pending.  A cluster
+// for which the update webhook failed is pending an update and halts
+// the rollout as a failed update would.
+func (s *FederationSyncController) rollout(fedResource FederatedResource, clusters []*fedv1a1.FederatedCluster, selectedClusters []string,
+	operations []util.FederatedOperation, webhookFailures map[string]error, previousStatus *util.PropagationStatus,
+	result *propagationResult) ([]util.FederatedOperation, *rolloutPlan, error) {
+
+	directive, err := util.GetRolloutDirective(fedResource.Object())
+	if err != nil {
+		return nil, nil, err
+	}
+	result.setRollout(nil)
+	if directive == nil {
+		return operations, nil, nil
+	}
+
+	templateVersion, err := fedResource.TemplateVersion()
+	if err != nil {
+		return nil, nil, err
+	}
+	overrideVersion, err := fedResource.OverrideVersion()
+	if err != nil {
+		return nil, nil, err
+	}
+	revision := fmt.Sprintf("%s-%s", templateVersion, overrideVersion)
+
+	pendingClusters := sets.NewString()
+	for _, operation := range operations {
+		if !isRemoval(operation) {
+			pendingClusters.Insert(operation.ClusterName)
+		}
+	}
+	for clusterName := range webhookFailures {
+		pendingClusters.Insert(clusterName)
+	}
+	targetKey := fedResource.TargetName().String()
+	unhealthyClusters := make(map[string]string)
+	for _, clusterName := range selectedClusters {
+		if pendingClusters.Has(clusterName) {
+			continue
+		}
+		clusterObj, found, err := s.informer.GetTargetStore().GetByKey(clusterName, targetKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !found {
+			continue
+		}
+		if healthy, reason := util.TargetHealthy(clusterObj.(*unstructured.Unstructured)); !healthy {
+			unhealthyClusters[clusterName] = reason
+		}
+	}
+
+	waves := rolloutWaves(directive, clusters, selectedClusters)
+	plan := planRollout(directive, previousStatus.Rollout, revision, waves, pendingClusters, unhealthyClusters, metav1.Now())
+	if plan.haltErr == nil && len(webhookFailures) > 0 {
+		failedClusters := []string{}
+		for clusterName := range webhookFailures {
+			failedClusters = append(failedClusters, clusterName)
+		}
+		sort.Strings(failedClusters)
+		clusterName := failedClusters[0]
+		plan.halt(fmt.Sprintf("Failed to update cluster %q: %v", clusterName, webhookFailures[clusterName]))
+	}
+	result.setRollout(plan.status)
+	if plan.haltErr != nil {
+		result.setFailure(RolloutHalted, plan.haltErr)
+	}
+
+	allowedOperations := []util.FederatedOperation{}
+	for _, operation := range operations {
+		if isRemoval(operation) || plan.allowedClusters.Has(operation.ClusterName) {
+			allowedOperations = append(allowedOperations, operation)
+			continue
+		}
+		plan.withheldClusters = append(plan.withheldClusters, operation.ClusterName)
+		result.setClusterState(operation.ClusterName, util.ClusterPropagationPending, plan.waitReason)
+	}
+	return allowedOperations, plan, nil
+}
+
+// unmanagedState indicates whether the given cluster status records a
+// target resource that was left unmanaged by being skipped or
+// orphaned.
+func unmanagedState(clusterStatus *util.ClusterPropagationStatus) bool {
+	return clusterStatus != nil && (clusterStatus.State == util.ClusterPropagationAlreadyExists ||
+		clusterStatus.State == util.ClusterPropagationOrphaned)
+}
+
+// isRemoval indicates whether the given operation removes the target
+// resource from a cluster that is no longer selected.  Removals are
+// not subject to the rollout strategy.
+func isRemoval(operation util.FederatedOperation) bool {
+	return operation.Type == util.OperationTypeDelete || operation.Type == util.OperationTypeOrphan
+}
+
+// driftCorrection determines whether drift should be corrected for the
+// given federated resource.  If correction was requested by the
+// correct-drift annotation, the value of the annotation is also
+// returned so that it can be recorded once drift has been corrected.
+// Drift is not corrected while propagation is paused so that it is
+// reported whatever the drift policy.
+func (s *FederationSyncController) driftCorrection(fedResource FederatedResource, previousStatus *util.PropagationStatus) (bool, string, error) {
+	if len(fedResource.PauseReason()) > 0 {
+		return false, "", nil
+	}
+	policy, err := util.GetDriftPolicy(fedResource.Object(), s.driftPolicy)
+	if err != nil {
+		return false, "", err
+	}
+	if policy == fedv1a1.DriftPolicyCorrect {
+		return true, "", nil
+	}
+	request := fedResource.Object().GetAnnotations()[util.CorrectDriftAnnotation]
+	if len(request) > 0 && request != previousStatus.CorrectedDrift {
+		return true, request, nil
+	}
+	return false, "", nil
+}
+
+// handleConflicts records the outcome of applying the conflict policy
+// to the clusters containing an unmanaged target resource.  Adoption
+// is not reported while propagation is paused.  Returns true if a
+// conflict requires propagation to fail.
+func (s *FederationSyncController) handleConflicts(fedResource FederatedResource, conflicts map[string]fedv1a1.ConflictPolicy, paused bool, result *propagationResult) bool {
+	kind := s.typeConfig.GetTarget().Kind
+	key := fedResource.TargetName().String()
+
+	failedClusters := []string{}
+	for clusterName, policy := range conflicts {
+		switch policy {
+		case fedv1a1.ConflictPolicyAdopt:
+			if paused {
+				continue
+			}
+			s.eventRecorder.Eventf(fedResource.Object(), corev1.EventTypeNormal, "AdoptInCluster",
+				"Adopting unmanaged %s %q in cluster %q", kind, key, clusterName)
+		case fedv1a1.ConflictPolicySkip:
+			result.setClusterState(clusterName, util.ClusterPropagationAlreadyExists, unmanagedReason)
+		case fedv1a1.ConflictPolicyFail:
+			result.setClusterState(clusterName, util.ClusterPropagationAlreadyExists, unmanagedReason)
+			failedClusters = append(failedClusters, clusterName)
+		}
+	}
+	if len(failedClusters) == 0 {
+		return false
+	}
+	sort.Strings(failedClusters)
+	err := errors.Errorf("Unmanaged %s %q exists in clusters: %s", kind, key, strings.Join(failedClusters, ", "))
+	s.eventRecorder.Eventf(fedResource.Object(), corev1.EventTypeWarning, "UnmanagedResourceConflict", "%v", err)
+	result.setFailure(UnmanagedResourceConflict, err)
+	return true
+}
+
+// clusterDrift describes the drift of a target resource in a cluster.
+type clusterDrift struct {
+	reason string
+	fields []util.DriftedField
 }
 
 // clusterOperations returns the list of operations needed to synchronize the
-// state of the given object to the provided clusters.
-func (s *FederationSyncController) clusterOperations(selectedClusters, unselectedClusters []string, fedResource FederatedResource) ([]util.FederatedOperation, error) {
+// state of the given object to the provided clusters.  Unless drift is to
+// be corrected, no operation is returned for a cluster whose target
+// resource was changed or removed since it was last propagated, and the
+// drift is returned instead.  The conflict policy applied to each
+// cluster containing a target resource not managed by federation is
+// also returned, and only an adopted resource is updated.  Managed
+// resources in unselected clusters are removed according to the given
+// removal policy, and unmanaged resources are not removed.  The update
+// webhook is only called for a cluster that requires an update, and a
+// failed call is returned for that cluster instead of an operation.
+func (s *FederationSyncController) clusterOperations(selectedClusters, unselectedClusters []string, fedResource FederatedResource, correctDrift bool,
+	removalPolicy util.RemovalPolicy) ([]util.FederatedOperation, map[string]clusterDrift, map[string]fedv1a1.ConflictPolicy, map[string]error, error) {
 	// Cluster operations require the target kind (which differs from
 	// the federated kind) and target name (which may differ from the
 	// federated name).
@@ -342,64 +986,175 @@ func (s *FederationSyncController) clusterOperations(selectedClusters, unselecte
 	key := fedResource.TargetName().String()
 
 	operations := make([]util.FederatedOperation, 0)
+	drift := make(map[string]clusterDrift)
+	conflicts := make(map[string]fedv1a1.ConflictPolicy)
+	webhookFailures := make(map[string]error)
 
 	versionMap, err := fedResource.GetVersions()
 	if err != nil {
-		return nil, errors.Wrapf(err, "Error retrieving version map for %s %q", kind, key)
+		return nil, nil, nil, nil, errors.Wrapf(err, "Error retrieving version map for %s %q", kind, key)
+	}
+
+	conflictPolicy, err := util.GetConflictPolicy(fedResource.Object(), s.conflictPolicy)
+	if err != nil {
+		return nil, nil, nil, nil, err
 	}
 
 	for _, clusterName := range selectedClusters {
 		// TODO(marun) Create the desired object only if needed
 		desiredObj, err := fedResource.ObjectForCluster(clusterName)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, nil, err
 		}
 
 		// TODO(marun) Wait until result of add operation has reached
 		// the target store before attempting subsequent operations?
 		// Otherwise the object won't be found but an add operation
 		// will fail with AlreadyExists.
-		clusterObj, found, err := s.informer.GetTargetStore().GetByKey(clusterName, key)
+		clusterObj, found, err := s.clusterObject(clusterName, fedResource.TargetName())
 		if err != nil {
 			wrappedErr := errors.Wrapf(err, "Failed to get %s %q from cluster %q", kind, key, clusterName)
 			runtime.HandleError(wrappedErr)
-			return nil, wrappedErr
+			return nil, nil, nil, nil, wrappedErr
 		}
 
-		var operationType util.FederatedOperationType = ""
+		operation := util.FederatedOperation{
+			Obj:         desiredObj,
+			ClusterName: clusterName,
+			Key:         key,
+		}
 
 		if found {
-			clusterObj := clusterObj.(*unstructured.Unstructured)
-
 			if fedResource.SkipClusterChange(clusterObj) {
 				continue
 			}
 
-			desiredObj, err = s.objectForUpdateOp(desiredObj, clusterObj)
+			// A recorded version indicates that the desired state has
+			// not changed since it was last propagated to the cluster.
+			version, propagated := versionMap[clusterName]
+
+			// A resource propagated before resources were labeled as
+			// managed is updated to add the label.  Any other
+			// unmanaged resource is subject to the conflict policy.
+			managed := util.IsManagedByFederation(clusterObj)
+			if !managed && !propagated {
+				conflicts[clusterName] = conflictPolicy
+				if conflictPolicy != fedv1a1.ConflictPolicyAdopt {
+					continue
+				}
+			}
+
+			err = s.prepareForUpdateOp(desiredObj, clusterObj)
 			if err != nil {
 				wrappedErr := errors.Wrapf(err, "Failed to determine desired object %s %q for cluster %q", kind, key, clusterName)
 				runtime.HandleError(wrappedErr)
-				return nil, wrappedErr
+				return nil, nil, nil, nil, wrappedErr
+			}
+
+			needsUpdate := false
+			if s.propagationMode == fedv1a1.PropagationModeMerge {
+				// Only the fields managed by federation are compared,
+				// so the recorded version is not relevant.
+				operation.PatchType, operation.Patch, err = s.mergePatchForUpdateOp(desiredObj, clusterObj)
+				if err != nil {
+					wrappedErr := errors.Wrapf(err, "Failed to compute patch for %s %q for cluster %q", kind, key, clusterName)
+					runtime.HandleError(wrappedErr)
+					return nil, nil, nil, nil, wrappedErr
+				}
+				needsUpdate = operation.Patch != nil
+			} else {
+				needsUpdate = !propagated || !managed || util.ObjectNeedsUpdate(desiredObj, clusterObj, version)
 			}
 
-			version, ok := versionMap[clusterName]
-			if !ok || util.ObjectNeedsUpdate(desiredObj, clusterObj, version) {
-				operationType = util.OperationTypeUpdate
+			if needsUpdate && s.updateWebhook != nil {
+				desiredObj, err = s.updateWebhook.DesiredObject(clusterName, desiredObj, clusterObj)
+				if err != nil {
+					wrappedErr := errors.Wrapf(err, "Failed to call update webhook for %s %q for cluster %q", kind, key, clusterName)
+					runtime.HandleError(wrappedErr)
+					webhookFailures[clusterName] = wrappedErr
+					continue
+				}
+				// The object returned by the webhook may not
+				// require an update of the fields managed by
+				// federation.
+				if s.propagationMode == fedv1a1.PropagationModeMerge {
+					operation.PatchType, operation.Patch, err = s.mergePatchForUpdateOp(desiredObj, clusterObj)
+					if err != nil {
+						wrappedErr := errors.Wrapf(err, "Failed to compute patch for %s %q for cluster %q", kind, key, clusterName)
+						runtime.HandleError(wrappedErr)
+						return nil, nil, nil, nil, wrappedErr
+					}
+					needsUpdate = operation.Patch != nil
+				}
+			}
+			operation.Obj = desiredObj
+
+			if needsUpdate && propagated && managed && !correctDrift {
+				fullClusterObj, err := s.fullClusterObject(clusterName, clusterObj)
+				if err != nil {
+					wrappedErr := errors.Wrapf(err, "Failed to get %s %q from cluster %q", kind, key, clusterName)
+					runtime.HandleError(wrappedErr)
+					return nil, nil, nil, nil, wrappedErr
+				}
+				fields, err := util.DriftedFields(desiredObj, fullClusterObj)
+				if err != nil {
+					wrappedErr := errors.Wrapf(err, "Failed to determine drift of %s %q in cluster %q", kind, key, clusterName)
+					runtime.HandleError(wrappedErr)
+					return nil, nil, nil, nil, wrappedErr
+				}
+				// A change that does not affect the fields compared
+				// for drift (e.g. a change to a field defaulted in
+				// the cluster) does not require reporting.
+				if len(fields) > 0 {
+					drift[clusterName] = clusterDrift{
+						reason: fmt.Sprintf("%d field(s) differ from the desired state", len(fields)),
+						fields: fields,
+					}
+				}
+				continue
+			}
+			if needsUpdate {
+				operation.Type = util.OperationTypeUpdate
 			}
 		} else {
 			// A namespace in the host cluster will never need to be
 			// added since by definition it must already exist.
 
-			operationType = util.OperationTypeAdd
+			if _, propagated := versionMap[clusterName]; propagated && !correctDrift {
+				// The store may not yet reflect a recent add
+				// operation, so confirm removal before reporting it.
+				// Removal has already been confirmed if only
+				// managed resources are cached.
+				removed := s.cacheManagedOnly
+				if !removed {
+					removed, err = s.removedFromCluster(clusterName, fedResource.TargetName())
+					if err != nil {
+						wrappedErr := errors.Wrapf(err, "Failed to get %s %q from cluster %q", kind, key, clusterName)
+						runtime.HandleError(wrappedErr)
+						return nil, nil, nil, nil, wrappedErr
+					}
+				}
+				if removed {
+					drift[clusterName] = clusterDrift{
+						reason: "The resource was removed from the cluster",
+					}
+				}
+				continue
+			}
+
+			if s.propagationMode == fedv1a1.PropagationModeMerge {
+				err := util.SetLastAppliedConfiguration(desiredObj)
+				if err != nil {
+					wrappedErr := errors.Wrapf(err, "Failed to determine desired object %s %q for cluster %q", kind, key, clusterName)
+					runtime.HandleError(wrappedErr)
+					return nil, nil, nil, nil, wrappedErr
+				}
+			}
+			operation.Type = util.OperationTypeAdd
 		}
 
-		if len(operationType) > 0 {
-			operations = append(operations, util.FederatedOperation{
-				Type:        operationType,
-				Obj:         desiredObj,
-				ClusterName: clusterName,
-				Key:         key,
-			})
+		if len(operation.Type) > 0 {
+			operations = append(operations, operation)
 		}
 	}
 
@@ -408,123 +1163,116 @@ func (s *FederationSyncController) clusterOperations(selectedClusters, unselecte
 		if err != nil {
 			wrappedErr := errors.Wrapf(err, "Failed to get %s %q from cluster %q", kind, key, clusterName)
 			runtime.HandleError(wrappedErr)
-			return nil, wrappedErr
+			return nil, nil, nil, nil, wrappedErr
 		}
 		if found {
-			clusterObj := rawClusterObj.(pkgruntime.Object)
-			if fedResource.SkipClusterChange(clusterObj) {
+			clusterObj := rawClusterObj.(*unstructured.Unstructured)
+			if fedResource.SkipClusterChange(clusterObj) || !util.IsManagedByFederation(clusterObj) {
 				continue
 			}
-			operations = append(operations, util.FederatedOperation{
+			operation := util.FederatedOperation{
 				Type:        util.OperationTypeDelete,
 				Obj:         clusterObj,
 				ClusterName: clusterName,
 				Key:         key,
-			})
+			}
+			switch removalPolicy {
+			case util.RemovalPolicyRetain:
+				continue
+			case util.RemovalPolicyOrphan:
+				operation.Type = util.OperationTypeOrphan
+				operation.Obj, err = util.OrphanedObject(clusterObj)
+				if err == nil {
+					operation.PatchType, operation.Patch, err = util.OrphanPatch()
+				}
+				if err != nil {
+					wrappedErr := errors.Wrapf(err, "Failed to orphan %s %q in cluster %q", kind, key, clusterName)
+					runtime.HandleError(wrappedErr)
+					return nil, nil, nil, nil, wrappedErr
+				}
+			}
+			operations = append(operations, operation)
 		}
 	}
 
-	return operations, nil
-}
-
-// TODO(marun) Support webhooks for custom update behavior
-func (s *FederationSyncController) objectForUpdateOp(desiredObj, clusterObj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
-	// Pass the same ResourceVersion as in the cluster object for update operation, otherwise operation will fail.
-	desiredObj.SetResourceVersion(clusterObj.GetResourceVersion())
-
-	targetKind := s.typeConfig.GetTarget().Kind
-	if targetKind == util.ServiceKind {
-		return serviceForUpdateOp(desiredObj, clusterObj)
-	}
-	if targetKind == util.ServiceAccountKind {
-		return serviceAccountForUpdateOp(desiredObj, clusterObj)
-	}
-	return desiredObj, nil
+	return operations, drift, conflicts, webhookFailures, nil
 }
 
-func serviceForUpdateOp(desiredObj, clusterObj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
-	// ClusterIP and NodePort are allocated to Service by cluster, so retain the same if any while updating
-
-	// Retain clusterip
-	clusterIP, ok, err := unstructured.NestedString(clusterObj.Object, "spec", "clusterIP")
+// clusterObject returns the named target resource in the given
+// cluster from the informer's cache.  If only managed resources are
+// cached, a resource that is not found in the cache is retrieved from
+// the cluster since it may exist without being managed.
+func (s *FederationSyncController) clusterObject(clusterName string, qualifiedName util.QualifiedName) (*unstructured.Unstructured, bool, error) {
+	cachedObj, found, err := s.informer.GetTargetStore().GetByKey(clusterName, qualifiedName.String())
 	if err != nil {
-		return nil, errors.Wrap(err, "Error retrieving clusterIP from cluster service")
+		return nil, false, err
 	}
-	// !ok could indicate that a cluster ip was not assigned
-	if ok && clusterIP != "" {
-		err := unstructured.SetNestedField(desiredObj.Object, clusterIP, "spec", "clusterIP")
-		if err != nil {
-			return nil, errors.Wrap(err, "Error setting clusterIP for service")
-		}
+	if found {
+		return cachedObj.(*unstructured.Unstructured), true, nil
 	}
-
-	// Retain nodeports
-	clusterPorts, ok, err := unstructured.NestedSlice(clusterObj.Object, "spec", "ports")
+	if !s.cacheManagedOnly {
+		return nil, false, nil
+	}
+	client, err := s.informer.GetClientForCluster(clusterName)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error retrieving ports from cluster service")
+		return nil, false, err
 	}
-	if !ok {
-		return desiredObj, nil
+	clusterObj, err := client.Resources(qualifiedName.Namespace).Get(qualifiedName.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, false, nil
 	}
-	var desiredPorts []interface{}
-	desiredPorts, ok, err = unstructured.NestedSlice(desiredObj.Object, "spec", "ports")
 	if err != nil {
-		return nil, errors.Wrap(err, "Error retrieving ports from service")
+		return nil, false, err
 	}
-	if !ok {
-		desiredPorts = []interface{}{}
-	}
-	for desiredIndex := range desiredPorts {
-		for clusterIndex := range clusterPorts {
-			fPort := desiredPorts[desiredIndex].(map[string]interface{})
-			cPort := clusterPorts[clusterIndex].(map[string]interface{})
-			if !(fPort["name"] == cPort["name"] && fPort["protocol"] == cPort["protocol"] && fPort["port"] == cPort["port"]) {
-				continue
-			}
-			nodePort, ok := cPort["nodePort"]
-			if ok {
-				fPort["nodePort"] = nodePort
-			}
-		}
+	return clusterObj, true, nil
+}
+
+// fullClusterObject returns the given target resource in the given
+// cluster with all of its fields, retrieving it from the cluster if
+// the cached resource has been trimmed.
+func (s *FederationSyncController) fullClusterObject(clusterName string, clusterObj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if !s.cacheTrimmed {
+		return clusterObj, nil
 	}
-	err = unstructured.SetNestedSlice(desiredObj.Object, desiredPorts, "spec", "ports")
+	client, err := s.informer.GetClientForCluster(clusterName)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error setting ports for service")
+		return nil, err
 	}
-
-	return desiredObj, nil
+	return client.Resources(clusterObj.GetNamespace()).Get(clusterObj.GetName(), metav1.GetOptions{})
 }
 
-// serviceAccountForUpdateOp retains the 'secrets' field of a service account
-// if the desired representation does not include a value for the field.  This
-// ensures that the sync controller doesn't continually clear a generated
-// secret from a service account, prompting continual regeneration by the
-// service account controller in the member cluster.
-//
-// TODO(marun) Clearing a manually-set secrets field will require resetting
-// placement.  Is there a better way to do this?
-func serviceAccountForUpdateOp(desiredObj, clusterObj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
-	// Check whether the secrets field is populated in the desired object.
-	desiredSecrets, ok, err := unstructured.NestedSlice(desiredObj.Object, util.SecretsField)
+// removedFromCluster indicates whether the named target resource does
+// not exist in the given cluster.
+func (s *FederationSyncController) removedFromCluster(clusterName string, qualifiedName util.QualifiedName) (bool, error) {
+	client, err := s.informer.GetClientForCluster(clusterName)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error retrieving secrets from desired service account")
+		return false, err
 	}
-	if ok && len(desiredSecrets) > 0 {
-		// Field is populated, so an update to the target resource does not
-		// risk triggering a race with the service account controller.
-		return desiredObj, nil
+	_, err = client.Resources(qualifiedName.Namespace).Get(qualifiedName.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return true, nil
 	}
+	return false, err
+}
 
-	// Retrieve the secrets from the cluster object and retain them.
-	secrets, ok, err := unstructured.NestedSlice(clusterObj.Object, util.SecretsField)
+// prepareForUpdateOp prepares the desired object to update the given
+// cluster object.  Fields configured to be retained are copied from
+// the cluster object.
+func (s *FederationSyncController) prepareForUpdateOp(desiredObj, clusterObj *unstructured.Unstructured) error {
+	// Pass the same ResourceVersion as in the cluster object for update operation, otherwise operation will fail.
+	desiredObj.SetResourceVersion(clusterObj.GetResourceVersion())
+
+	return util.RetainFields(desiredObj, clusterObj, s.retainedFields)
+}
+
+// mergePatchForUpdateOp records the desired object as the last-applied
+// configuration and returns the patch needed to update the fields of
+// the cluster object managed by federation.  A nil patch indicates that
+// no update is required.
+func (s *FederationSyncController) mergePatchForUpdateOp(desiredObj, clusterObj *unstructured.Unstructured) (types.PatchType, []byte, error) {
+	err := util.SetLastAppliedConfiguration(desiredObj)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error retrieving secrets from service account")
-	}
-	if ok && len(secrets) > 0 {
-		err := unstructured.SetNestedField(desiredObj.Object, secrets, util.SecretsField)
-		if err != nil {
-			return nil, errors.Wrap(err, "Error setting secrets for service account")
-		}
+		return "", nil, err
 	}
-	return desiredObj, nil
+	return util.ThreeWayMergePatch(desiredObj, clusterObj)
 }
@@ -0,0 +1,204 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+)
+
+func TestRollbackReason(t *testing.T) {
+	now := metav1.Now()
+	deadline := 10 * time.Minute
+	failingSince := func(revision string, age time.Duration) *util.PropagationStatus {
+		return &util.PropagationStatus{
+			RevisionFailure: &util.RevisionFailure{
+				Revision:  revision,
+				StartTime: metav1.NewTime(now.Add(-age)),
+			},
+		}
+	}
+
+	testCases := map[string]struct {
+		previousStatus    *util.PropagationStatus
+		failedClusters    []string
+		unhealthyClusters []string
+		halted            bool
+		expectedReason    bool
+		expectedPersisted bool
+		expectedStartTime metav1.Time
+	}{
+		"no failure": {
+			previousStatus: failingSince("current", time.Hour),
+		},
+		"failures below the threshold": {
+			previousStatus: failingSince("current", time.Hour),
+			failedClusters: []string{"cluster1"},
+		},
+		"new failure does not roll back": {
+			previousStatus:    &util.PropagationStatus{},
+			failedClusters:    []string{"cluster1", "cluster2"},
+			expectedReason:    true,
+			expectedStartTime: now,
+		},
+		"failure within the deadline does not roll back": {
+			previousStatus:    failingSince("current", time.Minute),
+			failedClusters:    []string{"cluster1", "cluster2"},
+			expectedReason:    true,
+			expectedStartTime: metav1.NewTime(now.Add(-time.Minute)),
+		},
+		"failure persisting for the deadline rolls back": {
+			previousStatus:    failingSince("current", deadline),
+			failedClusters:    []string{"cluster1", "cluster2"},
+			expectedReason:    true,
+			expectedPersisted: true,
+			expectedStartTime: metav1.NewTime(now.Add(-deadline)),
+		},
+		"failure of a previous revision does not count towards the deadline": {
+			previousStatus:    failingSince("previous", time.Hour),
+			failedClusters:    []string{"cluster1", "cluster2"},
+			expectedReason:    true,
+			expectedStartTime: now,
+		},
+		"unhealthy clusters count towards the threshold": {
+			previousStatus:    failingSince("current", time.Minute),
+			failedClusters:    []string{"cluster1"},
+			unhealthyClusters: []string{"cluster2"},
+			expectedReason:    true,
+			expectedStartTime: metav1.NewTime(now.Add(-time.Minute)),
+		},
+		"unhealthy clusters persisting for the deadline roll back": {
+			previousStatus:    failingSince("current", time.Hour),
+			unhealthyClusters: []string{"cluster1", "cluster2"},
+			expectedReason:    true,
+			expectedPersisted: true,
+			expectedStartTime: metav1.NewTime(now.Add(-time.Hour)),
+		},
+		"halted rollout rolls back immediately": {
+			previousStatus:    &util.PropagationStatus{},
+			halted:            true,
+			expectedReason:    true,
+			expectedPersisted: true,
+			expectedStartTime: now,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			result := newPropagationResult()
+			result.setCurrentRevision("current")
+			result.setSelectedClusters([]string{"cluster1", "cluster2", "cluster3"})
+			for _, clusterName := range testCase.failedClusters {
+				result.setClusterState(clusterName, util.ClusterPropagationUpdateFailed, "Failed")
+			}
+			unhealthyClusters := make(map[string]string)
+			for _, clusterName := range testCase.unhealthyClusters {
+				unhealthyClusters[clusterName] = "Unhealthy"
+			}
+			if testCase.halted {
+				result.setRollout(&util.RolloutStatus{Halted: true, Message: "Halted"})
+			}
+
+			reason, persisted := rollbackReason(testCase.previousStatus, result, unhealthyClusters, 50, deadline, now)
+			if testCase.expectedReason != (len(reason) > 0) {
+				t.Fatalf("Expected a reason to be %v, got %q", testCase.expectedReason, reason)
+			}
+			if testCase.expectedPersisted != persisted {
+				t.Fatalf("Expected persisted to be %v, got %v", testCase.expectedPersisted, persisted)
+			}
+			if !testCase.expectedReason {
+				if result.revisionFailure != nil {
+					t.Fatalf("Expected no revision failure, got %#v", result.revisionFailure)
+				}
+				return
+			}
+			if result.revisionFailure == nil || result.revisionFailure.Revision != "current" ||
+				!result.revisionFailure.StartTime.Equal(&testCase.expectedStartTime) {
+				t.Fatalf("Expected failure of revision %q since %v, got %#v", "current", testCase.expectedStartTime, result.revisionFailure)
+			}
+		})
+	}
+}
+
+func TestDriftCorrection(t *testing.T) {
+	testCases := map[string]struct {
+		driftPolicy     fedv1a1.DriftPolicy
+		correctDrift    string
+		correctedDrift  string
+		paused          bool
+		expectedCorrect bool
+		expectedRequest string
+	}{
+		"drift is corrected by the correct policy": {
+			driftPolicy:     fedv1a1.DriftPolicyCorrect,
+			expectedCorrect: true,
+		},
+		"drift is reported by the report policy": {
+			driftPolicy: fedv1a1.DriftPolicyReport,
+		},
+		"drift is corrected on request": {
+			driftPolicy:     fedv1a1.DriftPolicyReport,
+			correctDrift:    "1",
+			expectedCorrect: true,
+			expectedRequest: "1",
+		},
+		"a request is only corrected once": {
+			driftPolicy:    fedv1a1.DriftPolicyReport,
+			correctDrift:   "1",
+			correctedDrift: "1",
+		},
+		"drift is reported by the correct policy while paused": {
+			driftPolicy: fedv1a1.DriftPolicyCorrect,
+			paused:      true,
+		},
+		"a request is not corrected while paused": {
+			driftPolicy:  fedv1a1.DriftPolicyReport,
+			correctDrift: "1",
+			paused:       true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			if len(testCase.correctDrift) > 0 {
+				obj.SetAnnotations(map[string]string{util.CorrectDriftAnnotation: testCase.correctDrift})
+			}
+			fedResource := &fakeFederatedResource{obj: obj}
+			if testCase.paused {
+				fedResource.pauseReason = "Propagation is paused"
+			}
+			s := &FederationSyncController{driftPolicy: testCase.driftPolicy}
+			correct, request, err := s.driftCorrection(fedResource, &util.PropagationStatus{CorrectedDrift: testCase.correctedDrift})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if correct != testCase.expectedCorrect {
+				t.Fatalf("Expected drift correction to be %v, got %v", testCase.expectedCorrect, correct)
+			}
+			if request != testCase.expectedRequest {
+				t.Fatalf("Expected correction request %q, got %q", testCase.expectedRequest, request)
+			}
+		})
+	}
+}
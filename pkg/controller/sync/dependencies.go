@@ -0,0 +1,137 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+)
+
+// awaitDependencies removes from the given operations the add
+// operations for clusters in which a dependency of the federated
+// resource does not yet exist, and records those clusters as waiting
+// for their dependencies.  Returns the remaining operations and
+// whether any operation was removed.
+func (s *FederationSyncController) awaitDependencies(fedResource FederatedResource, operations []util.FederatedOperation,
+	result *propagationResult) ([]util.FederatedOperation, bool, error) {
+
+	dependencies, err := util.GetDependencies(fedResource.Object())
+	if err != nil || len(dependencies) == 0 {
+		return operations, false, err
+	}
+
+	addClusters := []string{}
+	for _, operation := range operations {
+		if operation.Type == util.OperationTypeAdd {
+			addClusters = append(addClusters, operation.ClusterName)
+		}
+	}
+	if len(addClusters) == 0 {
+		return operations, false, nil
+	}
+
+	apiResources, err := s.dependencyAPIResources(dependencies)
+	if err != nil {
+		return nil, false, err
+	}
+	namespace := fedResource.TargetName().Namespace
+	waitingClusters := make(map[string][]string)
+	for _, clusterName := range addClusters {
+		missing, err := s.missingDependencies(clusterName, namespace, dependencies, apiResources)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(missing) > 0 {
+			waitingClusters[clusterName] = missing
+		}
+	}
+	if len(waitingClusters) == 0 {
+		return operations, false, nil
+	}
+
+	remainingOperations := []util.FederatedOperation{}
+	for _, operation := range operations {
+		missing, waiting := waitingClusters[operation.ClusterName]
+		if !waiting || operation.Type != util.OperationTypeAdd {
+			remainingOperations = append(remainingOperations, operation)
+			continue
+		}
+		result.setClusterState(operation.ClusterName, util.ClusterPropagationWaitingForDependencies,
+			fmt.Sprintf("Waiting for %s", strings.Join(missing, ", ")))
+	}
+	return remainingOperations, true, nil
+}
+
+// dependencyAPIResources returns the target type of each of the given
+// dependencies.  The type of a dependency must be configured for
+// federation by a FederatedTypeConfig.
+func (s *FederationSyncController) dependencyAPIResources(dependencies []util.Dependency) (map[util.Dependency]metav1.APIResource, error) {
+	typeConfigs := s.typeConfigStore.List()
+	apiResources := make(map[util.Dependency]metav1.APIResource)
+	for _, dependency := range dependencies {
+		found := false
+		for _, obj := range typeConfigs {
+			target := obj.(*fedv1a1.FederatedTypeConfig).GetTarget()
+			if dependency.Matches(target) {
+				apiResources[dependency] = target
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("No FederatedTypeConfig was found for dependency %s", dependency)
+		}
+	}
+	return apiResources, nil
+}
+
+// missingDependencies returns the dependencies that do not exist in
+// the given cluster.
+func (s *FederationSyncController) missingDependencies(clusterName, namespace string, dependencies []util.Dependency,
+	apiResources map[util.Dependency]metav1.APIResource) ([]string, error) {
+
+	clusterClient, err := s.informer.GetClientForCluster(clusterName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error retrieving client for cluster %q", clusterName)
+	}
+	missing := []string{}
+	for _, dependency := range dependencies {
+		apiResource := apiResources[dependency]
+		client := clusterClient.ForResource(&apiResource)
+		dependencyNamespace := namespace
+		if !apiResource.Namespaced {
+			dependencyNamespace = ""
+		}
+		_, err = client.Resources(dependencyNamespace).Get(dependency.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			missing = append(missing, dependency.String())
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error retrieving dependency %s from cluster %q", dependency, clusterName)
+		}
+	}
+	return missing, nil
+}
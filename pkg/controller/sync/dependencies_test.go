@@ -0,0 +1,300 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+)
+
+// fakeInformer provides the clients of the member clusters.
+type fakeInformer struct {
+	util.FederatedInformer
+	clients map[string]util.ResourceClient
+}
+
+func (f *fakeInformer) GetClientForCluster(clusterName string) (util.ResourceClient, error) {
+	client, ok := f.clients[clusterName]
+	if !ok {
+		return nil, errors.Errorf("cluster %q not found", clusterName)
+	}
+	return client, nil
+}
+
+// fakeResourceClient serves the resources of a member cluster
+// identified by kind, namespace and name.
+type fakeResourceClient struct {
+	kind       string
+	namespaced bool
+	objects    sets.String
+}
+
+func (c *fakeResourceClient) Resources(namespace string) dynamic.ResourceInterface {
+	if !c.namespaced {
+		namespace = ""
+	}
+	return &fakeResourceInterface{client: c, namespace: namespace}
+}
+
+func (c *fakeResourceClient) Kind() string {
+	return c.kind
+}
+
+func (c *fakeResourceClient) ForResource(apiResource *metav1.APIResource) util.ResourceClient {
+	return &fakeResourceClient{
+		kind:       apiResource.Kind,
+		namespaced: apiResource.Namespaced,
+		objects:    c.objects,
+	}
+}
+
+type fakeResourceInterface struct {
+	dynamic.ResourceInterface
+	client    *fakeResourceClient
+	namespace string
+}
+
+func (r *fakeResourceInterface) Get(name string, options metav1.GetOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if !r.client.objects.Has(objectKey(r.client.kind, r.namespace, name)) {
+		return nil, apierrors.NewNotFound(schema.GroupResource{}, name)
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetKind(r.client.kind)
+	obj.SetNamespace(r.namespace)
+	obj.SetName(name)
+	return obj, nil
+}
+
+func objectKey(kind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
+
+// fakeFederatedResource provides the object of a federated resource.
+type fakeFederatedResource struct {
+	FederatedResource
+	obj         *unstructured.Unstructured
+	pauseReason string
+}
+
+func (r *fakeFederatedResource) Object() *unstructured.Unstructured {
+	return r.obj
+}
+
+func (r *fakeFederatedResource) TargetName() util.QualifiedName {
+	return util.NewQualifiedName(r.obj)
+}
+
+func (r *fakeFederatedResource) PauseReason() string {
+	return r.pauseReason
+}
+
+func newDependencyTestController(t *testing.T) *FederationSyncController {
+	typeConfigStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	typeConfigs := []*fedv1a1.FederatedTypeConfig{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "configmaps", Namespace: "federation-system"},
+			Spec: fedv1a1.FederatedTypeConfigSpec{
+				Target:     fedv1a1.APIResource{Version: "v1", Kind: "ConfigMap", PluralName: "configmaps"},
+				Namespaced: true,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "clusterroles.rbac.authorization.k8s.io", Namespace: "federation-system"},
+			Spec: fedv1a1.FederatedTypeConfigSpec{
+				Target: fedv1a1.APIResource{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole", PluralName: "clusterroles"},
+			},
+		},
+	}
+	for _, typeConfig := range typeConfigs {
+		if err := typeConfigStore.Add(typeConfig); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	return &FederationSyncController{
+		typeConfigStore: typeConfigStore,
+		informer: &fakeInformer{
+			clients: map[string]util.ResourceClient{
+				"cluster1": &fakeResourceClient{objects: sets.NewString(
+					objectKey("ConfigMap", "ns", "config"),
+					objectKey("ClusterRole", "", "role"),
+				)},
+				"cluster2": &fakeResourceClient{objects: sets.NewString(
+					objectKey("ConfigMap", "other", "config"),
+				)},
+			},
+		},
+	}
+}
+
+func newDependentResource(dependencies ...util.Dependency) FederatedResource {
+	dependsOn := []interface{}{}
+	for _, dependency := range dependencies {
+		dependsOn = append(dependsOn, map[string]interface{}{
+			"apiVersion": dependency.APIVersion,
+			"kind":       dependency.Kind,
+			"name":       dependency.Name,
+		})
+	}
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			util.SpecField: map[string]interface{}{
+				util.DependsOnField: dependsOn,
+			},
+		},
+	}
+	obj.SetNamespace("ns")
+	obj.SetName("foo")
+	return &fakeFederatedResource{obj: obj}
+}
+
+func TestMissingDependencies(t *testing.T) {
+	configMap := util.Dependency{Kind: "ConfigMap", Name: "config"}
+	clusterRole := util.Dependency{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "role"}
+
+	testCases := map[string]struct {
+		clusterName     string
+		expectedMissing []string
+		expectedErr     bool
+	}{
+		"no dependencies are missing": {
+			clusterName:     "cluster1",
+			expectedMissing: []string{},
+		},
+		"dependencies in other namespaces are missing": {
+			clusterName:     "cluster2",
+			expectedMissing: []string{`ConfigMap "config"`, `ClusterRole "role"`},
+		},
+		"cluster without a client": {
+			clusterName: "cluster3",
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			s := newDependencyTestController(t)
+			dependencies := []util.Dependency{configMap, clusterRole}
+			apiResources, err := s.dependencyAPIResources(dependencies)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			missing, err := s.missingDependencies(testCase.clusterName, "ns", dependencies, apiResources)
+			if testCase.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(testCase.expectedMissing, missing) {
+				t.Fatalf("Expected missing dependencies %v, got %v", testCase.expectedMissing, missing)
+			}
+		})
+	}
+}
+
+func TestAwaitDependencies(t *testing.T) {
+	configMap := util.Dependency{Kind: "ConfigMap", Name: "config"}
+	operations := []util.FederatedOperation{
+		{Type: util.OperationTypeAdd, ClusterName: "cluster1"},
+		{Type: util.OperationTypeAdd, ClusterName: "cluster2"},
+		{Type: util.OperationTypeDelete, ClusterName: "cluster3"},
+	}
+
+	testCases := map[string]struct {
+		fedResource        FederatedResource
+		operations         []util.FederatedOperation
+		expectedOperations []util.FederatedOperation
+		expectedWaiting    bool
+		expectedStates     map[string]clusterState
+		expectedErr        bool
+	}{
+		"operations of a resource without dependencies are unchanged": {
+			fedResource:        newDependentResource(),
+			operations:         operations,
+			expectedOperations: operations,
+		},
+		"operations other than add are not checked": {
+			fedResource:        newDependentResource(util.Dependency{Kind: "Unknown", Name: "unknown"}),
+			operations:         operations[2:],
+			expectedOperations: operations[2:],
+		},
+		"add is withheld from a cluster missing a dependency": {
+			fedResource:        newDependentResource(configMap),
+			operations:         operations,
+			expectedOperations: []util.FederatedOperation{operations[0], operations[2]},
+			expectedWaiting:    true,
+			expectedStates: map[string]clusterState{
+				"cluster2": {
+					state:  util.ClusterPropagationWaitingForDependencies,
+					reason: `Waiting for ConfigMap "config"`,
+				},
+			},
+		},
+		"dependency without a type config": {
+			fedResource: newDependentResource(util.Dependency{Kind: "Unknown", Name: "unknown"}),
+			operations:  operations,
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			s := newDependencyTestController(t)
+			result := newPropagationResult()
+			remaining, waiting, err := s.awaitDependencies(testCase.fedResource, testCase.operations, result)
+			if testCase.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(testCase.expectedOperations, remaining) {
+				t.Fatalf("Expected operations %v, got %v", testCase.expectedOperations, remaining)
+			}
+			if testCase.expectedWaiting != waiting {
+				t.Fatalf("Expected waiting to be %v, got %v", testCase.expectedWaiting, waiting)
+			}
+			expectedStates := testCase.expectedStates
+			if expectedStates == nil {
+				expectedStates = map[string]clusterState{}
+			}
+			if !reflect.DeepEqual(expectedStates, result.clusterStates) {
+				t.Fatalf("Expected cluster states %v, got %v", expectedStates, result.clusterStates)
+			}
+		})
+	}
+}
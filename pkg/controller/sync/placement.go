@@ -17,6 +17,11 @@ limitations under the License.
 package sync
 
 import (
+	"hash/fnv"
+	"sort"
+
+	"github.com/pkg/errors"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -40,11 +45,6 @@ import (
 // clusters, so namespace placement becomes a mechanism for limiting
 // rather than allowing propagation.
 func computeNamespacedPlacement(resource, namespace *unstructured.Unstructured, clusters []*fedv1a1.FederatedCluster, limitedScope bool) (selectedClusters, unselectedClusters []string, err error) {
-	selectedClusters, unselectedClusters, err = computePlacement(resource, clusters)
-	if err != nil {
-		return nil, nil, err
-	}
-
 	clusterNames := getClusterNames(clusters)
 
 	if namespace == nil {
@@ -52,7 +52,7 @@ func computeNamespacedPlacement(resource, namespace *unstructured.Unstructured,
 			// Use the resource placement verbatim if no federated
 			// namespace is present and federation is targeting a
 			// single namespace.
-			return selectedClusters, unselectedClusters, nil
+			return computePlacement(resource, clusters)
 		}
 		// Resource should not exist in any member clusters.
 		return []string{}, clusterNames, nil
@@ -63,13 +63,25 @@ func computeNamespacedPlacement(resource, namespace *unstructured.Unstructured,
 		return nil, nil, err
 	}
 
-	resourceClusterSet := sets.NewString(selectedClusters...)
+	// If both namespace and resource placement exist, the desired
+	// list of clusters is their intersection.  Resource placement is
+	// computed from the clusters selected by namespace placement so
+	// that any constraints on the number or spread of clusters are
+	// satisfied by the intersection.
 	namespaceClusterSet := sets.NewString(namespaceSelectedClusters...)
-	clusterSet := sets.NewString(clusterNames...)
+	namespaceClusters := []*fedv1a1.FederatedCluster{}
+	for _, cluster := range clusters {
+		if namespaceClusterSet.Has(cluster.Name) {
+			namespaceClusters = append(namespaceClusters, cluster)
+		}
+	}
+	selectedClusters, _, err = computePlacement(resource, namespaceClusters)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// If both namespace and resource placement exist, the desired
-	// list of clusters is their intersection.
-	selectedSet := resourceClusterSet.Intersection(namespaceClusterSet)
+	clusterSet := sets.NewString(clusterNames...)
+	selectedSet := sets.NewString(selectedClusters...)
 
 	return selectedSet.List(), clusterSet.Difference(selectedSet).List(), nil
 }
@@ -93,19 +105,192 @@ func selectedClusterNames(resource *unstructured.Unstructured, clusters []*fedv1
 		return nil, err
 	}
 
-	if directive.ClusterNames != nil {
+	if directive.ClusterNames != nil && !directive.Constrained() {
 		return directive.ClusterNames, nil
 	}
 
+	matchingClusters := []*fedv1a1.FederatedCluster{}
+	if directive.ClusterNames != nil {
+		clusterNameSet := sets.NewString(directive.ClusterNames...)
+		for _, cluster := range clusters {
+			if clusterNameSet.Has(cluster.Name) {
+				matchingClusters = append(matchingClusters, cluster)
+			}
+		}
+	} else {
+		for _, cluster := range clusters {
+			if directive.ClusterSelector.Matches(labels.Set(cluster.Labels)) {
+				matchingClusters = append(matchingClusters, cluster)
+			}
+		}
+	}
+
+	if !directive.Constrained() {
+		return getClusterNames(matchingClusters), nil
+	}
+	currentClusters, err := currentClusterNames(resource)
+	if err != nil {
+		return nil, err
+	}
+	key := util.NewQualifiedName(resource).String()
+	return constrainedClusterNames(key, directive, matchingClusters, currentClusters)
+}
+
+// currentClusterNames returns the names of the clusters that were
+// selected by the most recent reconciliation of the resource
+// according to its propagation status.  A cluster that is not ready
+// is not considered selected, since it will have been replaced by
+// another cluster while it was unready.
+func currentClusterNames(resource *unstructured.Unstructured) (sets.String, error) {
+	status, err := util.GetPropagationStatus(resource)
+	if err != nil {
+		return nil, err
+	}
+	clusterNames := sets.NewString()
+	for _, clusterStatus := range status.Clusters {
+		switch clusterStatus.State {
+		case util.ClusterPropagationDeleted, util.ClusterPropagationOrphaned,
+			util.ClusterPropagationRetained, util.ClusterPropagationDenied,
+			util.ClusterPropagationClusterNotReady:
+			continue
+		}
+		clusterNames.Insert(clusterStatus.Cluster)
+	}
+	return clusterNames, nil
+}
+
+// constrainedClusterNames selects a subset of the given clusters that
+// satisfies the spread constraints and cluster limit of the
+// directive.
+//
+// Clusters that are currently selected are ranked first so that a
+// resource is only moved from a cluster that is no longer eligible
+// (e.g. because it no longer matches the placement or is needed to
+// satisfy a spread constraint) rather than whenever a more preferred
+// cluster becomes available.  Clusters are otherwise ranked by the
+// total weight of the preferences they match.  Ties are broken by a hash of the cluster name and the key
+// of the resource so that the selection is stable across reconciles
+// and resources with the same placement are distributed across the
+// matching clusters rather than concentrated on the clusters with
+// the lowest names.  Clusters are first selected in order of rank to
+// cover the minimum number of domains of each spread constraint, and
+// remaining capacity is filled in order of rank.
+func constrainedClusterNames(key string, directive *util.PlacementDirective, clusters []*fedv1a1.FederatedCluster, currentClusters sets.String) ([]string, error) {
+	maxClusters := len(clusters)
+	if directive.MaxClusters != nil && int(*directive.MaxClusters) < maxClusters {
+		maxClusters = int(*directive.MaxClusters)
+	}
+	for _, constraint := range directive.SpreadConstraints {
+		if directive.MaxClusters != nil && *directive.MaxClusters < constraint.MinDomains {
+			return nil, errors.Errorf("Placement requires at least %d %s domains but is limited to %d clusters",
+				constraint.MinDomains, constraint.TopologyKey, *directive.MaxClusters)
+		}
+	}
+
+	ranked := rankClusters(key, directive.ClusterPreferences, clusters, currentClusters)
+
 	selectedNames := []string{}
-	for _, cluster := range clusters {
-		if directive.ClusterSelector.Matches(labels.Set(cluster.Labels)) {
-			selectedNames = append(selectedNames, cluster.Name)
+	selectedSet := sets.NewString()
+	selectCluster := func(cluster *fedv1a1.FederatedCluster) {
+		selectedNames = append(selectedNames, cluster.Name)
+		selectedSet.Insert(cluster.Name)
+	}
+
+	for _, constraint := range directive.SpreadConstraints {
+		domains := sets.NewString()
+		for _, cluster := range ranked {
+			if selectedSet.Has(cluster.Name) {
+				if domain := clusterDomain(cluster, constraint.TopologyKey); len(domain) > 0 {
+					domains.Insert(domain)
+				}
+			}
+		}
+		// A constraint that cannot be satisfied by the available
+		// clusters is satisfied as far as possible.
+		for _, cluster := range ranked {
+			if len(selectedNames) >= maxClusters || int32(domains.Len()) >= constraint.MinDomains {
+				break
+			}
+			domain := clusterDomain(cluster, constraint.TopologyKey)
+			if len(domain) == 0 || domains.Has(domain) || selectedSet.Has(cluster.Name) {
+				continue
+			}
+			domains.Insert(domain)
+			selectCluster(cluster)
+		}
+	}
+
+	for _, cluster := range ranked {
+		if len(selectedNames) >= maxClusters {
+			break
+		}
+		if !selectedSet.Has(cluster.Name) {
+			selectCluster(cluster)
 		}
 	}
+
 	return selectedNames, nil
 }
 
+type rankedCluster struct {
+	cluster *fedv1a1.FederatedCluster
+	current bool
+	weight  int64
+	hash    uint32
+}
+
+// rankClusters returns the given clusters ordered from most to least
+// preferred.
+func rankClusters(key string, preferences []util.PlacementPreference, clusters []*fedv1a1.FederatedCluster, currentClusters sets.String) []*fedv1a1.FederatedCluster {
+	rankedClusters := []rankedCluster{}
+	for _, cluster := range clusters {
+		var weight int64
+		for _, preference := range preferences {
+			if preference.ClusterSelector.Matches(labels.Set(cluster.Labels)) {
+				weight += int64(preference.Weight)
+			}
+		}
+		hasher := fnv.New32()
+		hasher.Write([]byte(cluster.Name))
+		hasher.Write([]byte(key))
+		rankedClusters = append(rankedClusters, rankedCluster{
+			cluster: cluster,
+			current: currentClusters.Has(cluster.Name),
+			weight:  weight,
+			hash:    hasher.Sum32(),
+		})
+	}
+	sort.Slice(rankedClusters, func(i, j int) bool {
+		a, b := rankedClusters[i], rankedClusters[j]
+		if a.current != b.current {
+			return a.current
+		}
+		if a.weight != b.weight {
+			return a.weight > b.weight
+		}
+		if a.hash != b.hash {
+			return a.hash < b.hash
+		}
+		return a.cluster.Name < b.cluster.Name
+	})
+
+	ranked := []*fedv1a1.FederatedCluster{}
+	for _, rankedCluster := range rankedClusters {
+		ranked = append(ranked, rankedCluster.cluster)
+	}
+	return ranked
+}
+
+func clusterDomain(cluster *fedv1a1.FederatedCluster, topologyKey string) string {
+	switch topologyKey {
+	case util.TopologyKeyRegion:
+		return cluster.Status.Region
+	case util.TopologyKeyZone:
+		return cluster.Status.Zone
+	}
+	return ""
+}
+
 func getClusterNames(clusters []*fedv1a1.FederatedCluster) []string {
 	clusterNames := []string{}
 	for _, cluster := range clusters {
@@ -18,10 +18,13 @@ package sync
 
 import (
 	"reflect"
+	"sort"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
 	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
@@ -96,3 +99,246 @@ func TestSelectedClusterNames(t *testing.T) {
 		})
 	}
 }
+
+func TestConstrainedClusterNames(t *testing.T) {
+	newCluster := func(name, region, zone string, labels map[string]string) *fedv1a1.FederatedCluster {
+		return &fedv1a1.FederatedCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: labels,
+			},
+			Status: fedv1a1.FederatedClusterStatus{
+				Region: region,
+				Zone:   zone,
+			},
+		}
+	}
+	clusters := []*fedv1a1.FederatedCluster{
+		newCluster("cluster1", "us-east1", "us-east1-a", nil),
+		newCluster("cluster2", "us-east1", "us-east1-b", nil),
+		newCluster("cluster3", "us-west1", "us-west1-a", map[string]string{"tier": "gold"}),
+		newCluster("cluster4", "eu-west1", "eu-west1-a", map[string]string{"tier": "gold"}),
+	}
+	int32Ptr := func(i int32) *int32 { return &i }
+
+	testCases := map[string]struct {
+		directive     util.PlacementDirective
+		expectedCount int
+		expectedNames []string
+		regions       int
+		expectedErr   bool
+	}{
+		"max clusters limits selection": {
+			directive:     util.PlacementDirective{MaxClusters: int32Ptr(2)},
+			expectedCount: 2,
+		},
+		"max clusters greater than matching clusters": {
+			directive:     util.PlacementDirective{MaxClusters: int32Ptr(10)},
+			expectedCount: 4,
+		},
+		"preferred clusters are selected first": {
+			directive: util.PlacementDirective{
+				MaxClusters: int32Ptr(2),
+				ClusterPreferences: []util.PlacementPreference{
+					{Weight: 10, ClusterSelector: labels.SelectorFromSet(labels.Set{"tier": "gold"})},
+				},
+			},
+			expectedNames: []string{"cluster3", "cluster4"},
+		},
+		"spread across regions": {
+			directive: util.PlacementDirective{
+				MaxClusters: int32Ptr(3),
+				SpreadConstraints: []util.SpreadConstraint{
+					{TopologyKey: util.TopologyKeyRegion, MinDomains: 3},
+				},
+			},
+			expectedCount: 3,
+			regions:       3,
+		},
+		"spread takes precedence over preferences": {
+			directive: util.PlacementDirective{
+				MaxClusters: int32Ptr(2),
+				SpreadConstraints: []util.SpreadConstraint{
+					{TopologyKey: util.TopologyKeyRegion, MinDomains: 2},
+				},
+				ClusterPreferences: []util.PlacementPreference{
+					{Weight: 10, ClusterSelector: labels.SelectorFromSet(labels.Set{"tier": "gold"})},
+				},
+			},
+			expectedNames: []string{"cluster3", "cluster4"},
+			regions:       2,
+		},
+		"unsatisfiable spread is satisfied as far as possible": {
+			directive: util.PlacementDirective{
+				SpreadConstraints: []util.SpreadConstraint{
+					{TopologyKey: util.TopologyKeyRegion, MinDomains: 5},
+				},
+			},
+			expectedCount: 4,
+			regions:       3,
+		},
+		"max clusters less than min domains": {
+			directive: util.PlacementDirective{
+				MaxClusters: int32Ptr(1),
+				SpreadConstraints: []util.SpreadConstraint{
+					{TopologyKey: util.TopologyKeyZone, MinDomains: 2},
+				},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			selectedNames, err := constrainedClusterNames("ns/foo", &testCase.directive, clusters, sets.NewString())
+			if testCase.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if testCase.expectedNames != nil {
+				sort.Strings(selectedNames)
+				if !reflect.DeepEqual(selectedNames, testCase.expectedNames) {
+					t.Fatalf("Expected names %v, got %v", testCase.expectedNames, selectedNames)
+				}
+			} else if len(selectedNames) != testCase.expectedCount {
+				t.Fatalf("Expected %d clusters, got %v", testCase.expectedCount, selectedNames)
+			}
+			if testCase.regions > 0 {
+				regions := sets.NewString()
+				for _, cluster := range clusters {
+					if sets.NewString(selectedNames...).Has(cluster.Name) {
+						regions.Insert(cluster.Status.Region)
+					}
+				}
+				if regions.Len() != testCase.regions {
+					t.Fatalf("Expected %d regions, got %v", testCase.regions, regions.List())
+				}
+			}
+
+			// The selection should be stable regardless of the order
+			// of the clusters.
+			reversed := []*fedv1a1.FederatedCluster{}
+			for i := len(clusters) - 1; i >= 0; i-- {
+				reversed = append(reversed, clusters[i])
+			}
+			reversedNames, err := constrainedClusterNames("ns/foo", &testCase.directive, reversed, sets.NewString())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !sets.NewString(selectedNames...).Equal(sets.NewString(reversedNames...)) {
+				t.Fatalf("Expected a stable selection of %v, got %v", selectedNames, reversedNames)
+			}
+		})
+	}
+}
+
+func TestConstrainedClusterNamesRetainsCurrentClusters(t *testing.T) {
+	newCluster := func(name, region string, labels map[string]string) *fedv1a1.FederatedCluster {
+		return &fedv1a1.FederatedCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: labels,
+			},
+			Status: fedv1a1.FederatedClusterStatus{
+				Region: region,
+			},
+		}
+	}
+	clusters := []*fedv1a1.FederatedCluster{
+		newCluster("cluster1", "us-east1", nil),
+		newCluster("cluster2", "us-west1", nil),
+		newCluster("cluster3", "eu-west1", nil),
+	}
+	gold := map[string]string{"tier": "gold"}
+	int32Ptr := func(i int32) *int32 { return &i }
+	directive := &util.PlacementDirective{
+		MaxClusters: int32Ptr(2),
+		SpreadConstraints: []util.SpreadConstraint{
+			{TopologyKey: util.TopologyKeyRegion, MinDomains: 2},
+		},
+		ClusterPreferences: []util.PlacementPreference{
+			{Weight: 10, ClusterSelector: labels.SelectorFromSet(labels.Set(gold))},
+		},
+	}
+
+	initialNames, err := constrainedClusterNames("ns/foo", directive, clusters, sets.NewString())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	currentClusters := sets.NewString(initialNames...)
+
+	testCases := map[string]struct {
+		clusters         []*fedv1a1.FederatedCluster
+		expectedRetained bool
+		expectedName     string
+	}{
+		"selection is retained when a cluster joins": {
+			clusters:         append(clusters, newCluster("cluster4", "asia-east1", nil)),
+			expectedRetained: true,
+		},
+		"selection is retained when a preferred cluster joins": {
+			clusters:         append(clusters, newCluster("cluster4", "asia-east1", gold)),
+			expectedRetained: true,
+		},
+		"cluster that is no longer eligible is replaced": {
+			clusters: func() []*fedv1a1.FederatedCluster {
+				remaining := []*fedv1a1.FederatedCluster{}
+				for _, cluster := range clusters {
+					if cluster.Name != initialNames[0] {
+						remaining = append(remaining, cluster)
+					}
+				}
+				return remaining
+			}(),
+			expectedName: initialNames[1],
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			selectedNames, err := constrainedClusterNames("ns/foo", directive, testCase.clusters, currentClusters)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			selectedSet := sets.NewString(selectedNames...)
+			if len(selectedNames) != 2 {
+				t.Fatalf("Expected 2 clusters, got %v", selectedNames)
+			}
+			if testCase.expectedRetained && !selectedSet.Equal(currentClusters) {
+				t.Fatalf("Expected the selection of %v to be retained, got %v", currentClusters.List(), selectedNames)
+			}
+			if len(testCase.expectedName) > 0 && !selectedSet.Has(testCase.expectedName) {
+				t.Fatalf("Expected %q to remain selected, got %v", testCase.expectedName, selectedNames)
+			}
+		})
+	}
+}
+
+func TestCurrentClusterNames(t *testing.T) {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			util.StatusField: map[string]interface{}{
+				"clusters": []interface{}{
+					map[string]interface{}{"cluster": "cluster1", "state": string(util.ClusterPropagationPlaced)},
+					map[string]interface{}{"cluster": "cluster2", "state": string(util.ClusterPropagationUpdateFailed)},
+					map[string]interface{}{"cluster": "cluster3", "state": string(util.ClusterPropagationDeleted)},
+					map[string]interface{}{"cluster": "cluster4", "state": string(util.ClusterPropagationDenied)},
+					map[string]interface{}{"cluster": "cluster5", "state": string(util.ClusterPropagationClusterNotReady)},
+				},
+			},
+		},
+	}
+	clusterNames, err := currentClusterNames(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedNames := []string{"cluster1", "cluster2"}
+	if !reflect.DeepEqual(expectedNames, clusterNames.List()) {
+		t.Fatalf("Expected names %v, got %v", expectedNames, clusterNames.List())
+	}
+}
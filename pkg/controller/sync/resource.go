@@ -20,10 +20,13 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
 
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	pkgruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -42,15 +45,22 @@ type FederatedResource interface {
 	FederatedName() util.QualifiedName
 	TargetName() util.QualifiedName
 	Object() *unstructured.Unstructured
+	TemplateVersion() (string, error)
+	OverrideVersion() (string, error)
+	ObservedGeneration() int64
+	RenderedVersion(clusterName string) (string, error)
 	GetVersions() (map[string]string, error)
 	UpdateVersions(selectedClusters []string, versionMap map[string]string) error
 	DeleteVersions()
-	ComputePlacement(clusters []*fedv1a1.FederatedCluster) (selectedClusters, unselectedClusters []string, err error)
+	ComputePlacement(clusters []*fedv1a1.FederatedCluster) (selectedClusters, unselectedClusters []string, deniedClusters map[string]string, err error)
 	SkipClusterChange(clusterObj pkgruntime.Object) bool
 	ObjectForCluster(clusterName string) (*unstructured.Unstructured, error)
+	PauseReason() string
 	MarkedForDeletion() bool
 	EnsureDeletion() error
 	EnsureFinalizers() error
+	UpdatePropagationStatus(status *util.PropagationStatus) error
+	RestoreRevision(revision *appsv1.ControllerRevision) error
 }
 
 type federatedResource struct {
@@ -61,11 +71,25 @@ type federatedResource struct {
 	federatedKind     string
 	federatedName     util.QualifiedName
 	federatedResource *unstructured.Unstructured
+	federatedClient   util.ResourceClient
 	versionManager    *version.VersionManager
 	deletionHelper    *deletionhelper.DeletionHelper
 	overridesMap      util.OverridesMap
+	selectorOverrides []*util.SelectorOverrides
 	namespace         *unstructured.Unstructured
 	fedNamespace      *unstructured.Unstructured
+
+	// The PlacementPolicies that apply to the resource, sorted by
+	// name.
+	placementPolicies []*fedv1a1.PlacementPolicy
+
+	// The clusters provided to ComputePlacement are retained to
+	// resolve the overrides that target a cluster selector.
+	clusters []*fedv1a1.FederatedCluster
+
+	// Whether the template and overrides were changed by
+	// RestoreRevision and have yet to be persisted.
+	revisionRestored bool
 }
 
 func (r *federatedResource) FederatedName() util.QualifiedName {
@@ -91,7 +115,34 @@ func (r *federatedResource) TemplateVersion() (string, error) {
 func (r *federatedResource) OverrideVersion() (string, error) {
 	// TODO(marun) Consider hashing overrides per cluster to minimize
 	// unnecessary updates.
-	return GetOverrideHash(r.federatedResource)
+	return GetOverrideHash(r.federatedResource, r.clusters)
+}
+
+// RenderedVersion returns a hash of the object rendered for the given
+// cluster if the resource renders cluster variables so that a change
+// to the variables of the cluster that changes its rendered object
+// will also change the hash.  An empty string is returned for a
+// resource that does not render cluster variables.
+func (r *federatedResource) RenderedVersion(clusterName string) (string, error) {
+	if !util.IsClusterTemplatingEnabled(r.federatedResource) {
+		return "", nil
+	}
+	obj, err := r.ObjectForCluster(clusterName)
+	if err != nil {
+		return "", err
+	}
+	return hashUnstructured(obj, "rendered object")
+}
+
+// ObservedGeneration returns the generation of the resource if its
+// status is written to the status subresource.  Otherwise a status
+// update increments the generation, so the generation reconciled by
+// the sync controller is never current and 0 is returned.
+func (r *federatedResource) ObservedGeneration() int64 {
+	if r.typeConfig.GetStatusMode() != fedv1a1.StatusModeSubresource {
+		return 0
+	}
+	return r.federatedResource.GetGeneration()
 }
 
 func (r *federatedResource) GetVersions() (map[string]string, error) {
@@ -106,11 +157,60 @@ func (r *federatedResource) DeleteVersions() {
 	r.versionManager.Delete(r.federatedName)
 }
 
-func (r *federatedResource) ComputePlacement(clusters []*fedv1a1.FederatedCluster) ([]string, []string, error) {
+// ComputePlacement determines the selected and unselected clusters
+// for the resource.  Placement policies may provide the placement of
+// a resource that does not specify one, and clusters denied by a
+// policy are never selected.  The clusters that would otherwise have
+// been selected are returned with the reason they were denied.
+func (r *federatedResource) ComputePlacement(clusters []*fedv1a1.FederatedCluster) ([]string, []string, map[string]string, error) {
+	r.clusters = clusters
+	if len(r.placementPolicies) == 0 {
+		selectedClusters, unselectedClusters, err := r.computePlacement(r.federatedResource, clusters)
+		return selectedClusters, unselectedClusters, nil, err
+	}
+
+	resource, err := util.ApplyDefaultPlacement(r.federatedResource, r.placementPolicies)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	deniedClusters, err := util.DeniedClusters(r.placementPolicies, clusters)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	allowedClusters := []*fedv1a1.FederatedCluster{}
+	for _, cluster := range clusters {
+		if _, denied := deniedClusters[cluster.Name]; !denied {
+			allowedClusters = append(allowedClusters, cluster)
+		}
+	}
+	selectedClusters, _, err := r.computePlacement(resource, allowedClusters)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Only denied clusters that the placement of the resource would
+	// have selected are reported.
+	requestedClusters, _, err := r.computePlacement(resource, clusters)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	requestedDenials := make(map[string]string)
+	for _, clusterName := range requestedClusters {
+		if reason, denied := deniedClusters[clusterName]; denied {
+			requestedDenials[clusterName] = reason
+		}
+	}
+
+	clusterSet := sets.NewString(getClusterNames(clusters)...)
+	selectedSet := sets.NewString(selectedClusters...)
+	return selectedSet.List(), clusterSet.Difference(selectedSet).List(), requestedDenials, nil
+}
+
+func (r *federatedResource) computePlacement(resource *unstructured.Unstructured, clusters []*fedv1a1.FederatedCluster) ([]string, []string, error) {
 	if r.typeConfig.GetNamespaced() {
-		return computeNamespacedPlacement(r.federatedResource, r.fedNamespace, clusters, r.limitedScope)
+		return computeNamespacedPlacement(resource, r.fedNamespace, clusters, r.limitedScope)
 	}
-	return computePlacement(r.federatedResource, clusters)
+	return computePlacement(resource, clusters)
 }
 
 func (r *federatedResource) SkipClusterChange(clusterObj pkgruntime.Object) bool {
@@ -172,24 +272,52 @@ func (r *federatedResource) ObjectForCluster(clusterName string) (*unstructured.
 	if err != nil {
 		return nil, err
 	}
-	if overrides != nil {
-		for path, value := range overrides {
-			pathEntries := strings.Split(path, ".")
-			unstructured.SetNestedField(obj.Object, value, pathEntries...)
+	for _, clusterOverrides := range overrides {
+		err = util.ApplyOverrides(obj, clusterOverrides)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error applying overrides for cluster %q to %s %q", clusterName, r.federatedKind, r.federatedName)
 		}
 	}
 
+	if util.IsClusterTemplatingEnabled(r.federatedResource) {
+		err = util.RenderClusterTemplate(obj, r.clusterOrDefault(clusterName), util.ClusterTemplatingFields(r.federatedResource))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error rendering cluster variables for cluster %q in %s %q", clusterName, r.federatedKind, r.federatedName)
+		}
+	}
+
+	util.AddManagedByFederationLabel(obj)
+
 	return obj, nil
 }
 
+// PauseReason returns the reason propagation of the resource is
+// paused, or an empty string if propagation is not paused.
+// Propagation of a namespaced resource is also paused by its
+// FederatedNamespace.
+func (r *federatedResource) PauseReason() string {
+	switch {
+	case util.IsPaused(r.federatedResource):
+		return "Propagation is paused"
+	case util.IsPaused(r.fedNamespace):
+		return "Propagation is paused for the namespace"
+	}
+	return ""
+}
+
 func (r *federatedResource) MarkedForDeletion() bool {
 	return r.federatedResource.GetDeletionTimestamp() != nil
 }
 
 func (r *federatedResource) EnsureDeletion() error {
 	r.DeleteVersions()
-	_, err := r.deletionHelper.HandleObjectInUnderlyingClusters(
+	removalPolicy, err := util.GetRemovalPolicy(r.federatedResource)
+	if err != nil {
+		return err
+	}
+	_, err = r.deletionHelper.HandleObjectInUnderlyingClusters(
 		r.federatedResource,
+		removalPolicy,
 		func(clusterObj pkgruntime.Object) bool {
 			// Skip deletion of a namespace in the host cluster as it will be
 			// removed by the garbage collector once its contents are removed.
@@ -208,15 +336,99 @@ func (r *federatedResource) EnsureFinalizers() error {
 	return err
 }
 
-func (r *federatedResource) overridesForCluster(clusterName string) (util.ClusterOverridesMap, error) {
+func (r *federatedResource) UpdatePropagationStatus(status *util.PropagationStatus) error {
+	if r.typeConfig.GetStatusMode() == fedv1a1.StatusModeSubresource {
+		return r.updateStatusSubresource(status)
+	}
+	obj := r.federatedResource.DeepCopy()
+	err := util.SetPropagationStatus(obj, status)
+	if err != nil {
+		return err
+	}
+	updatedObj, err := r.federatedClient.Resources(obj.GetNamespace()).Update(obj, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	// Retain the updated resource for use in future API calls.
+	r.federatedResource = updatedObj
+	r.revisionRestored = false
+	return nil
+}
+
+// updateStatusSubresource writes the propagation status to the status
+// subresource of the resource.  A status update does not change the
+// generation of the resource, but a restored revision must first be
+// persisted by a regular update that ignores the status.
+func (r *federatedResource) updateStatusSubresource(status *util.PropagationStatus) error {
+	client := r.federatedClient.Resources(r.federatedResource.GetNamespace())
+	if r.revisionRestored {
+		updatedObj, err := client.Update(r.federatedResource, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		r.federatedResource = updatedObj
+		r.revisionRestored = false
+	}
+	obj := r.federatedResource.DeepCopy()
+	err := util.SetPropagationStatus(obj, status)
+	if err != nil {
+		return err
+	}
+	updatedObj, err := client.UpdateStatus(obj, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	// Retain the updated resource for use in future API calls.
+	r.federatedResource = updatedObj
+	return nil
+}
+
+// RestoreRevision sets the template and overrides of the resource to
+// those of the given revision.  The change is persisted by the next
+// call to UpdatePropagationStatus.
+func (r *federatedResource) RestoreRevision(revision *appsv1.ControllerRevision) error {
+	obj := r.federatedResource.DeepCopy()
+	err := util.ApplyRevision(obj, revision)
+	if err != nil {
+		return err
+	}
+	r.federatedResource = obj
+	r.revisionRestored = true
+	return nil
+}
+
+// overridesForCluster returns the overrides to apply for the named
+// cluster in the order they should be applied.
+func (r *federatedResource) overridesForCluster(clusterName string) ([]*util.ClusterOverrides, error) {
 	if r.overridesMap == nil {
 		overridesMap, err := util.GetOverrides(r.federatedResource)
 		if err != nil {
-			return nil, errors.Errorf("Error reading cluster overrides for %s %q", r.federatedKind, r.federatedName)
+			return nil, errors.Wrapf(err, "Error reading cluster overrides for %s %q", r.federatedKind, r.federatedName)
+		}
+		selectorOverrides, err := util.GetSelectorOverrides(r.federatedResource)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error reading cluster overrides for %s %q", r.federatedKind, r.federatedName)
 		}
 		r.overridesMap = overridesMap
+		r.selectorOverrides = selectorOverrides
+	}
+	// Selector overrides cannot be resolved for a cluster that was
+	// not considered for placement.
+	cluster := r.clusterOrDefault(clusterName)
+	return util.OverridesForCluster(r.overridesMap, r.selectorOverrides, cluster), nil
+}
+
+// clusterOrDefault returns the named cluster if it was provided to
+// ComputePlacement, and otherwise a cluster with only a name.
+func (r *federatedResource) clusterOrDefault(clusterName string) *fedv1a1.FederatedCluster {
+	for _, cluster := range r.clusters {
+		if cluster.Name == clusterName {
+			return cluster
+		}
 	}
-	return r.overridesMap[clusterName], nil
+	cluster := &fedv1a1.FederatedCluster{}
+	cluster.Name = clusterName
+	return cluster
 }
 
 func namespaceFromTemplate(fieldMap map[string]interface{}) (*unstructured.Unstructured, error) {
@@ -268,7 +480,12 @@ func GetTemplateHash(fieldMap map[string]interface{}, namespaceIsTarget bool) (s
 	return hashUnstructured(obj, description)
 }
 
-func GetOverrideHash(rawObj *unstructured.Unstructured) (string, error) {
+// GetOverrideHash returns a hash of the overrides of the given
+// federated resource.  If overrides target a cluster selector, the
+// names of the given clusters matching each selector are included in
+// the hash so that a change in cluster labels that changes the
+// effective overrides will also change the hash.
+func GetOverrideHash(rawObj *unstructured.Unstructured, clusters []*fedv1a1.FederatedCluster) (string, error) {
 	override := util.GenericOverride{}
 	err := util.UnstructuredToInterface(rawObj, &override)
 	if err != nil {
@@ -281,6 +498,25 @@ func GetOverrideHash(rawObj *unstructured.Unstructured) (string, error) {
 		},
 	}
 
+	selectorOverrides, err := util.GetSelectorOverrides(rawObj)
+	if err != nil {
+		return "", errors.Wrap(err, "Error retrieving overrides")
+	}
+	if len(selectorOverrides) > 0 {
+		selectorMatches := []interface{}{}
+		for _, overrides := range selectorOverrides {
+			clusterNames := []string{}
+			for _, cluster := range clusters {
+				if overrides.Matches(cluster) {
+					clusterNames = append(clusterNames, cluster.Name)
+				}
+			}
+			sort.Strings(clusterNames)
+			selectorMatches = append(selectorMatches, clusterNames)
+		}
+		obj.Object["selectorMatches"] = selectorMatches
+	}
+
 	return hashUnstructured(obj, "overrides")
 }
 
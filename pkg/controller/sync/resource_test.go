@@ -17,9 +17,12 @@ limitations under the License.
 package sync
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
 	kfenable "github.com/kubernetes-sigs/federation-v2/pkg/kubefed2/enable"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
@@ -46,3 +49,261 @@ spec:
 		t.Fatalf("Expected %s, got %s", expectedHash, hash)
 	}
 }
+
+func TestGetOverrideHash(t *testing.T) {
+	fedObject := &unstructured.Unstructured{}
+	yaml := `
+kind: foo
+spec:
+  overrides:
+  - clusterSelector:
+      matchLabels:
+        foo: bar
+    clusterOverrides:
+    - path: spec.replicas
+      value: 2
+`
+	err := kfenable.DecodeYAML(strings.NewReader(yaml), fedObject)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+	newCluster := func(name string, labels map[string]string) *fedv1a1.FederatedCluster {
+		cluster := &fedv1a1.FederatedCluster{}
+		cluster.Name = name
+		cluster.Labels = labels
+		return cluster
+	}
+	hashFor := func(clusters ...*fedv1a1.FederatedCluster) string {
+		hash, err := GetOverrideHash(fedObject, clusters)
+		if err != nil {
+			t.Fatalf("An unexpected error occurred: %v", err)
+		}
+		return hash
+	}
+
+	matching := hashFor(newCluster("c1", map[string]string{"foo": "bar"}), newCluster("c2", nil))
+	if hashFor(newCluster("c1", map[string]string{"foo": "bar", "baz": "qux"}), newCluster("c2", nil)) != matching {
+		t.Fatalf("Expected the hash to be unchanged when the matching clusters are unchanged")
+	}
+	if hashFor(newCluster("c1", nil), newCluster("c2", nil)) == matching {
+		t.Fatalf("Expected the hash to change when the matching clusters change")
+	}
+}
+
+func TestPauseReason(t *testing.T) {
+	paused := &unstructured.Unstructured{}
+	paused.SetAnnotations(map[string]string{util.PausedAnnotation: "true"})
+	unpaused := &unstructured.Unstructured{}
+	unpaused.SetAnnotations(map[string]string{util.PausedAnnotation: "false"})
+
+	testCases := map[string]struct {
+		resource       *unstructured.Unstructured
+		fedNamespace   *unstructured.Unstructured
+		expectedReason string
+	}{
+		"not paused": {
+			resource:     unpaused,
+			fedNamespace: unpaused,
+		},
+		"resource paused": {
+			resource:       paused,
+			fedNamespace:   unpaused,
+			expectedReason: "Propagation is paused",
+		},
+		"namespace paused": {
+			resource:       unpaused,
+			fedNamespace:   paused,
+			expectedReason: "Propagation is paused for the namespace",
+		},
+		"no federated namespace": {
+			resource: unpaused,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			resource := &federatedResource{
+				federatedResource: testCase.resource,
+				fedNamespace:      testCase.fedNamespace,
+			}
+			if reason := resource.PauseReason(); reason != testCase.expectedReason {
+				t.Fatalf("Expected reason %q, got %q", testCase.expectedReason, reason)
+			}
+		})
+	}
+}
+
+func TestObservedGeneration(t *testing.T) {
+	testCases := map[string]struct {
+		statusMode         fedv1a1.StatusMode
+		expectedGeneration int64
+	}{
+		"generation is not observed with status object": {
+			statusMode:         fedv1a1.StatusModeObject,
+			expectedGeneration: 0,
+		},
+		"generation is observed with status subresource": {
+			statusMode:         fedv1a1.StatusModeSubresource,
+			expectedGeneration: 3,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			obj.SetGeneration(3)
+			resource := &federatedResource{
+				typeConfig: &fedv1a1.FederatedTypeConfig{
+					Spec: fedv1a1.FederatedTypeConfigSpec{StatusMode: testCase.statusMode},
+				},
+				federatedResource: obj,
+			}
+			generation := resource.ObservedGeneration()
+			if generation != testCase.expectedGeneration {
+				t.Fatalf("Expected generation %d, got %d", testCase.expectedGeneration, generation)
+			}
+		})
+	}
+}
+
+func TestObjectForClusterTemplating(t *testing.T) {
+	data := map[string]interface{}{
+		"host":  "{{ .Cluster.Name }}.example.com",
+		"chart": "{{ .Values.name }}",
+	}
+
+	testCases := map[string]struct {
+		annotations  map[string]string
+		expectedData map[string]interface{}
+		expectedErr  bool
+	}{
+		"template actions are not rendered without opting in": {
+			expectedData: data,
+		},
+		"template actions are not rendered if templating is disabled": {
+			annotations:  map[string]string{util.ClusterTemplatingAnnotation: "false"},
+			expectedData: data,
+		},
+		"template actions are only rendered in the listed fields": {
+			annotations: map[string]string{util.ClusterTemplatingFieldsAnnotation: "data.host"},
+			expectedData: map[string]interface{}{
+				"host":  "cluster1.example.com",
+				"chart": "{{ .Values.name }}",
+			},
+		},
+		"template actions are rendered in all fields": {
+			annotations: map[string]string{util.ClusterTemplatingAnnotation: "true"},
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			templateData := map[string]interface{}{}
+			for key, value := range data {
+				templateData[key] = value
+			}
+			fedObject := &unstructured.Unstructured{Object: map[string]interface{}{
+				util.SpecField: map[string]interface{}{
+					util.TemplateField: map[string]interface{}{
+						"data": templateData,
+					},
+				},
+			}}
+			fedObject.SetNamespace("ns")
+			fedObject.SetName("foo")
+			fedObject.SetAnnotations(testCase.annotations)
+			resource := &federatedResource{
+				typeConfig: &fedv1a1.FederatedTypeConfig{
+					Spec: fedv1a1.FederatedTypeConfigSpec{
+						Target: fedv1a1.APIResource{Version: "v1", Kind: "ConfigMap"},
+					},
+				},
+				federatedResource: fedObject,
+			}
+
+			obj, err := resource.ObjectForCluster("cluster1")
+			if testCase.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(testCase.expectedData, obj.Object["data"]) {
+				t.Fatalf("Expected data %v, got %v", testCase.expectedData, obj.Object["data"])
+			}
+		})
+	}
+}
+
+func TestRenderedVersion(t *testing.T) {
+	newCluster := func(name string, labels map[string]string) *fedv1a1.FederatedCluster {
+		cluster := &fedv1a1.FederatedCluster{}
+		cluster.Name = name
+		cluster.Labels = labels
+		return cluster
+	}
+	newResource := func(clusters ...*fedv1a1.FederatedCluster) *federatedResource {
+		fedObject := &unstructured.Unstructured{Object: map[string]interface{}{
+			util.SpecField: map[string]interface{}{
+				util.TemplateField: map[string]interface{}{
+					"data": map[string]interface{}{
+						"tier": "{{ .Cluster.Labels.tier }}",
+					},
+				},
+			},
+		}}
+		fedObject.SetNamespace("ns")
+		fedObject.SetName("foo")
+		fedObject.SetAnnotations(map[string]string{util.ClusterTemplatingAnnotation: "true"})
+		return &federatedResource{
+			typeConfig: &fedv1a1.FederatedTypeConfig{
+				Spec: fedv1a1.FederatedTypeConfigSpec{
+					Target: fedv1a1.APIResource{Version: "v1", Kind: "ConfigMap"},
+				},
+			},
+			federatedResource: fedObject,
+			clusters:          clusters,
+		}
+	}
+	selected := newCluster("cluster1", map[string]string{"tier": "frontend"})
+	unselected := newCluster("cluster2", nil)
+
+	resource := newResource(selected, unselected)
+	if _, err := resource.OverrideVersion(); err != nil {
+		t.Fatalf("Expected the override version to ignore the rendering of an unselected cluster: %v", err)
+	}
+	renderedVersion, err := resource.RenderedVersion(selected.Name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(renderedVersion) == 0 {
+		t.Fatalf("Expected a rendered version")
+	}
+	if _, err := resource.RenderedVersion(unselected.Name); err == nil {
+		t.Fatalf("Expected an error rendering a cluster missing a referenced label")
+	}
+
+	// The rendered version of a cluster does not depend on the
+	// variables of other clusters.
+	changedResource := newResource(selected, newCluster("cluster2", map[string]string{"tier": "backend"}))
+	changedVersion, err := changedResource.RenderedVersion(selected.Name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if renderedVersion != changedVersion {
+		t.Fatalf("Expected rendered version %q, got %q", renderedVersion, changedVersion)
+	}
+
+	resource.federatedResource.SetAnnotations(nil)
+	renderedVersion, err = resource.RenderedVersion(unselected.Name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(renderedVersion) != 0 {
+		t.Fatalf("Expected no rendered version without templating, got %q", renderedVersion)
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+)
+
+// rolloutPlan is the outcome of planning the next step of a rollout.
+type rolloutPlan struct {
+	status *util.RolloutStatus
+	// The clusters whose pending operations may proceed.
+	allowedClusters sets.String
+	// Why the pending operations of other clusters are withheld.
+	waitReason string
+	// The clusters whose pending operations were withheld.
+	withheldClusters []string
+	// Whether the rollout is in progress and should be rechecked.
+	inProgress bool
+	// The reason the rollout was halted, if it was.
+	haltErr error
+}
+
+func (p *rolloutPlan) halt(message string) {
+	p.status.Halted = true
+	p.status.Complete = false
+	p.status.Message = message
+	p.haltErr = errors.New(message)
+	p.waitReason = "The rollout was halted"
+	p.inProgress = false
+}
+
+// rolloutWaves divides the selected clusters into the waves of the
+// given directive.  A cluster belongs to the first wave whose
+// selector matches its labels, and clusters not matching any wave
+// belong to a final wave.  The clusters of each wave are sorted by
+// name.
+func rolloutWaves(directive *util.RolloutDirective, clusters []*fedv1a1.FederatedCluster, selectedClusters []string) [][]string {
+	selectedSet := sets.NewString(selectedClusters...)
+	waves := make([][]string, len(directive.WaveSelectors)+1)
+	for _, cluster := range clusters {
+		if !selectedSet.Has(cluster.Name) {
+			continue
+		}
+		waveIndex := len(directive.WaveSelectors)
+		for i, selector := range directive.WaveSelectors {
+			if selector.Matches(labels.Set(cluster.Labels)) {
+				waveIndex = i
+				break
+			}
+		}
+		waves[waveIndex] = append(waves[waveIndex], cluster.Name)
+	}
+	for _, wave := range waves {
+		sort.Strings(wave)
+	}
+	return waves
+}
+
+// planRollout determines which of the pending clusters may be updated
+// for the given revision.  The rollout proceeds through the waves in
+// order, and a wave is complete when none of its clusters are
+// pending or unhealthy.  Within the current wave, pending clusters
+// are updated as long as fewer than the maximum number of clusters
+// are unhealthy.  The rollout is halted if unhealthy clusters do not
+// become healthy within the progress deadline.
+func planRollout(directive *util.RolloutDirective, previous *util.RolloutStatus, revision string, waves [][]string, pendingClusters sets.String, unhealthyClusters map[string]string, now metav1.Time) *rolloutPlan {
+	status := &util.RolloutStatus{
+		Revision:  revision,
+		StartTime: now,
+	}
+	if previous != nil && previous.Revision == revision {
+		previousCopy := *previous
+		status = &previousCopy
+	}
+	plan := &rolloutPlan{
+		status:          status,
+		allowedClusters: sets.NewString(),
+	}
+	if status.Halted {
+		plan.haltErr = errors.New(status.Message)
+		plan.waitReason = "The rollout was halted"
+		return plan
+	}
+
+	for i, wave := range waves {
+		pending := []string{}
+		unhealthy := []string{}
+		for _, clusterName := range wave {
+			if pendingClusters.Has(clusterName) {
+				pending = append(pending, clusterName)
+			} else if _, ok := unhealthyClusters[clusterName]; ok {
+				unhealthy = append(unhealthy, clusterName)
+			}
+		}
+		if len(pending) == 0 && len(unhealthy) == 0 {
+			continue
+		}
+		plan.inProgress = true
+		status.Complete = false
+
+		waveIndex := int32(i)
+		if status.CurrentWave < waveIndex {
+			// Only pause if a previous wave was updated.
+			if status.BatchStartTime != nil && directive.Pause > 0 {
+				if status.PauseStartTime == nil {
+					status.PauseStartTime = &now
+				}
+				if now.Time.Before(status.PauseStartTime.Add(directive.Pause)) {
+					plan.waitReason = fmt.Sprintf("Pausing before starting wave %d", i+1)
+					status.Message = plan.waitReason
+					return plan
+				}
+			}
+			status.CurrentWave = waveIndex
+			status.PauseStartTime = nil
+		}
+
+		if len(unhealthy) > 0 {
+			progressStartTime := status.StartTime
+			if status.BatchStartTime != nil {
+				progressStartTime = *status.BatchStartTime
+			}
+			if now.Sub(progressStartTime.Time) > directive.ProgressDeadline {
+				reasons := []string{}
+				for _, clusterName := range unhealthy {
+					reasons = append(reasons, fmt.Sprintf("%s (%s)", clusterName, unhealthyClusters[clusterName]))
+				}
+				plan.halt(fmt.Sprintf("Clusters did not become healthy within %v: %s", directive.ProgressDeadline, strings.Join(reasons, ", ")))
+				return plan
+			}
+		}
+
+		available := directive.MaxUnavailableClusters - len(unhealthy)
+		for _, clusterName := range pending {
+			if available <= 0 {
+				break
+			}
+			plan.allowedClusters.Insert(clusterName)
+			available--
+		}
+		if plan.allowedClusters.Len() > 0 {
+			status.BatchStartTime = &now
+		}
+		plan.waitReason = fmt.Sprintf("Waiting for the rollout of wave %d to progress", i+1)
+		status.Message = fmt.Sprintf("Rolling out wave %d of %d", i+1, len(waves))
+		return plan
+	}
+
+	status.Complete = true
+	status.PauseStartTime = nil
+	status.Message = "The rollout is complete"
+	return plan
+}
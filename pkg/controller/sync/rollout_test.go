@@ -0,0 +1,235 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+)
+
+func TestRolloutWaves(t *testing.T) {
+	newCluster := func(name, stage string) *fedv1a1.FederatedCluster {
+		return &fedv1a1.FederatedCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"stage": stage},
+			},
+		}
+	}
+	clusters := []*fedv1a1.FederatedCluster{
+		newCluster("c4", "prod"),
+		newCluster("c3", "canary"),
+		newCluster("c2", "prod"),
+		newCluster("c1", "canary"),
+		newCluster("c5", "test"),
+	}
+
+	testCases := map[string]struct {
+		selectors        []string
+		selectedClusters []string
+		expectedWaves    [][]string
+	}{
+		"no waves": {
+			selectedClusters: []string{"c1", "c2", "c3"},
+			expectedWaves:    [][]string{{"c1", "c2", "c3"}},
+		},
+		"clusters assigned to the first matching wave": {
+			selectors:        []string{"stage=canary", "stage in (canary,prod)"},
+			selectedClusters: []string{"c1", "c2", "c3", "c4"},
+			expectedWaves:    [][]string{{"c1", "c3"}, {"c2", "c4"}, nil},
+		},
+		"unmatched clusters in the final wave": {
+			selectors:        []string{"stage=canary"},
+			selectedClusters: []string{"c1", "c4", "c5"},
+			expectedWaves:    [][]string{{"c1"}, {"c4", "c5"}},
+		},
+		"unselected clusters ignored": {
+			selectors:        []string{"stage=canary"},
+			selectedClusters: []string{"c2"},
+			expectedWaves:    [][]string{nil, {"c2"}},
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			directive := &util.RolloutDirective{}
+			for _, selector := range tc.selectors {
+				parsed, err := labels.Parse(selector)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				directive.WaveSelectors = append(directive.WaveSelectors, parsed)
+			}
+			waves := rolloutWaves(directive, clusters, tc.selectedClusters)
+			if !reflect.DeepEqual(waves, tc.expectedWaves) {
+				t.Fatalf("Expected waves %v, got %v", tc.expectedWaves, waves)
+			}
+		})
+	}
+}
+
+func TestPlanRollout(t *testing.T) {
+	then := metav1.NewTime(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC))
+	now := metav1.NewTime(then.Add(time.Minute))
+	directive := &util.RolloutDirective{
+		MaxUnavailableClusters: 2,
+		Pause:                  5 * time.Minute,
+		ProgressDeadline:       10 * time.Minute,
+	}
+	waves := [][]string{{"c1"}, {"c2", "c3", "c4"}}
+
+	testCases := map[string]struct {
+		previous          *util.RolloutStatus
+		pendingClusters   []string
+		unhealthyClusters map[string]string
+		expectedAllowed   []string
+		expectedWave      int32
+		expectedProgress  bool
+		expectedComplete  bool
+		expectedHalted    bool
+	}{
+		"new revision starts with the first wave": {
+			previous: &util.RolloutStatus{
+				Revision:  "old",
+				StartTime: then,
+				Complete:  true,
+			},
+			pendingClusters:  []string{"c1", "c2", "c3", "c4"},
+			expectedAllowed:  []string{"c1"},
+			expectedWave:     0,
+			expectedProgress: true,
+		},
+		"wave waits for unhealthy clusters": {
+			previous: &util.RolloutStatus{
+				Revision:       "rev",
+				StartTime:      then,
+				BatchStartTime: &then,
+			},
+			pendingClusters:   []string{"c2", "c3", "c4"},
+			unhealthyClusters: map[string]string{"c1": "not ready"},
+			expectedAllowed:   []string{},
+			expectedWave:      0,
+			expectedProgress:  true,
+		},
+		"pause before the next wave": {
+			previous: &util.RolloutStatus{
+				Revision:       "rev",
+				StartTime:      then,
+				BatchStartTime: &then,
+			},
+			pendingClusters:  []string{"c2", "c3", "c4"},
+			expectedAllowed:  []string{},
+			expectedWave:     0,
+			expectedProgress: true,
+		},
+		"next wave started after the pause": {
+			previous: &util.RolloutStatus{
+				Revision:       "rev",
+				StartTime:      then,
+				BatchStartTime: &then,
+				PauseStartTime: &metav1.Time{Time: then.Add(-10 * time.Minute)},
+			},
+			pendingClusters:  []string{"c2", "c3", "c4"},
+			expectedAllowed:  []string{"c2", "c3"},
+			expectedWave:     1,
+			expectedProgress: true,
+		},
+		"unhealthy clusters limit the batch": {
+			previous: &util.RolloutStatus{
+				Revision:       "rev",
+				StartTime:      then,
+				CurrentWave:    1,
+				BatchStartTime: &then,
+			},
+			pendingClusters:   []string{"c3", "c4"},
+			unhealthyClusters: map[string]string{"c2": "not ready"},
+			expectedAllowed:   []string{"c3"},
+			expectedWave:      1,
+			expectedProgress:  true,
+		},
+		"rollout halted after the progress deadline": {
+			previous: &util.RolloutStatus{
+				Revision:       "rev",
+				StartTime:      then,
+				CurrentWave:    1,
+				BatchStartTime: &metav1.Time{Time: then.Add(-time.Hour)},
+			},
+			pendingClusters:   []string{"c3", "c4"},
+			unhealthyClusters: map[string]string{"c2": "not ready"},
+			expectedAllowed:   []string{},
+			expectedWave:      1,
+			expectedHalted:    true,
+		},
+		"halted rollout stays halted": {
+			previous: &util.RolloutStatus{
+				Revision:    "rev",
+				StartTime:   then,
+				CurrentWave: 1,
+				Halted:      true,
+				Message:     "halted",
+			},
+			pendingClusters: []string{"c3", "c4"},
+			expectedAllowed: []string{},
+			expectedWave:    1,
+			expectedHalted:  true,
+		},
+		"rollout complete when all clusters are healthy": {
+			previous: &util.RolloutStatus{
+				Revision:       "rev",
+				StartTime:      then,
+				CurrentWave:    1,
+				BatchStartTime: &then,
+			},
+			expectedAllowed:  []string{},
+			expectedWave:     1,
+			expectedComplete: true,
+		},
+	}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			plan := planRollout(directive, tc.previous, "rev", waves, sets.NewString(tc.pendingClusters...), tc.unhealthyClusters, now)
+			if !plan.allowedClusters.Equal(sets.NewString(tc.expectedAllowed...)) {
+				t.Errorf("Expected allowed clusters %v, got %v", tc.expectedAllowed, plan.allowedClusters.List())
+			}
+			if plan.status.CurrentWave != tc.expectedWave {
+				t.Errorf("Expected wave %d, got %d", tc.expectedWave, plan.status.CurrentWave)
+			}
+			if plan.inProgress != tc.expectedProgress {
+				t.Errorf("Expected inProgress to be %v, got %v", tc.expectedProgress, plan.inProgress)
+			}
+			if plan.status.Complete != tc.expectedComplete {
+				t.Errorf("Expected complete to be %v, got %v", tc.expectedComplete, plan.status.Complete)
+			}
+			if plan.status.Halted != tc.expectedHalted {
+				t.Errorf("Expected halted to be %v, got %v", tc.expectedHalted, plan.status.Halted)
+			}
+			if tc.expectedHalted != (plan.haltErr != nil) {
+				t.Errorf("Expected a halt error to be %v, got %v", tc.expectedHalted, plan.haltErr)
+			}
+			if plan.status.Revision != "rev" {
+				t.Errorf("Expected revision %q, got %q", "rev", plan.status.Revision)
+			}
+		})
+	}
+}
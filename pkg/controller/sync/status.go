@@ -0,0 +1,362 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+)
+
+// Reasons for a Failed condition that is not specific to a cluster.
+const (
+	ComputePlacementFailed  = "ComputePlacementFailed"
+	ComputeOperationsFailed = "ComputeOperationsFailed"
+	ClusterUpdateFailed     = "ClusterUpdateFailed"
+	RolloutHalted           = "RolloutHalted"
+	// An unmanaged target resource exists in a cluster and the
+	// conflict policy of the resource is Fail.
+	UnmanagedResourceConflict = "UnmanagedResourceConflict"
+)
+
+type clusterState struct {
+	state         util.ClusterPropagationState
+	reason        string
+	driftedFields []util.DriftedField
+}
+
+// propagationResult accumulates the outcome of reconciling a
+// federated resource for the purpose of computing its propagation
+// status.
+type propagationResult struct {
+	// Whether placement was computed.  If not, the selected clusters
+	// are not known and the previous cluster states will be retained.
+	placementComputed bool
+	selectedClusters  []string
+	clusterStates     map[string]clusterState
+
+	// The reason and error for a failure that is not specific to a
+	// cluster.
+	failureReason string
+	failureErr    error
+
+	// The value of the correct-drift annotation for which drift was
+	// corrected.
+	correctedDrift string
+
+	// Whether the rollout strategy was evaluated, and the resulting
+	// rollout status.  If the strategy was not evaluated, the previous
+	// rollout status will be retained.
+	rolloutComputed bool
+	rollout         *util.RolloutStatus
+
+	// The name of the revision recording the current template and
+	// overrides, if revision history is enabled.
+	currentRevision string
+
+	// The automatic rollback performed, if any.
+	rollback *util.RollbackStatus
+
+	// The failure of the current revision that will trigger an
+	// automatic rollback if it persists, if any.
+	revisionFailure *util.RevisionFailure
+
+	// The generation of the federated resource that was reconciled.
+	observedGeneration int64
+}
+
+func newPropagationResult() *propagationResult {
+	return &propagationResult{
+		clusterStates: make(map[string]clusterState),
+	}
+}
+
+func (r *propagationResult) setSelectedClusters(selectedClusters []string) {
+	r.placementComputed = true
+	r.selectedClusters = selectedClusters
+}
+
+func (r *propagationResult) setClusterState(clusterName string, state util.ClusterPropagationState, reason string) {
+	r.clusterStates[clusterName] = clusterState{state: state, reason: reason}
+}
+
+func (r *propagationResult) setClusterDrift(clusterName, reason string, fields []util.DriftedField) {
+	r.clusterStates[clusterName] = clusterState{
+		state:         util.ClusterPropagationDrifted,
+		reason:        reason,
+		driftedFields: fields,
+	}
+}
+
+func (r *propagationResult) setCorrectedDrift(correctedDrift string) {
+	if len(correctedDrift) > 0 {
+		r.correctedDrift = correctedDrift
+	}
+}
+
+func (r *propagationResult) setRollout(rollout *util.RolloutStatus) {
+	r.rolloutComputed = true
+	r.rollout = rollout
+}
+
+func (r *propagationResult) setCurrentRevision(currentRevision string) {
+	r.currentRevision = currentRevision
+}
+
+func (r *propagationResult) setObservedGeneration(generation int64) {
+	r.observedGeneration = generation
+}
+
+func (r *propagationResult) setRollback(rollback *util.RollbackStatus) {
+	r.rollback = rollback
+}
+
+func (r *propagationResult) setRevisionFailure(revisionFailure *util.RevisionFailure) {
+	r.revisionFailure = revisionFailure
+}
+
+// failedClusters returns the selected clusters whose update failed.
+func (r *propagationResult) failedClusters() []string {
+	failed := []string{}
+	for _, clusterName := range r.selectedClusters {
+		if r.clusterStates[clusterName].state == util.ClusterPropagationUpdateFailed {
+			failed = append(failed, clusterName)
+		}
+	}
+	return failed
+}
+
+func (r *propagationResult) setFailure(reason string, err error) {
+	r.failureReason = reason
+	r.failureErr = err
+}
+
+// computePropagationStatus determines the propagation status of a
+// federated resource from the result of a reconcile.  Clusters that
+// are no longer joined are omitted, as are unready and unselected
+// clusters that were not previously reported.  Transition times are
+// retained from the previous status for conditions and clusters
+// whose state has not changed.
+func computePropagationStatus(previous *util.PropagationStatus, result *propagationResult, readyClusters, unreadyClusters []*fedv1a1.FederatedCluster, now metav1.Time) *util.PropagationStatus {
+	status := &util.PropagationStatus{
+		ObservedGeneration: result.observedGeneration,
+		CorrectedDrift:     previous.CorrectedDrift,
+	}
+	if len(result.correctedDrift) > 0 {
+		status.CorrectedDrift = result.correctedDrift
+	}
+	status.Rollout = previous.Rollout
+	if result.rolloutComputed {
+		status.Rollout = result.rollout
+	}
+	status.CurrentRevision = result.currentRevision
+	status.Rollback = previous.Rollback
+	if result.rollback != nil {
+		status.Rollback = result.rollback
+	}
+	status.RevisionFailure = result.revisionFailure
+
+	unreadySet := sets.NewString()
+	for _, cluster := range unreadyClusters {
+		unreadySet.Insert(cluster.Name)
+	}
+	selectedSet := sets.NewString(result.selectedClusters...)
+
+	for _, clusterName := range append(getClusterNames(readyClusters), unreadySet.List()...) {
+		previousStatus := previous.ClusterStatus(clusterName)
+
+		var current clusterState
+		switch {
+		case unreadySet.Has(clusterName):
+			// Only clusters that were previously reported are
+			// reported as not ready since placement is only computed
+			// for ready clusters.
+			if previousStatus == nil || previousStatus.State == util.ClusterPropagationDeleted {
+				if previousStatus != nil {
+					status.Clusters = append(status.Clusters, *previousStatus)
+				}
+				continue
+			}
+			current = clusterState{
+				state:  util.ClusterPropagationClusterNotReady,
+				reason: "The cluster is not ready",
+			}
+		default:
+			var ok bool
+			current, ok = result.clusterStates[clusterName]
+			if !ok {
+				// Retain the previous state of a cluster whose state
+				// could not be determined.
+				if previousStatus != nil {
+					status.Clusters = append(status.Clusters, *previousStatus)
+				}
+				continue
+			}
+			// Removal is only reported for clusters that were
+			// previously reported to avoid listing every
+			// unselected cluster.
+			if current.state == util.ClusterPropagationDeleted && previousStatus == nil {
+				continue
+			}
+		}
+
+		clusterStatus := util.ClusterPropagationStatus{
+			Cluster:            clusterName,
+			State:              current.state,
+			Reason:             current.reason,
+			LastTransitionTime: now,
+			DriftedFields:      current.driftedFields,
+		}
+		if previousStatus != nil && previousStatus.State == current.state {
+			clusterStatus.LastTransitionTime = previousStatus.LastTransitionTime
+		}
+		status.Clusters = append(status.Clusters, clusterStatus)
+	}
+	sort.Slice(status.Clusters, func(i, j int) bool {
+		return status.Clusters[i].Cluster < status.Clusters[j].Cluster
+	})
+
+	placedCount := 0
+	failedClusters := []string{}
+	driftedClusters := []string{}
+	for _, clusterStatus := range status.Clusters {
+		if clusterStatus.State == util.ClusterPropagationPlaced && selectedSet.Has(clusterStatus.Cluster) {
+			placedCount++
+		}
+		if clusterStatus.State == util.ClusterPropagationUpdateFailed {
+			failedClusters = append(failedClusters, clusterStatus.Cluster)
+		}
+		if clusterStatus.State == util.ClusterPropagationDrifted {
+			driftedClusters = append(driftedClusters, clusterStatus.Cluster)
+		}
+	}
+	selectedCount := len(result.selectedClusters)
+	placedMessage := fmt.Sprintf("Placed in %d of %d selected clusters", placedCount, selectedCount)
+
+	propagated := util.PropagationCondition{
+		Type:    util.PropagationConditionPropagated,
+		Status:  apiv1.ConditionFalse,
+		Message: placedMessage,
+	}
+	if result.placementComputed && result.failureErr == nil && placedCount == selectedCount {
+		propagated.Status = apiv1.ConditionTrue
+	}
+
+	partiallyPropagated := util.PropagationCondition{
+		Type:    util.PropagationConditionPartiallyPropagated,
+		Status:  apiv1.ConditionFalse,
+		Message: placedMessage,
+	}
+	if placedCount > 0 && (placedCount < selectedCount || result.failureErr != nil) {
+		partiallyPropagated.Status = apiv1.ConditionTrue
+	}
+
+	failed := util.PropagationCondition{
+		Type:   util.PropagationConditionFailed,
+		Status: apiv1.ConditionFalse,
+	}
+	if result.failureErr != nil {
+		failed.Status = apiv1.ConditionTrue
+		failed.Reason = result.failureReason
+		failed.Message = result.failureErr.Error()
+	} else if len(failedClusters) > 0 {
+		failed.Status = apiv1.ConditionTrue
+		failed.Reason = ClusterUpdateFailed
+		failed.Message = fmt.Sprintf("Failed to update clusters: %s", strings.Join(failedClusters, ", "))
+	}
+
+	drifted := util.PropagationCondition{
+		Type:   util.PropagationConditionDrifted,
+		Status: apiv1.ConditionFalse,
+	}
+	if len(driftedClusters) > 0 {
+		drifted.Status = apiv1.ConditionTrue
+		drifted.Message = fmt.Sprintf("Drift detected in clusters: %s", strings.Join(driftedClusters, ", "))
+	}
+
+	for _, condition := range []util.PropagationCondition{propagated, partiallyPropagated, failed, drifted} {
+		condition.LastTransitionTime = now
+		if previousCondition := previous.Condition(condition.Type); previousCondition != nil && previousCondition.Status == condition.Status {
+			condition.LastTransitionTime = previousCondition.LastTransitionTime
+		}
+		status.Conditions = append(status.Conditions, condition)
+	}
+
+	return status
+}
+
+// propagationStatusEqual indicates whether the given statuses are
+// equivalent.  Transition times are compared since they are retained
+// for unchanged states.
+func propagationStatusEqual(a, b *util.PropagationStatus) bool {
+	return reflect.DeepEqual(normalizePropagationStatus(a), normalizePropagationStatus(b))
+}
+
+// normalizePropagationStatus ensures that empty and nil slices and
+// times that differ only below the serialized precision of a second
+// compare as equal.
+func normalizePropagationStatus(status *util.PropagationStatus) *util.PropagationStatus {
+	normalized := &util.PropagationStatus{
+		ObservedGeneration: status.ObservedGeneration,
+	}
+	for _, condition := range status.Conditions {
+		condition.LastTransitionTime = normalizeTime(condition.LastTransitionTime)
+		normalized.Conditions = append(normalized.Conditions, condition)
+	}
+	for _, clusterStatus := range status.Clusters {
+		clusterStatus.LastTransitionTime = normalizeTime(clusterStatus.LastTransitionTime)
+		normalized.Clusters = append(normalized.Clusters, clusterStatus)
+	}
+	normalized.CorrectedDrift = status.CorrectedDrift
+	if status.Rollout != nil {
+		rollout := *status.Rollout
+		rollout.StartTime = normalizeTime(rollout.StartTime)
+		if rollout.BatchStartTime != nil {
+			batchStartTime := normalizeTime(*rollout.BatchStartTime)
+			rollout.BatchStartTime = &batchStartTime
+		}
+		if rollout.PauseStartTime != nil {
+			pauseStartTime := normalizeTime(*rollout.PauseStartTime)
+			rollout.PauseStartTime = &pauseStartTime
+		}
+		normalized.Rollout = &rollout
+	}
+	normalized.CurrentRevision = status.CurrentRevision
+	if status.Rollback != nil {
+		rollback := *status.Rollback
+		rollback.Time = normalizeTime(rollback.Time)
+		normalized.Rollback = &rollback
+	}
+	if status.RevisionFailure != nil {
+		revisionFailure := *status.RevisionFailure
+		revisionFailure.StartTime = normalizeTime(revisionFailure.StartTime)
+		normalized.RevisionFailure = &revisionFailure
+	}
+	return normalized
+}
+
+func normalizeTime(t metav1.Time) metav1.Time {
+	return metav1.NewTime(t.Rfc3339Copy().Time.UTC())
+}
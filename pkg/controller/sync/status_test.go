@@ -0,0 +1,245 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+)
+
+func TestComputePropagationStatus(t *testing.T) {
+	newClusters := func(names ...string) []*fedv1a1.FederatedCluster {
+		clusters := []*fedv1a1.FederatedCluster{}
+		for _, name := range names {
+			clusters = append(clusters, &fedv1a1.FederatedCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+			})
+		}
+		return clusters
+	}
+	then := metav1.NewTime(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC))
+	now := metav1.NewTime(then.Add(time.Hour))
+
+	testCases := map[string]struct {
+		previous          []util.ClusterPropagationStatus
+		result            func(*propagationResult)
+		readyClusters     []string
+		unreadyClusters   []string
+		expectedStates    map[string]util.ClusterPropagationState
+		expectedCondition util.PropagationConditionType
+		expectedFailed    bool
+		expectedDrifted   bool
+		// The expected value of the correctedDrift field.
+		expectedCorrected string
+	}{
+		"all selected clusters placed": {
+			readyClusters: []string{"c1", "c2"},
+			result: func(r *propagationResult) {
+				r.setSelectedClusters([]string{"c1", "c2"})
+				r.setClusterState("c1", util.ClusterPropagationPlaced, "")
+				r.setClusterState("c2", util.ClusterPropagationPlaced, "")
+			},
+			expectedStates: map[string]util.ClusterPropagationState{
+				"c1": util.ClusterPropagationPlaced,
+				"c2": util.ClusterPropagationPlaced,
+			},
+			expectedCondition: util.PropagationConditionPropagated,
+		},
+		"update failed for one cluster": {
+			readyClusters: []string{"c1", "c2"},
+			result: func(r *propagationResult) {
+				r.setSelectedClusters([]string{"c1", "c2"})
+				r.setClusterState("c1", util.ClusterPropagationPlaced, "")
+				r.setClusterState("c2", util.ClusterPropagationUpdateFailed, "forbidden")
+			},
+			expectedStates: map[string]util.ClusterPropagationState{
+				"c1": util.ClusterPropagationPlaced,
+				"c2": util.ClusterPropagationUpdateFailed,
+			},
+			expectedCondition: util.PropagationConditionPartiallyPropagated,
+			expectedFailed:    true,
+		},
+		"unselected cluster not previously reported is omitted": {
+			readyClusters: []string{"c1", "c2"},
+			result: func(r *propagationResult) {
+				r.setSelectedClusters([]string{"c1"})
+				r.setClusterState("c1", util.ClusterPropagationPlaced, "")
+				r.setClusterState("c2", util.ClusterPropagationDeleted, unselectedReason)
+			},
+			expectedStates: map[string]util.ClusterPropagationState{
+				"c1": util.ClusterPropagationPlaced,
+			},
+			expectedCondition: util.PropagationConditionPropagated,
+		},
+		"unselected cluster previously reported is deleted": {
+			previous: []util.ClusterPropagationStatus{
+				{Cluster: "c2", State: util.ClusterPropagationPlaced, LastTransitionTime: then},
+			},
+			readyClusters: []string{"c1", "c2"},
+			result: func(r *propagationResult) {
+				r.setSelectedClusters([]string{"c1"})
+				r.setClusterState("c1", util.ClusterPropagationPlaced, "")
+				r.setClusterState("c2", util.ClusterPropagationDeleted, unselectedReason)
+			},
+			expectedStates: map[string]util.ClusterPropagationState{
+				"c1": util.ClusterPropagationPlaced,
+				"c2": util.ClusterPropagationDeleted,
+			},
+			expectedCondition: util.PropagationConditionPropagated,
+		},
+		"unready cluster previously reported is not ready": {
+			previous: []util.ClusterPropagationStatus{
+				{Cluster: "c2", State: util.ClusterPropagationPlaced, LastTransitionTime: then},
+			},
+			readyClusters:   []string{"c1"},
+			unreadyClusters: []string{"c2", "c3"},
+			result: func(r *propagationResult) {
+				r.setSelectedClusters([]string{"c1"})
+				r.setClusterState("c1", util.ClusterPropagationPending, "timed out")
+			},
+			expectedStates: map[string]util.ClusterPropagationState{
+				"c1": util.ClusterPropagationPending,
+				"c2": util.ClusterPropagationClusterNotReady,
+			},
+		},
+		"placement failure retains cluster states": {
+			previous: []util.ClusterPropagationStatus{
+				{Cluster: "c1", State: util.ClusterPropagationPlaced, LastTransitionTime: then},
+			},
+			readyClusters: []string{"c1", "c2"},
+			result: func(r *propagationResult) {
+				r.setFailure(ComputePlacementFailed, errors.New("invalid placement"))
+			},
+			expectedStates: map[string]util.ClusterPropagationState{
+				"c1": util.ClusterPropagationPlaced,
+			},
+			expectedFailed: true,
+		},
+		"drifted cluster": {
+			readyClusters: []string{"c1", "c2"},
+			result: func(r *propagationResult) {
+				r.setSelectedClusters([]string{"c1", "c2"})
+				r.setClusterState("c1", util.ClusterPropagationPlaced, "")
+				r.setClusterDrift("c2", "1 field(s) differ from the desired state", []util.DriftedField{
+					{Path: "spec.replicas", Desired: "1", Actual: "2"},
+				})
+			},
+			expectedStates: map[string]util.ClusterPropagationState{
+				"c1": util.ClusterPropagationPlaced,
+				"c2": util.ClusterPropagationDrifted,
+			},
+			expectedCondition: util.PropagationConditionPartiallyPropagated,
+			expectedDrifted:   true,
+		},
+		"corrected drift is recorded": {
+			previous: []util.ClusterPropagationStatus{
+				{Cluster: "c1", State: util.ClusterPropagationDrifted, LastTransitionTime: then},
+			},
+			readyClusters: []string{"c1"},
+			result: func(r *propagationResult) {
+				r.setSelectedClusters([]string{"c1"})
+				r.setClusterState("c1", util.ClusterPropagationPlaced, "")
+				r.setCorrectedDrift("request-1")
+			},
+			expectedStates: map[string]util.ClusterPropagationState{
+				"c1": util.ClusterPropagationPlaced,
+			},
+			expectedCondition: util.PropagationConditionPropagated,
+			expectedCorrected: "request-1",
+		},
+		"removed cluster is omitted": {
+			previous: []util.ClusterPropagationStatus{
+				{Cluster: "c2", State: util.ClusterPropagationPlaced, LastTransitionTime: then},
+			},
+			readyClusters: []string{"c1"},
+			result: func(r *propagationResult) {
+				r.setSelectedClusters([]string{})
+			},
+			expectedStates:    map[string]util.ClusterPropagationState{},
+			expectedCondition: util.PropagationConditionPropagated,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			previous := &util.PropagationStatus{Clusters: testCase.previous}
+			result := newPropagationResult()
+			result.setObservedGeneration(2)
+			testCase.result(result)
+
+			status := computePropagationStatus(previous, result, newClusters(testCase.readyClusters...), newClusters(testCase.unreadyClusters...), now)
+
+			if len(status.Clusters) != len(testCase.expectedStates) {
+				t.Fatalf("Expected %d cluster states, got %v", len(testCase.expectedStates), status.Clusters)
+			}
+			for clusterName, expectedState := range testCase.expectedStates {
+				clusterStatus := status.ClusterStatus(clusterName)
+				if clusterStatus == nil || clusterStatus.State != expectedState {
+					t.Fatalf("Expected state %q for cluster %q, got %v", expectedState, clusterName, clusterStatus)
+				}
+				previousStatus := previous.ClusterStatus(clusterName)
+				if previousStatus != nil && previousStatus.State == expectedState && clusterStatus.LastTransitionTime != then {
+					t.Fatalf("Expected the transition time of cluster %q to be retained", clusterName)
+				}
+			}
+			for _, conditionType := range []util.PropagationConditionType{util.PropagationConditionPropagated, util.PropagationConditionPartiallyPropagated} {
+				expectedStatus := apiv1.ConditionFalse
+				if conditionType == testCase.expectedCondition {
+					expectedStatus = apiv1.ConditionTrue
+				}
+				if condition := status.Condition(conditionType); condition.Status != expectedStatus {
+					t.Fatalf("Expected condition %q to be %q, got %q", conditionType, expectedStatus, condition.Status)
+				}
+			}
+			expectedFailed := apiv1.ConditionFalse
+			if testCase.expectedFailed {
+				expectedFailed = apiv1.ConditionTrue
+			}
+			if condition := status.Condition(util.PropagationConditionFailed); condition.Status != expectedFailed {
+				t.Fatalf("Expected condition %q to be %q, got %q", util.PropagationConditionFailed, expectedFailed, condition.Status)
+			}
+			expectedDrifted := apiv1.ConditionFalse
+			if testCase.expectedDrifted {
+				expectedDrifted = apiv1.ConditionTrue
+			}
+			if condition := status.Condition(util.PropagationConditionDrifted); condition.Status != expectedDrifted {
+				t.Fatalf("Expected condition %q to be %q, got %q", util.PropagationConditionDrifted, expectedDrifted, condition.Status)
+			}
+			if status.ObservedGeneration != 2 {
+				t.Fatalf("Expected observedGeneration to be 2, got %d", status.ObservedGeneration)
+			}
+			if status.CorrectedDrift != testCase.expectedCorrected {
+				t.Fatalf("Expected correctedDrift to be %q, got %q", testCase.expectedCorrected, status.CorrectedDrift)
+			}
+
+			// Recomputing the status from an unchanged result should
+			// not change the status.
+			recomputed := computePropagationStatus(status, result, newClusters(testCase.readyClusters...), newClusters(testCase.unreadyClusters...), metav1.NewTime(now.Add(time.Hour)))
+			if !propagationStatusEqual(status, recomputed) {
+				t.Fatalf("Expected status %v to be unchanged, got %v", status, recomputed)
+			}
+		})
+	}
+}
@@ -47,6 +47,14 @@ type VersionedResource interface {
 	Object() *unstructured.Unstructured
 	TemplateVersion() (string, error)
 	OverrideVersion() (string, error)
+	// ObservedGeneration returns the generation of the resource to
+	// record with its versions, or 0 if the generation is not
+	// recorded.
+	ObservedGeneration() int64
+	// RenderedVersion returns the version of the object rendered for
+	// the given cluster, or an empty string if the resource does not
+	// vary by cluster beyond its overrides.
+	RenderedVersion(clusterName string) (string, error)
 }
 
 type VersionManager struct {
@@ -139,6 +147,15 @@ func (m *VersionManager) Get(resource VersionedResource) (map[string]string, err
 	if templateVersion == status.TemplateVersion &&
 		overrideVersion == status.OverrideVersion {
 		for _, versions := range status.ClusterVersions {
+			// A version is only valid for the object rendered when
+			// it was recorded.  A cluster whose object can no
+			// longer be rendered is left without a version so that
+			// the rendering error is reported for that cluster
+			// alone.
+			renderedVersion, err := resource.RenderedVersion(versions.ClusterName)
+			if err != nil || renderedVersion != versions.RenderedVersion {
+				continue
+			}
 			versionMap[versions.ClusterName] = versions.Version
 		}
 	}
@@ -147,7 +164,8 @@ func (m *VersionManager) Get(resource VersionedResource) (map[string]string, err
 }
 
 // Update ensures that the propagated version for the given versioned
-// resource is recorded.
+// resource is recorded.  The versions in the given map are recorded
+// with the observed generation of the resource.
 func (m *VersionManager) Update(resource VersionedResource,
 	selectedClusters []string, versionMap map[string]string) error {
 
@@ -159,6 +177,18 @@ func (m *VersionManager) Update(resource VersionedResource,
 	if err != nil {
 		return errors.Wrap(err, "Failed to determine override version")
 	}
+	generation := resource.ObservedGeneration()
+	renderedVersions := make(map[string]string)
+	for clusterName, version := range versionMap {
+		if version == "" {
+			continue
+		}
+		renderedVersion, err := resource.RenderedVersion(clusterName)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to determine rendered version for cluster %q", clusterName)
+		}
+		renderedVersions[clusterName] = renderedVersion
+	}
 	qualifiedName := m.versionQualifiedName(resource.FederatedName())
 	key := qualifiedName.String()
 
@@ -174,10 +204,11 @@ func (m *VersionManager) Update(resource VersionedResource,
 		if oldStatus.TemplateVersion == templateVersion && oldStatus.OverrideVersion == overrideVersion {
 			clusterVersions = oldStatus.ClusterVersions
 		}
-		clusterVersions = updateClusterVersions(clusterVersions, versionMap, selectedClusters)
+		clusterVersions = updateClusterVersions(clusterVersions, versionMap, generation, selectedClusters)
 	} else {
-		clusterVersions = VersionMapToClusterVersions(versionMap)
+		clusterVersions = VersionMapToClusterVersions(versionMap, generation)
 	}
+	setRenderedVersions(clusterVersions, renderedVersions)
 
 	status := &fedv1a1.PropagatedVersionStatus{
 		TemplateVersion: templateVersion,
@@ -430,7 +461,9 @@ func ownerReferenceForUnstructured(obj *unstructured.Unstructured) metav1.OwnerR
 }
 
 func updateClusterVersions(oldVersions []fedv1a1.ClusterObjectVersion,
-	newVersions map[string]string, selectedClusters []string) []fedv1a1.ClusterObjectVersion {
+	newVersions map[string]string, generation int64, selectedClusters []string) []fedv1a1.ClusterObjectVersion {
+
+	clusterVersions := VersionMapToClusterVersions(newVersions, generation)
 
 	// Retain versions for selected clusters that were not changed
 	selectedClusterSet := sets.NewString(selectedClusters...)
@@ -439,14 +472,28 @@ func updateClusterVersions(oldVersions []fedv1a1.ClusterObjectVersion,
 			continue
 		}
 		if _, ok := newVersions[oldVersion.ClusterName]; !ok {
-			newVersions[oldVersion.ClusterName] = oldVersion.Version
+			clusterVersions = append(clusterVersions, oldVersion)
 		}
 	}
 
-	return VersionMapToClusterVersions(newVersions)
+	util.SortClusterVersions(clusterVersions)
+	return clusterVersions
+}
+
+// setRenderedVersions records the given rendered versions for the
+// clusters whose versions were produced by the current update.
+func setRenderedVersions(clusterVersions []fedv1a1.ClusterObjectVersion, renderedVersions map[string]string) {
+	for i, clusterVersion := range clusterVersions {
+		if renderedVersion, ok := renderedVersions[clusterVersion.ClusterName]; ok {
+			clusterVersions[i].RenderedVersion = renderedVersion
+		}
+	}
 }
 
-func VersionMapToClusterVersions(versionMap map[string]string) []fedv1a1.ClusterObjectVersion {
+// VersionMapToClusterVersions returns the cluster versions for the
+// given map of cluster names to versions, recording each version as
+// produced from the given generation of the federated resource.
+func VersionMapToClusterVersions(versionMap map[string]string, generation int64) []fedv1a1.ClusterObjectVersion {
 	clusterVersions := []fedv1a1.ClusterObjectVersion{}
 	for clusterName, version := range versionMap {
 		// Lack of version indicates deletion
@@ -456,6 +503,7 @@ func VersionMapToClusterVersions(versionMap map[string]string) []fedv1a1.Cluster
 		clusterVersions = append(clusterVersions, fedv1a1.ClusterObjectVersion{
 			ClusterName: clusterName,
 			Version:     version,
+			Generation:  generation,
 		})
 	}
 	util.SortClusterVersions(clusterVersions)
@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+)
+
+type fakeVersionedResource struct {
+	renderedVersions map[string]string
+}
+
+func (r *fakeVersionedResource) FederatedName() util.QualifiedName {
+	return util.QualifiedName{Namespace: "ns", Name: "foo"}
+}
+
+func (r *fakeVersionedResource) Object() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetNamespace("ns")
+	obj.SetName("foo")
+	return obj
+}
+
+func (r *fakeVersionedResource) TemplateVersion() (string, error) {
+	return "template", nil
+}
+
+func (r *fakeVersionedResource) OverrideVersion() (string, error) {
+	return "override", nil
+}
+
+func (r *fakeVersionedResource) ObservedGeneration() int64 {
+	return 0
+}
+
+func (r *fakeVersionedResource) RenderedVersion(clusterName string) (string, error) {
+	renderedVersion, ok := r.renderedVersions[clusterName]
+	if !ok {
+		return "", errors.Errorf("Unable to render cluster %q", clusterName)
+	}
+	return renderedVersion, nil
+}
+
+func TestGetIgnoresVersionsOfChangedRenderedObjects(t *testing.T) {
+	clusters := []string{"cluster1", "cluster2", "cluster3"}
+	versionMap := map[string]string{
+		"cluster1": "gen:1",
+		"cluster2": "gen:1",
+		"cluster3": "gen:1",
+	}
+	resource := &fakeVersionedResource{renderedVersions: map[string]string{
+		"cluster1": "rendered1",
+		"cluster2": "rendered2",
+		"cluster3": "",
+	}}
+	m := NewVersionManager(nil, true, "FederatedConfigMap", "ConfigMap", "")
+	if err := m.Update(resource, clusters, versionMap); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	versions, err := m.Get(resource)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(versionMap, versions) {
+		t.Fatalf("Expected versions %v, got %v", versionMap, versions)
+	}
+
+	// Only the versions of clusters whose rendered object changed, or
+	// that can no longer be rendered, are discarded.
+	resource.renderedVersions = map[string]string{
+		"cluster1": "changed",
+		"cluster3": "",
+	}
+	versions, err = m.Get(resource)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedVersions := map[string]string{"cluster3": "gen:1"}
+	if !reflect.DeepEqual(expectedVersions, versions) {
+		t.Fatalf("Expected versions %v, got %v", expectedVersions, versions)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ClusterRateLimiter limits the rate of writes to each member cluster
+// with a token bucket per cluster.  A single limiter is intended to be
+// shared by all controllers writing to member clusters.
+type ClusterRateLimiter struct {
+	sync.Mutex
+
+	qps   float32
+	burst int
+
+	limiters map[string]*rate.Limiter
+}
+
+// NewClusterRateLimiter returns a limiter allowing the given rate of
+// writes to each cluster.  Writes are not limited if qps is not
+// positive.
+func NewClusterRateLimiter(qps float32, burst int) *ClusterRateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &ClusterRateLimiter{
+		qps:      qps,
+		burst:    burst,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// Wait blocks until a write to the named cluster is allowed and
+// returns true, or returns false without waiting if the write would
+// not be allowed within the given timeout.  Writes are always allowed
+// by a nil limiter.
+func (l *ClusterRateLimiter) Wait(clusterName string, timeout time.Duration) bool {
+	if l == nil || l.qps <= 0 {
+		return true
+	}
+	reservation := l.limiter(clusterName).Reserve()
+	delay := reservation.Delay()
+	if delay > timeout {
+		reservation.Cancel()
+		return false
+	}
+	time.Sleep(delay)
+	return true
+}
+
+func (l *ClusterRateLimiter) limiter(clusterName string) *rate.Limiter {
+	l.Lock()
+	defer l.Unlock()
+	limiter, ok := l.limiters[clusterName]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(l.qps), l.burst)
+		l.limiters[clusterName] = limiter
+	}
+	return limiter
+}
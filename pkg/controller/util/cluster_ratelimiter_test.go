@@ -0,0 +1,43 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClusterRateLimiter(t *testing.T) {
+	var nilLimiter *ClusterRateLimiter
+	if !nilLimiter.Wait("c1", 0) {
+		t.Fatalf("Expected a nil limiter to allow writes")
+	}
+
+	limiter := NewClusterRateLimiter(0.001, 2)
+	for i := 0; i < 2; i++ {
+		if !limiter.Wait("c1", 0) {
+			t.Fatalf("Expected write %d to be allowed within the burst", i+1)
+		}
+	}
+	if limiter.Wait("c1", time.Second) {
+		t.Fatalf("Expected a write exceeding the burst to be refused")
+	}
+	// Each cluster has its own bucket.
+	if !limiter.Wait("c2", 0) {
+		t.Fatalf("Expected a write to another cluster to be allowed")
+	}
+}
@@ -27,6 +27,7 @@ import (
 	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
 	"github.com/kubernetes-sigs/federation-v2/pkg/client/generic"
 	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	pkgruntime "k8s.io/apimachinery/pkg/runtime"
 	utilnet "k8s.io/apimachinery/pkg/util/net"
 	restclient "k8s.io/client-go/rest"
@@ -47,6 +48,23 @@ const (
 	getSecretTimeout        = 1 * time.Minute
 )
 
+// NewClusterResourceClient returns a client for the given type in the
+// given member cluster.
+func NewClusterResourceClient(fedCluster *fedv1a1.FederatedCluster, client generic.Client, fedNamespace, clusterNamespace string,
+	apiResource *metav1.APIResource) (ResourceClient, error) {
+
+	config, err := BuildClusterConfig(fedCluster, client, fedNamespace, clusterNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, errors.Errorf("Unable to load configuration for cluster %q", fedCluster.Name)
+	}
+
+	restclient.AddUserAgent(config, userAgentName)
+	return NewResourceClient(config, apiResource)
+}
+
 // BuildClusterConfig returns a restclient.Config that can be used to configure
 // a client for the given FederatedCluster or an error. The client is used to
 // access kubernetes secrets in the federation namespace and cluster-registry
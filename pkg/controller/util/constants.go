@@ -36,6 +36,7 @@ const (
 
 	// Common fields
 	SpecField     = "spec"
+	StatusField   = "status"
 	MetadataField = "metadata"
 
 	// ServiceAccount fields
@@ -51,11 +52,13 @@ const (
 	MatchLabelsField     = "matchLabels"
 
 	// Override fields
-	OverridesField        = "overrides"
-	ClusterNameField      = "clusterName"
-	ClusterOverridesField = "clusterOverrides"
-	PathField             = "path"
-	ValueField            = "value"
+	OverridesField           = "overrides"
+	ClusterNameField         = "clusterName"
+	ClusterOverridesField    = "clusterOverrides"
+	StrategicMergePatchField = "strategicMergePatch"
+	OpField                  = "op"
+	PathField                = "path"
+	ValueField               = "value"
 )
 
 type ReconciliationStatus int
@@ -39,6 +39,15 @@ type ControllerConfig struct {
 	ClusterAvailableDelay   time.Duration
 	ClusterUnavailableDelay time.Duration
 	MinimizeLatency         bool
+	// The number of federated resources of each type that may be
+	// reconciled concurrently.  Defaults to 1 if not set.
+	MaxConcurrentSyncReconciles int
+	// The number of member clusters a single federated resource may
+	// be written to concurrently.  Not limited if not set.
+	MaxConcurrentClusterOperations int
+	// Limits the rate of writes to each member cluster.  Not limited
+	// if nil.
+	ClusterWriteRateLimiter *ClusterRateLimiter
 }
 
 func (c *ControllerConfig) LimitedScope() bool {
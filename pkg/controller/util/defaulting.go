@@ -0,0 +1,111 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+// The metadata fields populated by the API server.
+var systemMetadataFields = []string{"selfLink", "uid", "resourceVersion", "generation", "creationTimestamp", "deletionTimestamp", "deletionGracePeriodSeconds"}
+
+// RemoveUnwantedFields removes from the given template of a federated
+// resource the fields that should not be propagated to member
+// clusters: the metadata populated by the API server (including that
+// of a pod template), the name and namespace, which are determined by
+// the federated resource, and the status.  The secrets of a
+// ServiceAccount are also removed since they are populated in each
+// member cluster.
+func RemoveUnwantedFields(template map[string]interface{}, targetKind string) {
+	for _, field := range systemMetadataFields {
+		unstructured.RemoveNestedField(template, MetadataField, field)
+		// For resources with pod template subresource (jobs, deployments, replicasets)
+		unstructured.RemoveNestedField(template, SpecField, TemplateField, MetadataField, field)
+	}
+	unstructured.RemoveNestedField(template, MetadataField, "name")
+	unstructured.RemoveNestedField(template, MetadataField, "namespace")
+	unstructured.RemoveNestedField(template, StatusField)
+	if targetKind == ServiceAccountKind {
+		unstructured.RemoveNestedField(template, SecretsField)
+	}
+}
+
+// DefaultPlacementFromNamespace returns the given federated resource
+// with the placement of the given federated namespace if the resource
+// specifies neither clusterNames nor a clusterSelector.  The resource
+// is copied before it is modified.
+func DefaultPlacementFromNamespace(fedObject, fedNamespace *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	placement := GenericPlacement{}
+	err := UnstructuredToInterface(fedNamespace, &placement)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error retrieving placement of the federated namespace")
+	}
+	fields := placement.Spec.Placement
+	if fields.ClusterNames == nil && fields.ClusterSelector == nil {
+		return fedObject, nil
+	}
+	return applyDefaultPlacement(fedObject, &fedv1a1.DefaultPlacement{
+		ClusterNames:    fields.ClusterNames,
+		ClusterSelector: fields.ClusterSelector,
+	})
+}
+
+// NormalizeOverrides orders the overrides of the given federated
+// resource so that equivalent overrides always hash the same.
+// Overrides targeting a cluster by name are sorted by cluster name and
+// precede those targeting a cluster selector, as per SetOverrides.
+// Since overrides targeting a cluster selector are applied in the
+// order they are defined, their order is retained.
+func NormalizeOverrides(fedObject *unstructured.Unstructured) error {
+	overrides, ok, err := unstructured.NestedSlice(fedObject.Object, SpecField, OverridesField)
+	if err != nil {
+		return errors.Wrap(err, "Error retrieving overrides")
+	}
+	if !ok {
+		return nil
+	}
+	namedOverrides := []interface{}{}
+	selectorOverrides := []interface{}{}
+	for _, rawOverridesItem := range overrides {
+		if overridesItem, ok := rawOverridesItem.(map[string]interface{}); ok {
+			if _, ok := overridesItem[ClusterSelectorField]; ok {
+				selectorOverrides = append(selectorOverrides, overridesItem)
+				continue
+			}
+		}
+		namedOverrides = append(namedOverrides, rawOverridesItem)
+	}
+	sort.SliceStable(namedOverrides, func(i, j int) bool {
+		return overridesClusterName(namedOverrides[i]) < overridesClusterName(namedOverrides[j])
+	})
+	return unstructured.SetNestedSlice(fedObject.Object, append(namedOverrides, selectorOverrides...), SpecField, OverridesField)
+}
+
+func overridesClusterName(rawOverridesItem interface{}) string {
+	overridesItem, ok := rawOverridesItem.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	clusterName, _ := overridesItem[ClusterNameField].(string)
+	return clusterName
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRemoveUnwantedFields(t *testing.T) {
+	testCases := map[string]struct {
+		targetKind       string
+		template         string
+		expectedTemplate string
+	}{
+		"system metadata is removed": {
+			targetKind:       "Deployment",
+			template:         `{"metadata": {"name": "foo", "namespace": "bar", "uid": "1", "resourceVersion": "2", "labels": {"app": "foo"}}, "spec": {"replicas": 1, "template": {"metadata": {"creationTimestamp": null, "labels": {"app": "foo"}}}}, "status": {"replicas": 1}}`,
+			expectedTemplate: `{"metadata": {"labels": {"app": "foo"}}, "spec": {"replicas": 1, "template": {"metadata": {"labels": {"app": "foo"}}}}}`,
+		},
+		"service account secrets are removed": {
+			targetKind:       ServiceAccountKind,
+			template:         `{"metadata": {}, "secrets": [{"name": "foo-token"}]}`,
+			expectedTemplate: `{"metadata": {}}`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			template := unstructuredFromJSON(t, testCase.template)
+			expectedTemplate := unstructuredFromJSON(t, testCase.expectedTemplate)
+			RemoveUnwantedFields(template.Object, testCase.targetKind)
+			if !reflect.DeepEqual(expectedTemplate, template) {
+				t.Fatalf("Expected %v, got %v", expectedTemplate.Object, template.Object)
+			}
+		})
+	}
+}
+
+func TestDefaultPlacementFromNamespace(t *testing.T) {
+	testCases := map[string]struct {
+		placement          string
+		namespacePlacement string
+		expectedPlacement  string
+	}{
+		"placement is defaulted": {
+			placement:          `{}`,
+			namespacePlacement: `{"clusterNames": ["cluster1"]}`,
+			expectedPlacement:  `{"clusterNames": ["cluster1"]}`,
+		},
+		"placement is retained": {
+			placement:          `{"clusterSelector": {}}`,
+			namespacePlacement: `{"clusterNames": ["cluster1"]}`,
+			expectedPlacement:  `{"clusterSelector": {}}`,
+		},
+		"namespace without placement": {
+			placement:          `{}`,
+			namespacePlacement: `{}`,
+			expectedPlacement:  `{}`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := unstructuredFromJSON(t, `{"metadata": {"name": "foo"}, "spec": {"placement": `+testCase.placement+`}}`)
+			fedNamespace := unstructuredFromJSON(t, `{"metadata": {"name": "bar"}, "spec": {"placement": `+testCase.namespacePlacement+`}}`)
+			expectedObject := unstructuredFromJSON(t, `{"metadata": {"name": "foo"}, "spec": {"placement": `+testCase.expectedPlacement+`}}`)
+			obj, err := DefaultPlacementFromNamespace(fedObject, fedNamespace)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(expectedObject, obj) {
+				t.Fatalf("Expected %v, got %v", expectedObject.Object, obj.Object)
+			}
+		})
+	}
+}
+
+func TestNormalizeOverrides(t *testing.T) {
+	testCases := map[string]struct {
+		overrides         string
+		expectedOverrides string
+	}{
+		"named overrides are sorted": {
+			overrides:         `[{"clusterName": "b"}, {"clusterName": "a"}]`,
+			expectedOverrides: `[{"clusterName": "a"}, {"clusterName": "b"}]`,
+		},
+		"selector overrides follow named overrides in their original order": {
+			overrides:         `[{"clusterSelector": {"z": "1"}}, {"clusterName": "b"}, {"clusterSelector": {"a": "1"}}, {"clusterName": "a"}]`,
+			expectedOverrides: `[{"clusterName": "a"}, {"clusterName": "b"}, {"clusterSelector": {"z": "1"}}, {"clusterSelector": {"a": "1"}}]`,
+		},
+		"cluster overrides are not reordered": {
+			overrides:         `[{"clusterName": "a", "clusterOverrides": [{"path": "spec.b"}, {"path": "spec.a"}]}]`,
+			expectedOverrides: `[{"clusterName": "a", "clusterOverrides": [{"path": "spec.b"}, {"path": "spec.a"}]}]`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := unstructuredFromJSON(t, `{"metadata": {"name": "foo"}, "spec": {"overrides": `+testCase.overrides+`}}`)
+			expectedObject := unstructuredFromJSON(t, `{"metadata": {"name": "foo"}, "spec": {"overrides": `+testCase.expectedOverrides+`}}`)
+			err := NormalizeOverrides(fedObject)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(expectedObject, fedObject) {
+				t.Fatalf("Expected %v, got %v", expectedObject.Object, fedObject.Object)
+			}
+		})
+	}
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
 	finalizersutil "github.com/kubernetes-sigs/federation-v2/pkg/controller/util/finalizers"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -99,11 +100,14 @@ func (dh *DeletionHelper) EnsureFinalizers(obj runtime.Object) (
 
 // Deletes the resources corresponding to the given federated resource from
 // all underlying clusters, unless it has the FinalizerOrphan finalizer.
+// Resources that are not labeled as managed by federation are left alone.
+// If the removal policy is Orphan, resources are unlabeled rather than
+// deleted, and if it is Retain, resources are left unchanged.
 // Removes FinalizerOrphan and FinalizerDeleteFromUnderlyingClusters finalizers
 // when done.
 // Callers are expected to keep calling this (with appropriate backoff) until
 // it succeeds.
-func (dh *DeletionHelper) HandleObjectInUnderlyingClusters(obj runtime.Object, skipDelete func(runtime.Object) bool) (
+func (dh *DeletionHelper) HandleObjectInUnderlyingClusters(obj runtime.Object, removalPolicy util.RemovalPolicy, skipDelete func(runtime.Object) bool) (
 	runtime.Object, error) {
 	objName := dh.objNameFunc(obj)
 	glog.V(2).Infof("Handling deletion of federated dependents for object: %s", objName)
@@ -127,8 +131,12 @@ func (dh *DeletionHelper) HandleObjectInUnderlyingClusters(obj runtime.Object, s
 		finalizers := sets.NewString(FinalizerDeleteFromUnderlyingClusters, metav1.FinalizerOrphanDependents)
 		return dh.removeFinalizers(obj, finalizers)
 	}
+	if removalPolicy == util.RemovalPolicyRetain {
+		glog.V(2).Infof("Retaining obj %s in underlying clusters", objName)
+		return dh.removeFinalizers(obj, sets.NewString(FinalizerDeleteFromUnderlyingClusters))
+	}
 
-	glog.V(2).Infof("Deleting obj %s from underlying clusters", objName)
+	glog.V(2).Infof("Removing obj %s from underlying clusters with policy %s", objName, removalPolicy)
 	// Else, we need to delete the obj from all underlying clusters.
 	unreadyClusters, err := dh.informer.GetUnreadyClusters()
 	if err != nil {
@@ -149,25 +157,45 @@ func (dh *DeletionHelper) HandleObjectInUnderlyingClusters(obj runtime.Object, s
 		if skipDelete(clusterObj) {
 			continue
 		}
-		operations = append(operations, util.FederatedOperation{
+		// Only resources managed by federation are deleted.
+		metaObj, err := meta.Accessor(clusterObj)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get metadata of object %s in cluster %s", objName, clusterNsObj.ClusterName)
+		}
+		if !util.IsManagedByFederation(metaObj) {
+			glog.V(2).Infof("Skipping deletion of obj %s in cluster %s since it is not managed by federation", objName, clusterNsObj.ClusterName)
+			continue
+		}
+		operation := util.FederatedOperation{
 			Type:        util.OperationTypeDelete,
 			ClusterName: clusterNsObj.ClusterName,
 			Obj:         clusterObj,
 			Key:         objName,
-		})
+		}
+		if removalPolicy == util.RemovalPolicyOrphan {
+			operation.Type = util.OperationTypeOrphan
+			operation.Obj, err = util.OrphanedObject(clusterObj)
+			if err == nil {
+				operation.PatchType, operation.Patch, err = util.OrphanPatch()
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to orphan object %s in cluster %s", objName, clusterNsObj.ClusterName)
+			}
+		}
+		operations = append(operations, operation)
 	}
 	_, operationalErrors := dh.updater.Update(operations)
 	if len(operationalErrors) > 0 {
-		return nil, errors.Errorf("failed to execute deletions for obj %s: %v", objName, operationalErrors)
+		return nil, errors.Errorf("failed to execute removals for obj %s: %v", objName, operationalErrors)
 	}
 	if len(operations) > 0 {
-		// We have deleted a bunch of resources.
-		// Wait for the store to observe all the deletions.
+		// We have removed a bunch of resources.
+		// Wait for the store to observe all the removals.
 		var clusterNames []string
 		for _, op := range operations {
 			clusterNames = append(clusterNames, op.ClusterName)
 		}
-		return nil, errors.Errorf("waiting for object %s to be deleted from clusters: %s", objName, strings.Join(clusterNames, ", "))
+		return nil, errors.Errorf("waiting for removal of object %s to be observed in clusters: %s", objName, strings.Join(clusterNames, ", "))
 	}
 
 	// We have now deleted the object from all *ready* clusters.
@@ -178,7 +206,7 @@ func (dh *DeletionHelper) HandleObjectInUnderlyingClusters(obj runtime.Object, s
 		for _, cluster := range unreadyClusters {
 			clusterNames = append(clusterNames, cluster.Name)
 		}
-		return nil, errors.Errorf("waiting for clusters %s to become ready to verify that obj %s has been deleted", strings.Join(clusterNames, ", "), objName)
+		return nil, errors.Errorf("waiting for clusters %s to become ready to verify that obj %s has been removed", strings.Join(clusterNames, ", "), objName)
 	}
 
 	// All done. Just remove the finalizer.
@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	DependsOnField = "dependsOn"
+)
+
+// Dependency identifies a resource that must exist in a member cluster
+// before the target resource of a federated resource is created in
+// that cluster.  The dependency is expected to be in the namespace of
+// the federated resource unless its type is cluster-scoped.
+type Dependency struct {
+	// The API version of the dependency.  If not provided, a type of
+	// any group with the given kind is matched.
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+func (d Dependency) String() string {
+	return fmt.Sprintf("%s %q", d.Kind, d.Name)
+}
+
+// Matches indicates whether the given target type is the type of the
+// dependency.
+func (d Dependency) Matches(apiResource metav1.APIResource) bool {
+	if d.Kind != apiResource.Kind {
+		return false
+	}
+	if len(d.APIVersion) == 0 {
+		return true
+	}
+	gv, err := schema.ParseGroupVersion(d.APIVersion)
+	if err != nil {
+		return false
+	}
+	return gv.Group == apiResource.Group
+}
+
+type GenericDependenciesSpec struct {
+	DependsOn []Dependency `json:"dependsOn,omitempty"`
+}
+
+type GenericDependencies struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GenericDependenciesSpec `json:"spec,omitempty"`
+}
+
+// GetDependencies returns the dependencies of the given federated
+// resource.
+func GetDependencies(fedObject *unstructured.Unstructured) ([]Dependency, error) {
+	dependencies := GenericDependencies{}
+	err := UnstructuredToInterface(fedObject, &dependencies)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error retrieving dependencies")
+	}
+	for _, dependency := range dependencies.Spec.DependsOn {
+		if len(dependency.Kind) == 0 || len(dependency.Name) == 0 {
+			return nil, errors.Errorf("Invalid dependency %v: kind and name are required", dependency)
+		}
+		if len(dependency.APIVersion) > 0 {
+			if _, err := schema.ParseGroupVersion(dependency.APIVersion); err != nil {
+				return nil, errors.Wrapf(err, "Invalid apiVersion for dependency %s", dependency)
+			}
+		}
+	}
+	return dependencies.Spec.DependsOn, nil
+}
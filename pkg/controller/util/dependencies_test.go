@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetDependencies(t *testing.T) {
+	testCases := map[string]struct {
+		spec                 string
+		expectedDependencies []Dependency
+		expectedErr          bool
+	}{
+		"no dependencies": {
+			spec: `{}`,
+		},
+		"dependencies": {
+			spec: `{"dependsOn": [{"kind": "ConfigMap", "name": "config"}, {"apiVersion": "v1", "kind": "Secret", "name": "creds"}]}`,
+			expectedDependencies: []Dependency{
+				{Kind: "ConfigMap", Name: "config"},
+				{APIVersion: "v1", Kind: "Secret", Name: "creds"},
+			},
+		},
+		"missing name": {
+			spec:        `{"dependsOn": [{"kind": "ConfigMap"}]}`,
+			expectedErr: true,
+		},
+		"invalid apiVersion": {
+			spec:        `{"dependsOn": [{"apiVersion": "a/b/c", "kind": "ConfigMap", "name": "config"}]}`,
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := unstructuredFromJSON(t, `{"apiVersion": "core.federation.k8s.io/v1alpha1", "kind": "FederatedDeployment", "metadata": {"name": "foo"}, "spec": `+testCase.spec+`}`)
+			dependencies, err := GetDependencies(fedObject)
+			if testCase.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(testCase.expectedDependencies, dependencies) {
+				t.Fatalf("Expected %v, got %v", testCase.expectedDependencies, dependencies)
+			}
+		})
+	}
+}
+
+func TestDependencyMatches(t *testing.T) {
+	configMaps := metav1.APIResource{Version: "v1", Kind: "ConfigMap", Name: "configmaps"}
+	deployments := metav1.APIResource{Group: "apps", Version: "v1", Kind: "Deployment", Name: "deployments"}
+
+	testCases := map[string]struct {
+		dependency      Dependency
+		apiResource     metav1.APIResource
+		expectedMatches bool
+	}{
+		"kind matches": {
+			dependency:      Dependency{Kind: "ConfigMap", Name: "foo"},
+			apiResource:     configMaps,
+			expectedMatches: true,
+		},
+		"kind differs": {
+			dependency:  Dependency{Kind: "Secret", Name: "foo"},
+			apiResource: configMaps,
+		},
+		"group matches": {
+			dependency:      Dependency{APIVersion: "apps/v1", Kind: "Deployment", Name: "foo"},
+			apiResource:     deployments,
+			expectedMatches: true,
+		},
+		"group differs": {
+			dependency:  Dependency{APIVersion: "extensions/v1beta1", Kind: "Deployment", Name: "foo"},
+			apiResource: deployments,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			if matches := testCase.dependency.Matches(testCase.apiResource); matches != testCase.expectedMatches {
+				t.Fatalf("Expected matches to be %v, got %v", testCase.expectedMatches, matches)
+			}
+		})
+	}
+}
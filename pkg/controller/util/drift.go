@@ -0,0 +1,198 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+const (
+	// DriftPolicyAnnotation overrides the drift policy of the type
+	// for a federated resource.
+	DriftPolicyAnnotation = "federation.k8s.io/drift-policy"
+
+	// CorrectDriftAnnotation requests a one-time correction of drift
+	// for a federated resource whose drift policy is Report.  Drift
+	// is corrected whenever the value of the annotation differs from
+	// the value recorded in the correctedDrift field of the
+	// propagation status.
+	CorrectDriftAnnotation = "federation.k8s.io/correct-drift"
+
+	// The maximum number of drifted fields reported for a cluster.
+	maxDriftedFields = 20
+
+	// The maximum length of a reported field value.
+	maxDriftedValueLength = 256
+)
+
+// DriftedField describes a field of a target resource whose value in a
+// member cluster differs from the desired value.
+type DriftedField struct {
+	// Dot-separated path of the field.
+	Path string `json:"path"`
+	// JSON-encoded desired value.  Empty if the field is not desired.
+	Desired string `json:"desired,omitempty"`
+	// JSON-encoded value in the member cluster.  Empty if the field is
+	// not set.
+	Actual string `json:"actual,omitempty"`
+}
+
+// GetDriftPolicy returns the drift policy for the given federated
+// resource: the value of its drift policy annotation if present, and
+// otherwise the given default policy for the type.
+func GetDriftPolicy(fedObject *unstructured.Unstructured, defaultPolicy fedv1a1.DriftPolicy) (fedv1a1.DriftPolicy, error) {
+	value, ok := fedObject.GetAnnotations()[DriftPolicyAnnotation]
+	if !ok {
+		return defaultPolicy, nil
+	}
+	policy := fedv1a1.DriftPolicy(value)
+	if policy != fedv1a1.DriftPolicyCorrect && policy != fedv1a1.DriftPolicyReport {
+		return "", errors.Errorf("Invalid value %q for annotation %q: must be %q or %q", value, DriftPolicyAnnotation, fedv1a1.DriftPolicyCorrect, fedv1a1.DriftPolicyReport)
+	}
+	return policy, nil
+}
+
+// DriftedFields returns the fields of the cluster object whose values
+// differ from those of the desired object, sorted by path and limited
+// to a maximum of 20 fields.  Only the fields set in the desired
+// object and the labels and annotations of the cluster object are
+// compared, so fields defaulted or populated in the member cluster are
+// not considered drift.  Status and metadata other than labels and
+// annotations are ignored.
+func DriftedFields(desiredObj, clusterObj *unstructured.Unstructured) ([]DriftedField, error) {
+	desired, err := normalizedDriftObject(desiredObj)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := normalizedDriftObject(clusterObj)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []DriftedField{}
+	for _, key := range sortedKeys(desired) {
+		fields = appendDriftedFields(fields, key, desired[key], actual[key])
+	}
+	for _, key := range sortedKeys(actual) {
+		if _, ok := desired[key]; !ok {
+			fields = appendDriftedFields(fields, key, nil, actual[key])
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Path < fields[j].Path
+	})
+	if len(fields) > maxDriftedFields {
+		fields = fields[:maxDriftedFields]
+	}
+	return fields, nil
+}
+
+// normalizedDriftObject returns a JSON-normalized map of the fields of
+// the given object that are compared for drift.  Labels and
+// annotations are keyed by their full path so that the rest of the
+// metadata is ignored.
+func normalizedDriftObject(obj *unstructured.Unstructured) (map[string]interface{}, error) {
+	content, err := json.Marshal(obj.Object)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling object")
+	}
+	fieldMap := make(map[string]interface{})
+	err = json.Unmarshal(content, &fieldMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error unmarshalling object")
+	}
+	for _, field := range []string{"apiVersion", "kind", MetadataField, StatusField} {
+		delete(fieldMap, field)
+	}
+	labels := obj.GetLabels()
+	if len(labels) > 0 {
+		fieldMap["metadata.labels"] = stringMapToInterface(labels)
+	}
+	annotations := obj.GetAnnotations()
+	delete(annotations, LastAppliedConfigurationAnnotation)
+	if len(annotations) > 0 {
+		fieldMap["metadata.annotations"] = stringMapToInterface(annotations)
+	}
+	return fieldMap, nil
+}
+
+func stringMapToInterface(m map[string]string) map[string]interface{} {
+	result := make(map[string]interface{})
+	for key, value := range m {
+		result[key] = value
+	}
+	return result
+}
+
+func appendDriftedFields(fields []DriftedField, path string, desired, actual interface{}) []DriftedField {
+	desiredMap, desiredIsMap := desired.(map[string]interface{})
+	actualMap, actualIsMap := actual.(map[string]interface{})
+	// Labels and annotations are compared in their entirety since
+	// they are not expected to be added in the member cluster.
+	compareAll := strings.HasPrefix(path, "metadata.")
+	if desiredIsMap && actualIsMap && !compareAll {
+		for _, key := range sortedKeys(desiredMap) {
+			fields = appendDriftedFields(fields, path+"."+key, desiredMap[key], actualMap[key])
+		}
+		return fields
+	}
+	if reflect.DeepEqual(desired, actual) {
+		return fields
+	}
+	if desired == nil && !compareAll {
+		// Fields that are only set in the member cluster are not
+		// considered drift.
+		return fields
+	}
+	return append(fields, DriftedField{
+		Path:    path,
+		Desired: encodeDriftedValue(desired),
+		Actual:  encodeDriftedValue(actual),
+	})
+}
+
+func encodeDriftedValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	content, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	if len(content) > maxDriftedValueLength {
+		return string(content[:maxDriftedValueLength]) + "..."
+	}
+	return string(content)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
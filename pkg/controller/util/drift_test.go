@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+func TestDriftedFields(t *testing.T) {
+	const desired = `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "foo", "labels": {"app": "foo"}}, "data": {"a": "1", "b": "2"}}`
+
+	testCases := map[string]struct {
+		clusterObj     string
+		expectedFields []DriftedField
+	}{
+		"no drift": {
+			clusterObj:     `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "foo", "labels": {"app": "foo"}, "resourceVersion": "3", "uid": "abc"}, "data": {"a": "1", "b": "2"}}`,
+			expectedFields: []DriftedField{},
+		},
+		"field set only in the cluster is ignored": {
+			clusterObj:     `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "foo", "labels": {"app": "foo"}}, "data": {"a": "1", "b": "2", "c": "3"}}`,
+			expectedFields: []DriftedField{},
+		},
+		"changed and removed fields": {
+			clusterObj: `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "foo", "labels": {"app": "foo"}}, "data": {"a": "2"}}`,
+			expectedFields: []DriftedField{
+				{Path: "data.a", Desired: `"1"`, Actual: `"2"`},
+				{Path: "data.b", Desired: `"2"`},
+			},
+		},
+		"added label": {
+			clusterObj: `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "foo", "labels": {"app": "foo", "extra": "true"}}, "data": {"a": "1", "b": "2"}}`,
+			expectedFields: []DriftedField{
+				{Path: "metadata.labels", Desired: `{"app":"foo"}`, Actual: `{"app":"foo","extra":"true"}`},
+			},
+		},
+		"added annotation": {
+			clusterObj: `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "foo", "labels": {"app": "foo"}, "annotations": {"extra": "true"}}, "data": {"a": "1", "b": "2"}}`,
+			expectedFields: []DriftedField{
+				{Path: "metadata.annotations", Actual: `{"extra":"true"}`},
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fields, err := DriftedFields(unstructuredFromJSON(t, desired), unstructuredFromJSON(t, testCase.clusterObj))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(testCase.expectedFields, fields) {
+				t.Fatalf("Expected %v, got %v", testCase.expectedFields, fields)
+			}
+		})
+	}
+}
+
+func TestGetDriftPolicy(t *testing.T) {
+	testCases := map[string]struct {
+		annotation     string
+		expectedPolicy fedv1a1.DriftPolicy
+		expectedErr    bool
+	}{
+		"default policy": {
+			expectedPolicy: fedv1a1.DriftPolicyCorrect,
+		},
+		"annotation overrides default": {
+			annotation:     `"Report"`,
+			expectedPolicy: fedv1a1.DriftPolicyReport,
+		},
+		"invalid annotation": {
+			annotation:  `"Ignore"`,
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			content := `{"metadata": {"name": "foo"}}`
+			if len(testCase.annotation) > 0 {
+				content = `{"metadata": {"name": "foo", "annotations": {"federation.k8s.io/drift-policy": ` + testCase.annotation + `}}}`
+			}
+			policy, err := GetDriftPolicy(unstructuredFromJSON(t, content), fedv1a1.DriftPolicyCorrect)
+			if testCase.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if policy != testCase.expectedPolicy {
+				t.Fatalf("Expected policy %q, got %q", testCase.expectedPolicy, policy)
+			}
+		})
+	}
+}
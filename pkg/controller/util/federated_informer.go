@@ -31,7 +31,6 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	pkgruntime "k8s.io/apimachinery/pkg/runtime"
-	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 )
 
@@ -142,22 +141,40 @@ func NewFederatedInformer(
 	targetInformerFactory := func(cluster *fedv1a1.FederatedCluster, client ResourceClient) (cache.Store, cache.Controller) {
 		return NewResourceInformer(client, config.TargetNamespace, triggerFunc)
 	}
+	return newFederatedInformer(config, client, apiResource, targetInformerFactory, clusterLifecycle)
+}
+
+// NewTrimmedFederatedInformer builds a FederatedInformer whose target
+// informers cache only the resources labeled as managed by federation,
+// trimmed by the given function.
+func NewTrimmedFederatedInformer(
+	config *ControllerConfig,
+	client generic.Client,
+	apiResource *metav1.APIResource,
+	trimFunc TrimFunc,
+	triggerFunc func(pkgruntime.Object),
+	clusterLifecycle *ClusterLifecycleHandlerFuncs) (FederatedInformer, error) {
+
+	targetInformerFactory := func(cluster *fedv1a1.FederatedCluster, client ResourceClient) (cache.Store, cache.Controller) {
+		return NewManagedResourceInformer(client, config.TargetNamespace, trimFunc, triggerFunc)
+	}
+	return newFederatedInformer(config, client, apiResource, targetInformerFactory, clusterLifecycle)
+}
+
+func newFederatedInformer(
+	config *ControllerConfig,
+	client generic.Client,
+	apiResource *metav1.APIResource,
+	targetInformerFactory TargetInformerFactory,
+	clusterLifecycle *ClusterLifecycleHandlerFuncs) (FederatedInformer, error) {
 
 	federatedInformer := &federatedInformerImpl{
 		targetInformerFactory: targetInformerFactory,
 		clientFactory: func(cluster *fedv1a1.FederatedCluster) (ResourceClient, error) {
-			config, err := BuildClusterConfig(cluster, client, config.FederationNamespace, config.ClusterNamespace)
-			if err != nil {
-				return nil, err
-			}
-			if config == nil {
-				return nil, errors.Errorf("Unable to load configuration for cluster %q", cluster.Name)
-			}
-
-			restclient.AddUserAgent(config, userAgentName)
-			return NewResourceClient(config, apiResource)
+			return NewClusterResourceClient(cluster, client, config.FederationNamespace, config.ClusterNamespace, apiResource)
 		},
 		targetInformers: make(map[string]informer),
+		clients:         make(map[string]ResourceClient),
 		fedNamespace:    config.FederationNamespace,
 	}
 
@@ -231,6 +248,13 @@ func NewFederatedInformer(
 							clusterLifecycle.ClusterAvailable(curCluster)
 						}
 					}
+				} else if IsClusterReady(curCluster) && clusterMetadataChanged(oldCluster, curCluster) {
+					// Placement and overrides may depend on the labels,
+					// region and zone of a cluster, but a change to them
+					// does not require the informer to be restarted.
+					if clusterLifecycle.ClusterAvailable != nil {
+						clusterLifecycle.ClusterAvailable(curCluster)
+					}
 				} else {
 					glog.V(4).Infof("Cluster %v not updated to %v as ready status and specs are identical", oldCluster, curCluster)
 				}
@@ -240,6 +264,14 @@ func NewFederatedInformer(
 	return federatedInformer, err
 }
 
+// clusterMetadataChanged indicates whether the labels, region or zone
+// of the given cluster have changed.
+func clusterMetadataChanged(oldCluster, curCluster *fedv1a1.FederatedCluster) bool {
+	return !reflect.DeepEqual(oldCluster.Labels, curCluster.Labels) ||
+		oldCluster.Status.Region != curCluster.Status.Region ||
+		oldCluster.Status.Zone != curCluster.Status.Zone
+}
+
 func IsClusterReady(cluster *fedv1a1.FederatedCluster) bool {
 	for _, condition := range cluster.Status.Conditions {
 		if condition.Type == fedcommon.ClusterReady {
@@ -272,6 +304,10 @@ type federatedInformerImpl struct {
 	// A function to build clients.
 	clientFactory func(*fedv1a1.FederatedCluster) (ResourceClient, error)
 
+	// Clients for ready clusters, built on first use and discarded
+	// when a cluster is removed.
+	clients map[string]ResourceClient
+
 	// Namespace from which to source FederatedCluster resources
 	fedNamespace string
 }
@@ -313,6 +349,7 @@ func (f *federatedInformerImpl) SetClientFactory(clientFactory func(*fedv1a1.Fed
 	defer f.Unlock()
 
 	f.clientFactory = clientFactory
+	f.clients = make(map[string]ResourceClient)
 }
 
 // GetClientForCluster returns a client for the cluster, if present.
@@ -327,7 +364,15 @@ func (f *federatedInformerImpl) getClientForClusterUnlocked(clusterName string)
 	glog.V(4).Infof("Getting clientset for cluster %q", clusterName)
 	if cluster, found, err := f.getReadyClusterUnlocked(clusterName); found && err == nil {
 		glog.V(4).Infof("Got clientset for cluster %q", clusterName)
-		return f.clientFactory(cluster)
+		if client, ok := f.clients[clusterName]; ok {
+			return client, nil
+		}
+		client, err := f.clientFactory(cluster)
+		if err != nil {
+			return nil, err
+		}
+		f.clients[clusterName] = client
+		return client, nil
 	} else {
 		if err != nil {
 			return nil, err
@@ -431,6 +476,7 @@ func (f *federatedInformerImpl) deleteCluster(cluster *fedv1a1.FederatedCluster)
 		close(targetInformer.stopChan)
 	}
 	delete(f.targetInformers, name)
+	delete(f.clients, name)
 }
 
 // Returns a store created over all stores from target informers.
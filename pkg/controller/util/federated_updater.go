@@ -27,6 +27,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 )
 
@@ -37,8 +38,16 @@ const (
 	OperationTypeAdd    = "add"
 	OperationTypeUpdate = "update"
 	OperationTypeDelete = "delete"
+	// OperationTypeOrphan updates an object so that it is no longer
+	// managed by federation.  Obj should have been computed by
+	// OrphanedObject, and the object is patched with Patch if set.
+	OperationTypeOrphan = "orphan"
 )
 
+// ErrOperationTimeout is the cause of the error returned for an
+// operation that did not complete within the timeout of the updater.
+var ErrOperationTimeout = errors.New("operation timed out")
+
 type operationResult struct {
 	clusterName string
 	version     string
@@ -51,17 +60,25 @@ type FederatedOperation struct {
 	ClusterName string
 	Obj         pkgruntime.Object
 	Key         string
+	// If set, an update or orphan operation patches the object with
+	// Patch rather than replacing it with Obj.
+	PatchType types.PatchType
+	Patch     []byte
 }
 
 // A helper that executes the given set of updates on federation, in parallel.
 type FederatedUpdater interface {
-	// Executes the given set of operations.
-	Update([]FederatedOperation) (map[string]string, []error)
+	// Executes the given set of operations and returns the resulting
+	// versions and errors keyed by cluster name.
+	Update([]FederatedOperation) (map[string]string, map[string]error)
 }
 
 // A function that executes some operation using the passed client and object.
 type FederatedOperationHandler func(ResourceClient, pkgruntime.Object) (string, error)
 
+// A function that patches the passed object using the passed client.
+type FederatedPatchHandler func(ResourceClient, pkgruntime.Object, types.PatchType, []byte) (string, error)
+
 type federatedUpdaterImpl struct {
 	federation FederationView
 
@@ -69,22 +86,34 @@ type federatedUpdaterImpl struct {
 
 	timeout time.Duration
 
+	// The number of operations that may be executed concurrently.
+	// Not limited if zero.
+	maxConcurrency int
+
+	// Limits the rate of writes to each cluster.
+	rateLimiter *ClusterRateLimiter
+
 	eventRecorder record.EventRecorder
 
 	addFunction    FederatedOperationHandler
 	updateFunction FederatedOperationHandler
 	deleteFunction FederatedOperationHandler
+	patchFunction  FederatedPatchHandler
 }
 
-func NewFederatedUpdater(federation FederationView, kind string, timeout time.Duration, recorder record.EventRecorder, add, update, del FederatedOperationHandler) FederatedUpdater {
+func NewFederatedUpdater(federation FederationView, kind string, timeout time.Duration, maxConcurrency int, rateLimiter *ClusterRateLimiter,
+	recorder record.EventRecorder, add, update, del FederatedOperationHandler, patch FederatedPatchHandler) FederatedUpdater {
 	return &federatedUpdaterImpl{
 		federation:     federation,
 		kind:           kind,
 		timeout:        timeout,
+		maxConcurrency: maxConcurrency,
+		rateLimiter:    rateLimiter,
 		eventRecorder:  recorder,
 		addFunction:    add,
 		updateFunction: update,
 		deleteFunction: del,
+		patchFunction:  patch,
 	}
 }
 
@@ -99,67 +128,46 @@ func (fu *federatedUpdaterImpl) recordEvent(obj runtime.Object, eventType, reaso
 // Update executes the given set of operations within the timeout specified for
 // the instance. Timeout is best-effort. There is no guarantee that the
 // underlying operations are stopped when it is reached. However the function
-// will return after the timeout with a non-nil error.
-func (fu *federatedUpdaterImpl) Update(ops []FederatedOperation) (map[string]string, []error) {
+// will return after the timeout with an error whose cause is
+// ErrOperationTimeout for each operation that has not completed.
+// Operations are executed concurrently, up to the limit specified for
+// the instance, and writes to each cluster are subject to its rate
+// limiter.
+func (fu *federatedUpdaterImpl) Update(ops []FederatedOperation) (map[string]string, map[string]error) {
 	done := make(chan operationResult, len(ops))
+	start := time.Now()
+
+	// Operations are executed by a bounded number of workers.  Workers
+	// stop picking up operations once the timeout has been reached.
+	pending := make(chan FederatedOperation, len(ops))
 	for _, op := range ops {
-		go func(op FederatedOperation) {
-			clusterName := op.ClusterName
-
-			// TODO: Ensure that the client has reasonable timeout.
-			client, err := fu.federation.GetClientForCluster(clusterName)
-			if err != nil {
-				done <- operationResult{err: err}
-				return
-			}
+		pending <- op
+	}
+	close(pending)
+	stop := make(chan struct{})
+	defer close(stop)
 
-			eventArgs := []interface{}{fu.kind, op.Key, clusterName}
-			baseEventType := fmt.Sprintf("%s", op.Type)
-			eventType := fmt.Sprintf("%sInCluster", strings.Title(baseEventType))
-
-			version := ""
-
-			switch op.Type {
-			case OperationTypeAdd:
-				// TODO s+OperationTypeAdd+OperationTypeCreate+
-				baseEventType = "create"
-				eventType := "CreateInCluster"
-
-				fu.recordEvent(op.Obj, apiv1.EventTypeNormal, eventType, "Creating", eventArgs...)
-				version, err = fu.addFunction(client, op.Obj)
-			case OperationTypeUpdate:
-				fu.recordEvent(op.Obj, apiv1.EventTypeNormal, eventType, "Updating", eventArgs...)
-				version, err = fu.updateFunction(client, op.Obj)
-			case OperationTypeDelete:
-				fu.recordEvent(op.Obj, apiv1.EventTypeNormal, eventType, "Deleting", eventArgs...)
-				_, err = fu.deleteFunction(client, op.Obj)
-				// IsNotFound error is fine since that means the object is deleted already.
-				if apierrors.IsNotFound(err) {
-					err = nil
+	workers := len(ops)
+	if fu.maxConcurrency > 0 && fu.maxConcurrency < workers {
+		workers = fu.maxConcurrency
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for op := range pending {
+				select {
+				case <-stop:
+					return
+				default:
 				}
+				done <- fu.executeOperation(op, start.Add(fu.timeout).Sub(time.Now()))
 			}
-
-			if err != nil {
-				eventType := eventType + "Failed"
-				messageFmt := "Failed to " + baseEventType + " %s %q in cluster %s: %v"
-				eventArgs = append(eventArgs, err)
-				err = errors.Errorf(messageFmt, eventArgs...)
-				fu.recordEvent(op.Obj, apiv1.EventTypeWarning, eventType, messageFmt, eventArgs...)
-			}
-
-			done <- operationResult{
-				clusterName: clusterName,
-				version:     version,
-				err:         err,
-			}
-		}(op)
+		}()
 	}
 
 	versions := make(map[string]string)
-	updateErrs := []error{}
+	updateErrs := make(map[string]error)
 	timedOut := false
 
-	start := time.Now()
 	for i := 0; i < len(ops); i++ {
 		now := time.Now()
 		if !now.Before(start.Add(fu.timeout)) {
@@ -169,7 +177,7 @@ func (fu *federatedUpdaterImpl) Update(ops []FederatedOperation) (map[string]str
 		select {
 		case result := <-done:
 			if result.err != nil {
-				updateErrs = append(updateErrs, result.err)
+				updateErrs[result.clusterName] = result.err
 				break
 			}
 			versions[result.clusterName] = result.version
@@ -180,8 +188,93 @@ func (fu *federatedUpdaterImpl) Update(ops []FederatedOperation) (map[string]str
 	}
 
 	if timedOut {
-		updateErrs = append(updateErrs, errors.Errorf("Failed to finish all operations in %v", fu.timeout))
+		for _, op := range ops {
+			if _, ok := versions[op.ClusterName]; ok {
+				continue
+			}
+			if _, ok := updateErrs[op.ClusterName]; ok {
+				continue
+			}
+			updateErrs[op.ClusterName] = errors.Wrapf(ErrOperationTimeout, "Failed to finish %s of %s %q in cluster %s within %v",
+				op.Type, fu.kind, op.Key, op.ClusterName, fu.timeout)
+		}
 	}
 
 	return versions, updateErrs
 }
+
+// executeOperation executes the given operation once a write to its
+// cluster is allowed by the rate limiter.  An operation that would not
+// be allowed within the remaining time fails with a timeout.
+func (fu *federatedUpdaterImpl) executeOperation(op FederatedOperation, remaining time.Duration) operationResult {
+	clusterName := op.ClusterName
+
+	if !fu.rateLimiter.Wait(clusterName, remaining) {
+		return operationResult{
+			clusterName: clusterName,
+			err: errors.Wrapf(ErrOperationTimeout, "Rate limit for cluster %s prevented %s of %s %q within %v",
+				clusterName, op.Type, fu.kind, op.Key, fu.timeout),
+		}
+	}
+
+	// TODO: Ensure that the client has reasonable timeout.
+	client, err := fu.federation.GetClientForCluster(clusterName)
+	if err != nil {
+		return operationResult{clusterName: clusterName, err: err}
+	}
+
+	eventArgs := []interface{}{fu.kind, op.Key, clusterName}
+	baseEventType := fmt.Sprintf("%s", op.Type)
+	eventType := fmt.Sprintf("%sInCluster", strings.Title(baseEventType))
+
+	version := ""
+
+	switch op.Type {
+	case OperationTypeAdd:
+		// TODO s+OperationTypeAdd+OperationTypeCreate+
+		baseEventType = "create"
+		eventType := "CreateInCluster"
+
+		fu.recordEvent(op.Obj, apiv1.EventTypeNormal, eventType, "Creating", eventArgs...)
+		version, err = fu.addFunction(client, op.Obj)
+	case OperationTypeUpdate:
+		fu.recordEvent(op.Obj, apiv1.EventTypeNormal, eventType, "Updating", eventArgs...)
+		if op.Patch != nil {
+			version, err = fu.patchFunction(client, op.Obj, op.PatchType, op.Patch)
+		} else {
+			version, err = fu.updateFunction(client, op.Obj)
+		}
+	case OperationTypeDelete:
+		fu.recordEvent(op.Obj, apiv1.EventTypeNormal, eventType, "Deleting", eventArgs...)
+		_, err = fu.deleteFunction(client, op.Obj)
+		// IsNotFound error is fine since that means the object is deleted already.
+		if apierrors.IsNotFound(err) {
+			err = nil
+		}
+	case OperationTypeOrphan:
+		fu.recordEvent(op.Obj, apiv1.EventTypeNormal, eventType, "Orphaning", eventArgs...)
+		if op.Patch != nil {
+			_, err = fu.patchFunction(client, op.Obj, op.PatchType, op.Patch)
+		} else {
+			_, err = fu.updateFunction(client, op.Obj)
+		}
+		// An object that no longer exists does not need to be orphaned.
+		if apierrors.IsNotFound(err) {
+			err = nil
+		}
+	}
+
+	if err != nil {
+		eventType := eventType + "Failed"
+		messageFmt := "Failed to " + baseEventType + " %s %q in cluster %s: %v"
+		eventArgs = append(eventArgs, err)
+		err = errors.Errorf(messageFmt, eventArgs...)
+		fu.recordEvent(op.Obj, apiv1.EventTypeWarning, eventType, messageFmt, eventArgs...)
+	}
+
+	return operationResult{
+		clusterName: clusterName,
+		version:     version,
+		err:         err,
+	}
+}
@@ -17,19 +17,110 @@ limitations under the License.
 package util
 
 import (
+	"sort"
+
+	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// The fields of the status collected from member clusters.  The
+// fields are written to a separate status object or, if the status
+// mode of the type is Subresource, to the status of the federated
+// resource.
+const (
+	ClusterStatusField    = "clusterStatus"
+	AggregatedStatusField = "aggregatedStatus"
+	ConditionsField       = "conditions"
+)
+
 // FederatedResource is a generic representation of a federated type
 type FederatedResource struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	ClusterStatus []ResourceClusterStatus `json:"clusterStatus,omitempty"`
+	// The status aggregated across clusters if the type config
+	// specifies a status aggregator.
+	AggregatedStatus interface{} `json:"aggregatedStatus,omitempty"`
 }
 
+type ResourceClusterState string
+
+const (
+	// The resource exists in the cluster and its status is reported.
+	ResourceClusterStatePresent ResourceClusterState = "Present"
+	// The resource exists in the cluster but does not have a status.
+	ResourceClusterStateNoStatus ResourceClusterState = "NoStatus"
+	// The resource should exist in the cluster according to the
+	// propagation status of the federated resource but was not found.
+	ResourceClusterStateMissing ResourceClusterState = "Missing"
+	// The resource is not placed in the cluster and was not found.
+	ResourceClusterStateNotPlaced ResourceClusterState = "NotPlaced"
+	// The cluster is not ready.  The last known status of the
+	// resource in the cluster, if any, is retained.
+	ResourceClusterStateClusterNotReady ResourceClusterState = "ClusterNotReady"
+)
+
 // ResourceClusterStatus defines the status of federated resource within a cluster
 type ResourceClusterStatus struct {
 	ClusterName string                 `json:"clusterName,omitempty"`
+	State       ResourceClusterState   `json:"state,omitempty"`
 	Status      map[string]interface{} `json:"status,omitempty"`
+	// The time at which the status was last observed to change in
+	// the cluster.  Only set for a resource that exists in the
+	// cluster, and retained with the last known status while the
+	// cluster is not ready.
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+type AggregatedStatusConditionType string
+
+const (
+	// The resource is healthy in every cluster that reports a status
+	// for it.  The condition is false if the resource is degraded in
+	// at least one cluster.
+	AggregatedStatusConditionHealthy AggregatedStatusConditionType = "Healthy"
+)
+
+// AggregatedStatusCondition describes an aspect of the status of a
+// federated resource aggregated across clusters.
+type AggregatedStatusCondition struct {
+	Type    AggregatedStatusConditionType `json:"type"`
+	Status  apiv1.ConditionStatus         `json:"status"`
+	Reason  string                        `json:"reason,omitempty"`
+	Message string                        `json:"message,omitempty"`
+}
+
+// SetStatusConditions replaces the conditions in the given status
+// whose types are owned by the caller with the given conditions.
+// Propagation conditions are ordered before other conditions so that
+// the order does not depend on which controller last wrote the
+// status.
+func SetStatusConditions(status map[string]interface{}, conditions []interface{}, owned func(conditionType string) bool) {
+	merged := []interface{}{}
+	existing, _ := status[ConditionsField].([]interface{})
+	for _, condition := range existing {
+		if !owned(statusConditionType(condition)) {
+			merged = append(merged, condition)
+		}
+	}
+	merged = append(merged, conditions...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return IsPropagationCondition(statusConditionType(merged[i])) &&
+			!IsPropagationCondition(statusConditionType(merged[j]))
+	})
+	if len(merged) == 0 {
+		delete(status, ConditionsField)
+		return
+	}
+	status[ConditionsField] = merged
+}
+
+func statusConditionType(condition interface{}) string {
+	conditionMap, ok := condition.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	conditionType, _ := conditionMap["type"].(string)
+	return conditionType
 }
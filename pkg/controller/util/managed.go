@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+const (
+	// ManagedByFederationLabel is applied to every resource propagated
+	// to a member cluster.  Only resources with the label are updated
+	// or deleted by federation without regard to the conflict policy.
+	ManagedByFederationLabel      = "federation.k8s.io/managed"
+	ManagedByFederationLabelValue = "true"
+
+	// ConflictPolicyAnnotation overrides the conflict policy of the
+	// type for a federated resource.
+	ConflictPolicyAnnotation = "federation.k8s.io/conflict-policy"
+
+	// PausedAnnotation pauses propagation of a federated resource, or
+	// of every federated resource in the namespace of a
+	// FederatedNamespace, when set to "true".
+	PausedAnnotation = "federation.k8s.io/paused"
+)
+
+// IsManagedByFederation indicates whether the given resource in a
+// member cluster is labeled as managed by federation.
+func IsManagedByFederation(obj metav1.Object) bool {
+	return obj.GetLabels()[ManagedByFederationLabel] == ManagedByFederationLabelValue
+}
+
+// AddManagedByFederationLabel labels the given resource as managed by
+// federation.
+func AddManagedByFederationLabel(obj metav1.Object) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[ManagedByFederationLabel] = ManagedByFederationLabelValue
+	obj.SetLabels(labels)
+}
+
+// IsPaused indicates whether propagation is paused by the given
+// federated resource.
+func IsPaused(fedObject *unstructured.Unstructured) bool {
+	return fedObject != nil && fedObject.GetAnnotations()[PausedAnnotation] == "true"
+}
+
+// GetConflictPolicy returns the conflict policy for the given
+// federated resource: the value of its conflict policy annotation if
+// present, and otherwise the given default policy for the type.
+func GetConflictPolicy(fedObject *unstructured.Unstructured, defaultPolicy fedv1a1.ConflictPolicy) (fedv1a1.ConflictPolicy, error) {
+	value, ok := fedObject.GetAnnotations()[ConflictPolicyAnnotation]
+	if !ok {
+		return defaultPolicy, nil
+	}
+	policy := fedv1a1.ConflictPolicy(value)
+	if !IsValidConflictPolicy(policy) {
+		return "", errors.Errorf("Invalid value %q for annotation %q: must be %q, %q or %q", value, ConflictPolicyAnnotation,
+			fedv1a1.ConflictPolicyAdopt, fedv1a1.ConflictPolicySkip, fedv1a1.ConflictPolicyFail)
+	}
+	return policy, nil
+}
+
+// IsValidConflictPolicy indicates whether the given conflict policy is
+// supported.
+func IsValidConflictPolicy(policy fedv1a1.ConflictPolicy) bool {
+	switch policy {
+	case fedv1a1.ConflictPolicyAdopt, fedv1a1.ConflictPolicySkip, fedv1a1.ConflictPolicyFail:
+		return true
+	}
+	return false
+}
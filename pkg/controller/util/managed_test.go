@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+func TestManagedByFederationLabel(t *testing.T) {
+	obj := unstructuredFromJSON(t, `{"metadata": {"name": "foo", "labels": {"app": "foo"}}}`)
+	if IsManagedByFederation(obj) {
+		t.Fatalf("Expected an unlabeled resource not to be managed")
+	}
+	AddManagedByFederationLabel(obj)
+	if !IsManagedByFederation(obj) {
+		t.Fatalf("Expected a labeled resource to be managed")
+	}
+	if obj.GetLabels()["app"] != "foo" {
+		t.Fatalf("Expected existing labels to be retained, got %v", obj.GetLabels())
+	}
+
+	obj = unstructuredFromJSON(t, `{"metadata": {"name": "foo", "labels": {"federation.k8s.io/managed": "false"}}}`)
+	if IsManagedByFederation(obj) {
+		t.Fatalf("Expected a resource labeled with an unexpected value not to be managed")
+	}
+}
+
+func TestGetConflictPolicy(t *testing.T) {
+	testCases := map[string]struct {
+		annotation     string
+		expectedPolicy fedv1a1.ConflictPolicy
+		expectedErr    bool
+	}{
+		"default policy": {
+			expectedPolicy: fedv1a1.ConflictPolicyAdopt,
+		},
+		"annotation overrides default": {
+			annotation:     `"Skip"`,
+			expectedPolicy: fedv1a1.ConflictPolicySkip,
+		},
+		"invalid annotation": {
+			annotation:  `"Ignore"`,
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			content := `{"metadata": {"name": "foo"}}`
+			if len(testCase.annotation) > 0 {
+				content = `{"metadata": {"name": "foo", "annotations": {"federation.k8s.io/conflict-policy": ` + testCase.annotation + `}}}`
+			}
+			policy, err := GetConflictPolicy(unstructuredFromJSON(t, content), fedv1a1.ConflictPolicyAdopt)
+			if testCase.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if policy != testCase.expectedPolicy {
+				t.Fatalf("Expected policy %q, got %q", testCase.expectedPolicy, policy)
+			}
+		})
+	}
+}
@@ -18,22 +18,59 @@ package util
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+// The RFC 6902 operations supported by overrides.  An override that
+// does not specify an operation sets a value at a dotted path.
+const (
+	OverrideOpAdd     = "add"
+	OverrideOpRemove  = "remove"
+	OverrideOpReplace = "replace"
 )
 
+var validOverrideOps = sets.NewString(
+	OverrideOpAdd,
+	OverrideOpRemove,
+	OverrideOpReplace,
+)
+
+// ClusterOverride defines a single override of the template for a
+// cluster.
+//
+// If Op is not provided, Path is a dotted path (e.g. spec.replicas)
+// and Value will be set at that path.  If Op is provided, Path is a
+// JSON Pointer (e.g. /spec/template/spec/containers/0/image) and the
+// override is applied as an RFC 6902 operation.
 type ClusterOverride struct {
+	Op    string      `json:"op,omitempty"`
 	Path  string      `json:"path"`
 	Value interface{} `json:"value"`
 }
 
+// GenericOverrideItem defines the overrides for either the cluster
+// with the given name or the clusters whose labels match the given
+// selector.  Exactly one of ClusterName and ClusterSelector must be
+// provided.
 type GenericOverrideItem struct {
-	ClusterName      string            `json:"clusterName"`
-	ClusterOverrides []ClusterOverride `json:"clusterOverrides,omitempty"`
+	ClusterName      string                `json:"clusterName,omitempty"`
+	ClusterSelector  *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+	ClusterOverrides []ClusterOverride     `json:"clusterOverrides,omitempty"`
+	// A strategic merge patch to apply to the template before
+	// applying ClusterOverrides.  Target types that are not known to
+	// the controller (e.g. CRDs) are patched as per RFC 7386.
+	StrategicMergePatch map[string]interface{} `json:"strategicMergePatch,omitempty"`
 }
 
 type GenericOverrideSpec struct {
@@ -59,77 +96,317 @@ var invalidPaths = sets.NewString(
 // Mapping of qualified path (e.g. spec.replicas) to value
 type ClusterOverridesMap map[string]interface{}
 
+// ClusterOverrides holds the overrides for a single cluster in the
+// order they should be applied.
+type ClusterOverrides struct {
+	StrategicMergePatch map[string]interface{}
+	Overrides           []ClusterOverride
+}
+
+// Value returns the value of the dotted-path override for the given
+// path.
+func (o *ClusterOverrides) Value(path string) (interface{}, bool) {
+	for _, override := range o.Overrides {
+		if len(override.Op) == 0 && override.Path == path {
+			return override.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Values returns the mapping of path to value for the dotted-path
+// overrides.
+func (o *ClusterOverrides) Values() ClusterOverridesMap {
+	values := make(ClusterOverridesMap)
+	for _, override := range o.Overrides {
+		if len(override.Op) == 0 {
+			values[override.Path] = override.Value
+		}
+	}
+	return values
+}
+
+// SetValue sets the value of the dotted-path override for the given
+// path, adding the override if it does not already exist.
+func (o *ClusterOverrides) SetValue(path string, value interface{}) {
+	for i, override := range o.Overrides {
+		if len(override.Op) == 0 && override.Path == path {
+			o.Overrides[i].Value = value
+			return
+		}
+	}
+	o.Overrides = append(o.Overrides, ClusterOverride{Path: path, Value: value})
+}
+
+// RemoveValue removes the dotted-path override for the given path.
+func (o *ClusterOverrides) RemoveValue(path string) {
+	overrides := []ClusterOverride{}
+	for _, override := range o.Overrides {
+		if len(override.Op) == 0 && override.Path == path {
+			continue
+		}
+		overrides = append(overrides, override)
+	}
+	o.Overrides = overrides
+}
+
 // Mapping of clusterName to overrides for the cluster
-type OverridesMap map[string]ClusterOverridesMap
+type OverridesMap map[string]*ClusterOverrides
+
+// SelectorOverrides holds the overrides for the clusters matching a
+// label selector.
+type SelectorOverrides struct {
+	ClusterOverrides
+
+	// The selector as defined in the federated resource
+	LabelSelector *metav1.LabelSelector
+	// The parsed form of LabelSelector
+	ClusterSelector labels.Selector
+}
+
+// Matches indicates whether the overrides apply to the given cluster.
+func (o *SelectorOverrides) Matches(cluster *fedv1a1.FederatedCluster) bool {
+	return o.ClusterSelector.Matches(labels.Set(cluster.Labels))
+}
 
 // ToUnstructuredSlice converts the map of overrides to a slice of
-// interfaces that can be set in an unstructured object.
+// interfaces that can be set in an unstructured object.  Clusters are
+// sorted by name to ensure a stable serialization.
 func (m OverridesMap) ToUnstructuredSlice() []interface{} {
+	clusterNames := []string{}
+	for clusterName := range m {
+		clusterNames = append(clusterNames, clusterName)
+	}
+	sort.Strings(clusterNames)
+
 	overrides := []interface{}{}
-	for clusterName, clusterOverridesMap := range m {
-		clusterOverrides := []map[string]interface{}{}
-		for path, value := range clusterOverridesMap {
-			clusterOverrides = append(clusterOverrides, map[string]interface{}{
-				PathField:  path,
-				ValueField: value,
-			})
+	for _, clusterName := range clusterNames {
+		clusterOverrides := []interface{}{}
+		for _, override := range m[clusterName].Overrides {
+			clusterOverride := map[string]interface{}{
+				PathField: override.Path,
+			}
+			if len(override.Op) > 0 {
+				clusterOverride[OpField] = override.Op
+			}
+			if override.Op != OverrideOpRemove {
+				clusterOverride[ValueField] = override.Value
+			}
+			clusterOverrides = append(clusterOverrides, clusterOverride)
 		}
 		overridesItem := map[string]interface{}{
 			ClusterNameField:      clusterName,
 			ClusterOverridesField: clusterOverrides,
 		}
+		if m[clusterName].StrategicMergePatch != nil {
+			overridesItem[StrategicMergePatchField] = m[clusterName].StrategicMergePatch
+		}
 		overrides = append(overrides, overridesItem)
 	}
 	return overrides
 }
 
-// GetOverrides returns a map of overrides populated from the given
-// unstructured object.
+// GetOverrides returns a map of the overrides that target a cluster
+// by name populated from the given unstructured object.
 func GetOverrides(rawObj *unstructured.Unstructured) (OverridesMap, error) {
+	overridesMap, _, err := getOverrides(rawObj)
+	return overridesMap, err
+}
+
+// GetSelectorOverrides returns the overrides that target a cluster
+// selector populated from the given unstructured object, in the
+// order they are defined.
+func GetSelectorOverrides(rawObj *unstructured.Unstructured) ([]*SelectorOverrides, error) {
+	_, selectorOverrides, err := getOverrides(rawObj)
+	return selectorOverrides, err
+}
+
+func getOverrides(rawObj *unstructured.Unstructured) (OverridesMap, []*SelectorOverrides, error) {
 	overridesMap := make(OverridesMap)
+	selectorOverrides := []*SelectorOverrides{}
 
 	if rawObj == nil {
-		return overridesMap, nil
+		return overridesMap, selectorOverrides, nil
 	}
 
 	override := GenericOverride{}
 	err := UnstructuredToInterface(rawObj, &override)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if override.Spec == nil || override.Spec.Overrides == nil {
 		// No overrides defined for the federated type
-		return overridesMap, nil
+		return overridesMap, selectorOverrides, nil
 	}
 
-	for _, overrideItem := range override.Spec.Overrides {
+	for i, overrideItem := range override.Spec.Overrides {
 		clusterName := overrideItem.ClusterName
+		hasName := len(clusterName) > 0
+		hasSelector := overrideItem.ClusterSelector != nil
+		if hasName == hasSelector {
+			return nil, nil, errors.Errorf("overrides[%d] must specify exactly one of clusterName or clusterSelector", i)
+		}
+
+		if hasSelector {
+			selector, err := metav1.LabelSelectorAsSelector(overrideItem.ClusterSelector)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "overrides[%d] has an invalid cluster selector", i)
+			}
+			clusterOverrides, err := clusterOverridesForItem(overrideItem, fmt.Sprintf("cluster selector %q", selector))
+			if err != nil {
+				return nil, nil, err
+			}
+			selectorOverrides = append(selectorOverrides, &SelectorOverrides{
+				ClusterOverrides: *clusterOverrides,
+				LabelSelector:    overrideItem.ClusterSelector,
+				ClusterSelector:  selector,
+			})
+			continue
+		}
+
 		if _, ok := overridesMap[clusterName]; ok {
-			return nil, errors.Errorf("cluster %q appears more than once", clusterName)
+			return nil, nil, errors.Errorf("cluster %q appears more than once", clusterName)
+		}
+		clusterOverrides, err := clusterOverridesForItem(overrideItem, fmt.Sprintf("cluster %q", clusterName))
+		if err != nil {
+			return nil, nil, err
 		}
-		overridesMap[clusterName] = make(ClusterOverridesMap)
+		overridesMap[clusterName] = clusterOverrides
+	}
+
+	return overridesMap, selectorOverrides, nil
+}
+
+// OverridesForCluster returns the overrides that apply to the given
+// cluster in the order they should be applied.  Overrides targeting a
+// matching cluster selector are applied first in the order they are
+// defined, followed by the overrides targeting the cluster by name.
+// Overrides targeting a cluster by name therefore take precedence.
+func OverridesForCluster(overridesMap OverridesMap, selectorOverrides []*SelectorOverrides, cluster *fedv1a1.FederatedCluster) []*ClusterOverrides {
+	clusterOverrides := []*ClusterOverrides{}
+	for _, overrides := range selectorOverrides {
+		if overrides.Matches(cluster) {
+			clusterOverrides = append(clusterOverrides, &overrides.ClusterOverrides)
+		}
+	}
+	if overrides, ok := overridesMap[cluster.Name]; ok {
+		clusterOverrides = append(clusterOverrides, overrides)
+	}
+	return clusterOverrides
+}
 
-		clusterOverrides := overrideItem.ClusterOverrides
+// clusterOverridesForItem validates the given override item and
+// returns the overrides it defines.
+func clusterOverridesForItem(overrideItem GenericOverrideItem, target string) (*ClusterOverrides, error) {
+	if overrideItem.StrategicMergePatch != nil {
+		path, ok := invalidPatchPath(overrideItem.StrategicMergePatch)
+		if ok {
+			return nil, errors.Errorf("strategic merge patch for %s modifies an invalid path: %s", target, path)
+		}
+	}
 
-		for i, clusterOverride := range clusterOverrides {
-			path := clusterOverride.Path
-			if invalidPaths.Has(path) {
-				return nil, errors.Errorf("override[%d] for cluster %q has an invalid path: %s", i, clusterName, path)
+	valuePaths := sets.NewString()
+	for i, clusterOverride := range overrideItem.ClusterOverrides {
+		path := clusterOverride.Path
+		if len(clusterOverride.Op) == 0 {
+			if isInvalidPath(strings.Split(path, ".")) {
+				return nil, errors.Errorf("override[%d] for %s has an invalid path: %s", i, target, path)
 			}
-			if _, ok := overridesMap[clusterName][path]; ok {
-				return nil, errors.Errorf("path %q appears more than once for cluster %q", path, clusterName)
+			if valuePaths.Has(path) {
+				return nil, errors.Errorf("path %q appears more than once for %s", path, target)
 			}
+			valuePaths.Insert(path)
+			continue
+		}
 
-			overridesMap[clusterName][path] = clusterOverride.Value
+		if !validOverrideOps.Has(clusterOverride.Op) {
+			return nil, errors.Errorf("override[%d] for %s has an invalid op: %s", i, target, clusterOverride.Op)
+		}
+		pathEntries, err := parseJSONPointer(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "override[%d] for %s has an invalid path", i, target)
+		}
+		if len(pathEntries) == 0 || isInvalidPath(pathEntries) {
+			return nil, errors.Errorf("override[%d] for %s has an invalid path: %s", i, target, path)
 		}
 	}
 
-	return overridesMap, nil
+	return &ClusterOverrides{
+		StrategicMergePatch: overrideItem.StrategicMergePatch,
+		Overrides:           overrideItem.ClusterOverrides,
+	}, nil
+}
+
+// isInvalidPath indicates whether the given path is, or contains, a
+// path that may not be overridden.
+func isInvalidPath(pathEntries []string) bool {
+	path := strings.Join(pathEntries, ".")
+	for _, invalidPath := range invalidPaths.List() {
+		if path == invalidPath || strings.HasPrefix(invalidPath, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidPatchPath returns the first path that may not be overridden
+// that would be modified by the given merge patch.
+func invalidPatchPath(patch map[string]interface{}) (string, bool) {
+	for _, invalidPath := range invalidPaths.List() {
+		fieldMap := patch
+		pathEntries := strings.Split(invalidPath, ".")
+		for i, entry := range pathEntries {
+			value, ok := fieldMap[entry]
+			if !ok {
+				break
+			}
+			nestedMap, isMap := value.(map[string]interface{})
+			if i == len(pathEntries)-1 || !isMap {
+				return strings.Join(pathEntries[:i+1], "."), true
+			}
+			fieldMap = nestedMap
+		}
+	}
+	return "", false
+}
+
+// ApplyOverrides applies the given overrides to the provided object.
+// The strategic merge patch is applied first, followed by the
+// individual overrides in the order they were defined.
+func ApplyOverrides(obj *unstructured.Unstructured, overrides *ClusterOverrides) error {
+	if overrides == nil {
+		return nil
+	}
+
+	if overrides.StrategicMergePatch != nil {
+		err := applyStrategicMergePatch(obj, overrides.StrategicMergePatch)
+		if err != nil {
+			return errors.Wrap(err, "Error applying strategic merge patch")
+		}
+	}
+
+	for i, override := range overrides.Overrides {
+		if len(override.Op) == 0 {
+			pathEntries := strings.Split(override.Path, ".")
+			err := unstructured.SetNestedField(obj.Object, override.Value, pathEntries...)
+			if err != nil {
+				return errors.Wrapf(err, "Error applying override[%d] with path %q", i, override.Path)
+			}
+			continue
+		}
+		err := applyJSONPatchOperation(obj.Object, override)
+		if err != nil {
+			return errors.Wrapf(err, "Error applying override[%d] (%s %q)", i, override.Op, override.Path)
+		}
+	}
+
+	return nil
 }
 
 // SetOverrides sets the spec.overrides field of the unstructured
-// object from the provided overrides map.
+// object from the provided overrides map.  Existing overrides that
+// target a cluster selector are retained.
 func SetOverrides(fedObject *unstructured.Unstructured, overridesMap OverridesMap) error {
 	rawSpec := fedObject.Object[SpecField]
 	if rawSpec == nil {
@@ -141,7 +418,19 @@ func SetOverrides(fedObject *unstructured.Unstructured, overridesMap OverridesMa
 	if !ok {
 		return errors.Errorf("Unable to set overrides since %q is not an object: %T", SpecField, rawSpec)
 	}
-	spec[OverridesField] = overridesMap.ToUnstructuredSlice()
+
+	overrides := overridesMap.ToUnstructuredSlice()
+	existingOverrides, _ := spec[OverridesField].([]interface{})
+	for _, rawOverridesItem := range existingOverrides {
+		overridesItem, ok := rawOverridesItem.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok := overridesItem[ClusterSelectorField]; ok {
+			overrides = append(overrides, overridesItem)
+		}
+	}
+	spec[OverridesField] = overrides
 	return nil
 }
 
@@ -0,0 +1,326 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+func unstructuredFromJSON(t *testing.T, content string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	err := json.Unmarshal([]byte(content), &obj.Object)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return obj
+}
+
+func TestGetOverrides(t *testing.T) {
+	testCases := map[string]struct {
+		overrides   string
+		expectedErr bool
+	}{
+		"value override": {
+			overrides: `[{"clusterName": "c1", "clusterOverrides": [{"path": "spec.replicas", "value": 1}]}]`,
+		},
+		"json patch override": {
+			overrides: `[{"clusterName": "c1", "clusterOverrides": [{"op": "remove", "path": "/spec/replicas"}]}]`,
+		},
+		"duplicate cluster": {
+			overrides:   `[{"clusterName": "c1"}, {"clusterName": "c1"}]`,
+			expectedErr: true,
+		},
+		"duplicate value path": {
+			overrides:   `[{"clusterName": "c1", "clusterOverrides": [{"path": "spec.replicas", "value": 1}, {"path": "spec.replicas", "value": 2}]}]`,
+			expectedErr: true,
+		},
+		"invalid value path": {
+			overrides:   `[{"clusterName": "c1", "clusterOverrides": [{"path": "metadata.name", "value": "foo"}]}]`,
+			expectedErr: true,
+		},
+		"invalid json patch path": {
+			overrides:   `[{"clusterName": "c1", "clusterOverrides": [{"op": "replace", "path": "/metadata/namespace", "value": "foo"}]}]`,
+			expectedErr: true,
+		},
+		"json patch path containing an invalid path": {
+			overrides:   `[{"clusterName": "c1", "clusterOverrides": [{"op": "remove", "path": "/metadata"}]}]`,
+			expectedErr: true,
+		},
+		"json patch path that is not a pointer": {
+			overrides:   `[{"clusterName": "c1", "clusterOverrides": [{"op": "add", "path": "spec.replicas", "value": 1}]}]`,
+			expectedErr: true,
+		},
+		"invalid op": {
+			overrides:   `[{"clusterName": "c1", "clusterOverrides": [{"op": "move", "path": "/spec/replicas"}]}]`,
+			expectedErr: true,
+		},
+		"invalid strategic merge patch": {
+			overrides:   `[{"clusterName": "c1", "strategicMergePatch": {"metadata": {"name": "foo"}}}]`,
+			expectedErr: true,
+		},
+		"cluster selector": {
+			overrides: `[{"clusterSelector": {"matchLabels": {"foo": "bar"}}, "clusterOverrides": [{"path": "spec.replicas", "value": 1}]}]`,
+		},
+		"cluster selector and name for the same cluster": {
+			overrides: `[{"clusterSelector": {"matchLabels": {"foo": "bar"}}}, {"clusterName": "c1"}]`,
+		},
+		"both cluster name and selector": {
+			overrides:   `[{"clusterName": "c1", "clusterSelector": {"matchLabels": {"foo": "bar"}}}]`,
+			expectedErr: true,
+		},
+		"neither cluster name nor selector": {
+			overrides:   `[{"clusterOverrides": [{"path": "spec.replicas", "value": 1}]}]`,
+			expectedErr: true,
+		},
+		"invalid cluster selector": {
+			overrides:   `[{"clusterSelector": {"matchExpressions": [{"key": "foo", "operator": "Bogus"}]}}]`,
+			expectedErr: true,
+		},
+		"invalid cluster selector path": {
+			overrides:   `[{"clusterSelector": {}, "clusterOverrides": [{"path": "metadata.name", "value": "foo"}]}]`,
+			expectedErr: true,
+		},
+	}
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := unstructuredFromJSON(t, `{"spec": {"overrides": `+testCase.overrides+`}}`)
+			_, err := GetOverrides(obj)
+			if testCase.expectedErr && err == nil {
+				t.Fatalf("Expected an error")
+			}
+			if !testCase.expectedErr && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestOverridesForCluster(t *testing.T) {
+	obj := unstructuredFromJSON(t, `{"spec": {"overrides": [
+	{"clusterName": "c1", "clusterOverrides": [{"path": "spec.replicas", "value": 1}]},
+	{"clusterSelector": {"matchLabels": {"foo": "bar"}}, "clusterOverrides": [{"path": "spec.replicas", "value": 2}]},
+	{"clusterSelector": {}, "clusterOverrides": [{"path": "spec.paused", "value": true}]}
+]}}`)
+	overridesMap, err := GetOverrides(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	selectorOverrides, err := GetSelectorOverrides(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testCases := map[string]struct {
+		clusterName    string
+		clusterLabels  map[string]string
+		expectedValues []interface{}
+	}{
+		"name takes precedence over selector": {
+			clusterName:    "c1",
+			clusterLabels:  map[string]string{"foo": "bar"},
+			expectedValues: []interface{}{float64(2), true, float64(1)},
+		},
+		"selector matches": {
+			clusterName:    "c2",
+			clusterLabels:  map[string]string{"foo": "bar"},
+			expectedValues: []interface{}{float64(2), true},
+		},
+		"only empty selector matches": {
+			clusterName:    "c2",
+			expectedValues: []interface{}{true},
+		},
+	}
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			cluster := &fedv1a1.FederatedCluster{}
+			cluster.Name = testCase.clusterName
+			cluster.Labels = testCase.clusterLabels
+			values := []interface{}{}
+			for _, clusterOverrides := range OverridesForCluster(overridesMap, selectorOverrides, cluster) {
+				for _, override := range clusterOverrides.Overrides {
+					values = append(values, override.Value)
+				}
+			}
+			if !reflect.DeepEqual(testCase.expectedValues, values) {
+				t.Fatalf("Expected values %v, got %v", testCase.expectedValues, values)
+			}
+		})
+	}
+}
+
+func TestSetOverridesRetainsSelectorOverrides(t *testing.T) {
+	obj := unstructuredFromJSON(t, `{"spec": {"overrides": [
+	{"clusterName": "c1", "clusterOverrides": [{"path": "spec.replicas", "value": 1}]},
+	{"clusterSelector": {"matchLabels": {"foo": "bar"}}, "clusterOverrides": [{"path": "spec.replicas", "value": 2}]}
+]}}`)
+	overridesMap := OverridesMap{
+		"c2": &ClusterOverrides{Overrides: []ClusterOverride{{Path: "spec.replicas", Value: 3}}},
+	}
+	err := SetOverrides(obj, overridesMap)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	overridesMap, err = GetOverrides(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := overridesMap["c1"]; ok || len(overridesMap) != 1 {
+		t.Fatalf("Expected only the overrides for cluster c2, got %v", overridesMap)
+	}
+	selectorOverrides, err := GetSelectorOverrides(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(selectorOverrides) != 1 {
+		t.Fatalf("Expected the selector overrides to be retained")
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	deployment := `{
+	"apiVersion": "apps/v1",
+	"kind": "Deployment",
+	"metadata": {"name": "foo"},
+	"spec": {
+		"replicas": 1,
+		"template": {"spec": {"containers": [
+			{"name": "a", "image": "a:1", "env": [{"name": "FOO", "value": "foo"}]},
+			{"name": "b", "image": "b:1"}
+		]}}
+	}
+}`
+	testCases := map[string]struct {
+		overrides   string
+		expected    string
+		expectedErr bool
+	}{
+		"value override": {
+			overrides: `{"clusterOverrides": [{"path": "spec.replicas", "value": 2}]}`,
+			expected:  `{"spec": {"replicas": 2}}`,
+		},
+		"replace array element field": {
+			overrides: `{"clusterOverrides": [{"op": "replace", "path": "/spec/template/spec/containers/1/image", "value": "b:2"}]}`,
+			expected:  `{"spec": {"template": {"spec": {"containers": [{"name": "b", "image": "b:2"}]}}}}`,
+		},
+		"append to array": {
+			overrides: `{"clusterOverrides": [{"op": "add", "path": "/spec/template/spec/containers/0/env/-", "value": {"name": "BAR", "value": "bar"}}]}`,
+			expected:  `{"spec": {"template": {"spec": {"containers": [{"name": "a", "env": [{"name": "FOO", "value": "foo"}, {"name": "BAR", "value": "bar"}]}]}}}}`,
+		},
+		"remove field": {
+			overrides: `{"clusterOverrides": [{"op": "remove", "path": "/spec/replicas"}]}`,
+			expected:  `{"spec": {"replicas": null}}`,
+		},
+		"remove missing field": {
+			overrides:   `{"clusterOverrides": [{"op": "remove", "path": "/spec/paused"}]}`,
+			expectedErr: true,
+		},
+		"replace out of bounds": {
+			overrides:   `{"clusterOverrides": [{"op": "replace", "path": "/spec/template/spec/containers/2/image", "value": "c:1"}]}`,
+			expectedErr: true,
+		},
+		"strategic merge patch merges by key": {
+			overrides: `{"strategicMergePatch": {"spec": {"template": {"spec": {"containers": [{"name": "a", "image": "a:2"}]}}}}}`,
+			expected:  `{"spec": {"replicas": 1, "template": {"spec": {"containers": [{"name": "a", "image": "a:2"}, {"name": "b", "image": "b:1"}]}}}}`,
+		},
+		"strategic merge patch precedes overrides": {
+			overrides: `{"strategicMergePatch": {"spec": {"replicas": 3}}, "clusterOverrides": [{"path": "spec.replicas", "value": 4}]}`,
+			expected:  `{"spec": {"replicas": 4}}`,
+		},
+	}
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := unstructuredFromJSON(t, deployment)
+			overrideItem := GenericOverrideItem{}
+			err := json.Unmarshal([]byte(testCase.overrides), &overrideItem)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			err = ApplyOverrides(obj, &ClusterOverrides{
+				StrategicMergePatch: overrideItem.StrategicMergePatch,
+				Overrides:           overrideItem.ClusterOverrides,
+			})
+			if testCase.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			expected := unstructuredFromJSON(t, testCase.expected)
+			assertSubset(t, expected.Object, obj.Object, "")
+		})
+	}
+}
+
+// assertSubset checks that the fields of expected are present in
+// actual.  A nil expected value indicates the field should be absent,
+// and slices are compared by the name of their elements.
+func assertSubset(t *testing.T, expected, actual interface{}, path string) {
+	switch expectedValue := expected.(type) {
+	case map[string]interface{}:
+		actualMap, ok := actual.(map[string]interface{})
+		if !ok {
+			t.Fatalf("Expected an object at %q, got %v", path, actual)
+		}
+		for key, value := range expectedValue {
+			actualValue, ok := actualMap[key]
+			if value == nil {
+				if ok {
+					t.Fatalf("Expected %q to be absent, got %v", path+"."+key, actualValue)
+				}
+				continue
+			}
+			if !ok {
+				t.Fatalf("Expected %q to be present", path+"."+key)
+			}
+			assertSubset(t, value, actualValue, path+"."+key)
+		}
+	case []interface{}:
+		actualSlice, ok := actual.([]interface{})
+		if !ok {
+			t.Fatalf("Expected an array at %q, got %v", path, actual)
+		}
+		for _, element := range expectedValue {
+			name := element.(map[string]interface{})["name"]
+			found := false
+			for _, actualElement := range actualSlice {
+				if actualElement.(map[string]interface{})["name"] == name {
+					assertSubset(t, element, actualElement, path)
+					found = true
+				}
+			}
+			if !found {
+				t.Fatalf("Expected an element named %q at %q", name, path)
+			}
+		}
+		if len(expectedValue) > 1 && len(expectedValue) != len(actualSlice) {
+			t.Fatalf("Expected %d elements at %q, got %d", len(expectedValue), path, len(actualSlice))
+		}
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("Expected %v at %q, got %v", expected, path, actual)
+		}
+	}
+}
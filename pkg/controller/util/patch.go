@@ -0,0 +1,189 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// parseJSONPointer splits an RFC 6901 JSON Pointer into its unescaped
+// reference tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if len(pointer) == 0 {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errors.Errorf("JSON Pointer %q must start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		token = strings.Replace(token, "~1", "/", -1)
+		tokens[i] = strings.Replace(token, "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// applyJSONPatchOperation applies an RFC 6902 add, remove or replace
+// operation to the given field map.
+func applyJSONPatchOperation(fieldMap map[string]interface{}, override ClusterOverride) error {
+	tokens, err := parseJSONPointer(override.Path)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return errors.New("The root of the object may not be patched")
+	}
+	var value interface{}
+	if override.Op != OverrideOpRemove {
+		value = pkgruntime.DeepCopyJSONValue(override.Value)
+	}
+	_, err = patchValue(fieldMap, tokens, override.Op, value)
+	return err
+}
+
+// patchValue applies the operation to the node referenced by the
+// given tokens and returns the resulting value of current.  Slices
+// may need to be reallocated, so the caller is responsible for
+// storing the returned value.
+func patchValue(current interface{}, tokens []string, op string, value interface{}) (interface{}, error) {
+	token := tokens[0]
+	last := len(tokens) == 1
+
+	switch node := current.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if last {
+			if !ok && op != OverrideOpAdd {
+				return nil, errors.Errorf("Field %q does not exist", token)
+			}
+			if op == OverrideOpRemove {
+				delete(node, token)
+			} else {
+				node[token] = value
+			}
+			return node, nil
+		}
+		if !ok {
+			return nil, errors.Errorf("Field %q does not exist", token)
+		}
+		updated, err := patchValue(child, tokens[1:], op, value)
+		if err != nil {
+			return nil, err
+		}
+		node[token] = updated
+		return node, nil
+	case []interface{}:
+		if last && op == OverrideOpAdd && token == "-" {
+			return append(node, value), nil
+		}
+		index, err := parseArrayIndex(token, len(node), last && op == OverrideOpAdd)
+		if err != nil {
+			return nil, err
+		}
+		if !last {
+			updated, err := patchValue(node[index], tokens[1:], op, value)
+			if err != nil {
+				return nil, err
+			}
+			node[index] = updated
+			return node, nil
+		}
+		switch op {
+		case OverrideOpAdd:
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+		case OverrideOpRemove:
+			node = append(node[:index], node[index+1:]...)
+		default:
+			node[index] = value
+		}
+		return node, nil
+	default:
+		return nil, errors.Errorf("Unable to resolve %q in a value of type %T", token, current)
+	}
+}
+
+// parseArrayIndex converts the given token to an index of an array of
+// the given length.  If inserting, the index may be equal to the
+// length of the array.
+func parseArrayIndex(token string, length int, inserting bool) (int, error) {
+	if len(token) > 1 && strings.HasPrefix(token, "0") {
+		return 0, errors.Errorf("Invalid array index %q", token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 {
+		return 0, errors.Errorf("Invalid array index %q", token)
+	}
+	maxIndex := length - 1
+	if inserting {
+		maxIndex = length
+	}
+	if index > maxIndex {
+		return 0, errors.Errorf("Array index %d is out of bounds", index)
+	}
+	return index, nil
+}
+
+// applyStrategicMergePatch applies the given patch to the object.  If
+// the kind of the object is not registered with the client scheme
+// (e.g. for a CRD), the patch will be applied as a JSON merge patch.
+func applyStrategicMergePatch(obj *unstructured.Unstructured, patch map[string]interface{}) error {
+	patch = pkgruntime.DeepCopyJSON(patch)
+	dataStruct, err := scheme.Scheme.New(obj.GroupVersionKind())
+	if pkgruntime.IsNotRegisteredError(err) {
+		obj.Object = jsonMergePatch(obj.Object, patch).(map[string]interface{})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	patchedObj, err := strategicpatch.StrategicMergeMapPatch(obj.Object, patch, dataStruct)
+	if err != nil {
+		return err
+	}
+	obj.Object = patchedObj
+	return nil
+}
+
+// jsonMergePatch applies an RFC 7386 merge patch to the original value.
+func jsonMergePatch(original, patch interface{}) interface{} {
+	patchMap, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	originalMap, ok := original.(map[string]interface{})
+	if !ok {
+		originalMap = make(map[string]interface{})
+	}
+	for key, value := range patchMap {
+		if value == nil {
+			delete(originalMap, key)
+			continue
+		}
+		originalMap[key] = jsonMergePatch(originalMap[key], value)
+	}
+	return originalMap
+}
@@ -19,14 +19,50 @@ package util
 import (
 	"encoding/json"
 
+	"github.com/pkg/errors"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
+// The topologies that can be used to spread placement.  They
+// correspond to the region and zone reported in the status of a
+// FederatedCluster.
+const (
+	TopologyKeyRegion = "region"
+	TopologyKeyZone   = "zone"
+)
+
 type GenericPlacementFields struct {
 	ClusterNames    []string              `json:"clusterNames,omitempty"`
 	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+
+	// The maximum number of clusters to select from the clusters
+	// matching ClusterNames or ClusterSelector.  If not provided,
+	// all matching clusters are selected.
+	MaxClusters *int32 `json:"maxClusters,omitempty"`
+	// The constraints on how the selected clusters should be spread
+	// across topology domains.
+	SpreadConstraints []SpreadConstraint `json:"spreadConstraints,omitempty"`
+	// The preferences used to rank matching clusters when only a
+	// subset of them can be selected.
+	ClusterPreferences []ClusterPreference `json:"clusterPreferences,omitempty"`
+}
+
+// SpreadConstraint requires the selected clusters to span at least
+// MinDomains distinct values of the given topology.
+type SpreadConstraint struct {
+	// One of region or zone
+	TopologyKey string `json:"topologyKey"`
+	MinDomains  int32  `json:"minDomains"`
+}
+
+// ClusterPreference adds Weight to the rank of clusters matching
+// ClusterSelector.
+type ClusterPreference struct {
+	Weight          int32                 `json:"weight"`
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector"`
 }
 
 // TODO(marun) Consider removing this intermediate field.  It is only
@@ -43,7 +79,21 @@ type GenericPlacement struct {
 }
 
 type PlacementDirective struct {
-	ClusterNames    []string
+	ClusterNames       []string
+	ClusterSelector    labels.Selector
+	MaxClusters        *int32
+	SpreadConstraints  []SpreadConstraint
+	ClusterPreferences []PlacementPreference
+}
+
+// Constrained indicates whether the directive may select only a
+// subset of the clusters matching its names or selector.
+func (d *PlacementDirective) Constrained() bool {
+	return d.MaxClusters != nil || len(d.SpreadConstraints) > 0
+}
+
+type PlacementPreference struct {
+	Weight          int32
 	ClusterSelector labels.Selector
 }
 
@@ -57,13 +107,39 @@ func GetPlacementDirective(resource *unstructured.Unstructured) (*PlacementDirec
 	if err != nil {
 		return nil, err
 	}
-	selector, err := metav1.LabelSelectorAsSelector(placement.Spec.Placement.ClusterSelector)
+	fields := placement.Spec.Placement
+	selector, err := metav1.LabelSelectorAsSelector(fields.ClusterSelector)
 	if err != nil {
 		return nil, err
 	}
+	if fields.MaxClusters != nil && *fields.MaxClusters < 0 {
+		return nil, errors.Errorf("maxClusters must not be negative: %d", *fields.MaxClusters)
+	}
+	for i, constraint := range fields.SpreadConstraints {
+		if constraint.TopologyKey != TopologyKeyRegion && constraint.TopologyKey != TopologyKeyZone {
+			return nil, errors.Errorf("spreadConstraints[%d] has an invalid topologyKey: %q", i, constraint.TopologyKey)
+		}
+		if constraint.MinDomains < 1 {
+			return nil, errors.Errorf("spreadConstraints[%d] must require at least one domain", i)
+		}
+	}
+	preferences := []PlacementPreference{}
+	for i, preference := range fields.ClusterPreferences {
+		preferenceSelector, err := metav1.LabelSelectorAsSelector(preference.ClusterSelector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "clusterPreferences[%d] has an invalid cluster selector", i)
+		}
+		preferences = append(preferences, PlacementPreference{
+			Weight:          preference.Weight,
+			ClusterSelector: preferenceSelector,
+		})
+	}
 	return &PlacementDirective{
-		ClusterNames:    placement.Spec.Placement.ClusterNames,
-		ClusterSelector: selector,
+		ClusterNames:       fields.ClusterNames,
+		ClusterSelector:    selector,
+		MaxClusters:        fields.MaxClusters,
+		SpreadConstraints:  fields.SpreadConstraints,
+		ClusterPreferences: preferences,
 	}, nil
 }
 
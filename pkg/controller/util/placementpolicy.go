@@ -0,0 +1,334 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+// PlacementPolicyManager sources the PlacementPolicies and the
+// namespaces needed to determine the policies that apply to a
+// federated resource.  A single manager is shared by the controllers
+// of all federated types, each of which registers a listener to be
+// notified of changes.
+type PlacementPolicyManager struct {
+	policyStore      cache.Store
+	policyController cache.Controller
+
+	namespaceStore      cache.Store
+	namespaceController cache.Controller
+
+	lock      sync.RWMutex
+	listeners map[*placementPolicyListener]bool
+}
+
+// placementPolicyListener is notified of changes that may change the
+// policies that apply to federated resources.
+type placementPolicyListener struct {
+	policiesChanged  func()
+	namespaceChanged func(namespace string)
+}
+
+// NewPlacementPolicyManager returns a manager for the PlacementPolicies
+// and namespaces of the given cluster.
+func NewPlacementPolicyManager(config *rest.Config) (*PlacementPolicyManager, error) {
+	m := &PlacementPolicyManager{
+		listeners: make(map[*placementPolicyListener]bool),
+	}
+
+	var err error
+	m.policyStore, m.policyController, err = NewGenericInformerWithEventHandler(
+		config,
+		"",
+		&fedv1a1.PlacementPolicy{},
+		NoResyncPeriod,
+		&cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				m.notifyPoliciesChanged()
+			},
+			UpdateFunc: func(old, cur interface{}) {
+				if !reflect.DeepEqual(old.(*fedv1a1.PlacementPolicy).Spec, cur.(*fedv1a1.PlacementPolicy).Spec) {
+					m.notifyPoliciesChanged()
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				m.notifyPoliciesChanged()
+			},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only a change to the labels of a namespace can change the
+	// policies that apply to the resources it contains.
+	m.namespaceStore, m.namespaceController, err = NewGenericInformerWithEventHandler(
+		config,
+		"",
+		&corev1.Namespace{},
+		NoResyncPeriod,
+		&cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				m.notifyNamespaceChanged(obj.(*corev1.Namespace).Name)
+			},
+			UpdateFunc: func(old, cur interface{}) {
+				oldNamespace := old.(*corev1.Namespace)
+				curNamespace := cur.(*corev1.Namespace)
+				if !reflect.DeepEqual(oldNamespace.Labels, curNamespace.Labels) {
+					m.notifyNamespaceChanged(curNamespace.Name)
+				}
+			},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *PlacementPolicyManager) Run(stopChan <-chan struct{}) {
+	go m.policyController.Run(stopChan)
+	go m.namespaceController.Run(stopChan)
+}
+
+func (m *PlacementPolicyManager) HasSynced() bool {
+	return m.policyController.HasSynced() && m.namespaceController.HasSynced()
+}
+
+// AddListener registers functions to be called when any
+// PlacementPolicy changes and with the name of a namespace whose
+// labels change.  The returned function removes the listener.
+func (m *PlacementPolicyManager) AddListener(policiesChanged func(), namespaceChanged func(namespace string)) func() {
+	listener := &placementPolicyListener{
+		policiesChanged:  policiesChanged,
+		namespaceChanged: namespaceChanged,
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.listeners[listener] = true
+	return func() {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		delete(m.listeners, listener)
+	}
+}
+
+// notifyPoliciesChanged notifies listeners of a change to a policy.
+// Listeners are not notified of the policies and namespaces listed
+// before the manager has synced, since the resources of every type
+// are reconciled once the manager has synced.
+func (m *PlacementPolicyManager) notifyPoliciesChanged() {
+	if !m.HasSynced() {
+		return
+	}
+	for _, listener := range m.currentListeners() {
+		listener.policiesChanged()
+	}
+}
+
+// notifyNamespaceChanged notifies listeners of a change to the labels
+// of the named namespace.
+func (m *PlacementPolicyManager) notifyNamespaceChanged(namespace string) {
+	if !m.HasSynced() {
+		return
+	}
+	for _, listener := range m.currentListeners() {
+		listener.namespaceChanged(namespace)
+	}
+}
+
+func (m *PlacementPolicyManager) currentListeners() []*placementPolicyListener {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	listeners := make([]*placementPolicyListener, 0, len(m.listeners))
+	for listener := range m.listeners {
+		listeners = append(listeners, listener)
+	}
+	return listeners
+}
+
+// PoliciesFor returns the PlacementPolicies that apply to the given
+// federated resource, sorted by name.
+func (m *PlacementPolicyManager) PoliciesFor(fedObject *unstructured.Unstructured) ([]*fedv1a1.PlacementPolicy, error) {
+	var namespaceLabels map[string]string
+	if namespace := fedObject.GetNamespace(); len(namespace) > 0 {
+		obj, found, err := m.namespaceStore.GetByKey(namespace)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			namespaceLabels = obj.(*corev1.Namespace).Labels
+		}
+	}
+	policies := []*fedv1a1.PlacementPolicy{}
+	for _, obj := range m.policyStore.List() {
+		policies = append(policies, obj.(*fedv1a1.PlacementPolicy))
+	}
+	return MatchingPlacementPolicies(policies, len(fedObject.GetNamespace()) > 0, namespaceLabels, fedObject.GetLabels())
+}
+
+// MatchingPlacementPolicies returns the given policies that apply to
+// a federated resource with the given labels, sorted by name.  A
+// policy with a namespace selector does not apply to a cluster-scoped
+// resource.
+func MatchingPlacementPolicies(policies []*fedv1a1.PlacementPolicy, namespaced bool, namespaceLabels, resourceLabels map[string]string) ([]*fedv1a1.PlacementPolicy, error) {
+	matching := []*fedv1a1.PlacementPolicy{}
+	for _, policy := range policies {
+		if policy.Spec.NamespaceSelector != nil {
+			if !namespaced {
+				continue
+			}
+			matches, err := selectorMatches(policy.Spec.NamespaceSelector, namespaceLabels)
+			if err != nil {
+				return nil, errors.Wrapf(err, "PlacementPolicy %q has an invalid namespaceSelector", policy.Name)
+			}
+			if !matches {
+				continue
+			}
+		}
+		if policy.Spec.ResourceSelector != nil {
+			matches, err := selectorMatches(policy.Spec.ResourceSelector, resourceLabels)
+			if err != nil {
+				return nil, errors.Wrapf(err, "PlacementPolicy %q has an invalid resourceSelector", policy.Name)
+			}
+			if !matches {
+				continue
+			}
+		}
+		matching = append(matching, policy)
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].Name < matching[j].Name
+	})
+	return matching, nil
+}
+
+// DeniedClusters returns a mapping of the name of each of the given
+// clusters that may not be selected under the given policies to the
+// reason it was denied.  The first policy to deny a cluster is
+// reported.
+func DeniedClusters(policies []*fedv1a1.PlacementPolicy, clusters []*fedv1a1.FederatedCluster) (map[string]string, error) {
+	denied := make(map[string]string)
+	for _, policy := range policies {
+		excluded := sets.NewString(policy.Spec.ExcludedClusters...)
+		var selector labels.Selector
+		if policy.Spec.ClusterSelector != nil {
+			var err error
+			selector, err = metav1.LabelSelectorAsSelector(policy.Spec.ClusterSelector)
+			if err != nil {
+				return nil, errors.Wrapf(err, "PlacementPolicy %q has an invalid clusterSelector", policy.Name)
+			}
+		}
+		for _, cluster := range clusters {
+			if _, ok := denied[cluster.Name]; ok {
+				continue
+			}
+			switch {
+			case excluded.Has(cluster.Name):
+				denied[cluster.Name] = fmt.Sprintf("The cluster is excluded by PlacementPolicy %q", policy.Name)
+			case selector != nil && !selector.Matches(labels.Set(cluster.Labels)):
+				denied[cluster.Name] = fmt.Sprintf("The cluster does not match the clusterSelector of PlacementPolicy %q", policy.Name)
+			}
+		}
+	}
+	return denied, nil
+}
+
+// ApplyDefaultPlacement returns the given federated resource with the
+// default placement of the first of the given policies that defines
+// one if the resource specifies neither clusterNames nor a
+// clusterSelector.  The resource is copied before it is modified.
+func ApplyDefaultPlacement(fedObject *unstructured.Unstructured, policies []*fedv1a1.PlacementPolicy) (*unstructured.Unstructured, error) {
+	for _, policy := range policies {
+		if policy.Spec.DefaultPlacement == nil {
+			continue
+		}
+		obj, err := applyDefaultPlacement(fedObject, policy.Spec.DefaultPlacement)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error applying default placement of PlacementPolicy %q", policy.Name)
+		}
+		return obj, nil
+	}
+	return fedObject, nil
+}
+
+// PlacementSpecified indicates whether the given federated resource
+// specifies either clusterNames or a clusterSelector.
+func PlacementSpecified(fedObject *unstructured.Unstructured) (bool, error) {
+	placement := GenericPlacement{}
+	err := UnstructuredToInterface(fedObject, &placement)
+	if err != nil {
+		return false, errors.Wrap(err, "Error retrieving placement")
+	}
+	return placement.Spec.Placement.ClusterNames != nil || placement.Spec.Placement.ClusterSelector != nil, nil
+}
+
+// applyDefaultPlacement returns a copy of the given federated resource
+// with the given placement if the resource does not specify
+// placement.
+func applyDefaultPlacement(fedObject *unstructured.Unstructured, defaultPlacement *fedv1a1.DefaultPlacement) (*unstructured.Unstructured, error) {
+	specified, err := PlacementSpecified(fedObject)
+	if err != nil {
+		return nil, err
+	}
+	if specified {
+		return fedObject, nil
+	}
+	obj := fedObject.DeepCopy()
+	if defaultPlacement.ClusterNames != nil {
+		err = unstructured.SetNestedStringSlice(obj.Object, defaultPlacement.ClusterNames, SpecField, PlacementField, ClusterNamesField)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if defaultPlacement.ClusterSelector != nil {
+		selector, err := pkgruntime.DefaultUnstructuredConverter.ToUnstructured(defaultPlacement.ClusterSelector)
+		if err != nil {
+			return nil, err
+		}
+		err = unstructured.SetNestedField(obj.Object, selector, SpecField, PlacementField, ClusterSelectorField)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+func selectorMatches(labelSelector *metav1.LabelSelector, labelSet map[string]string) (bool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(labelSet)), nil
+}
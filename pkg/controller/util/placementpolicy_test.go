@@ -0,0 +1,252 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+func newPlacementPolicy(name string, spec fedv1a1.PlacementPolicySpec) *fedv1a1.PlacementPolicy {
+	policy := &fedv1a1.PlacementPolicy{Spec: spec}
+	policy.Name = name
+	return policy
+}
+
+func TestMatchingPlacementPolicies(t *testing.T) {
+	pciNamespaces := newPlacementPolicy("pci", fedv1a1.PlacementPolicySpec{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pci": "true"}},
+	})
+	teamResources := newPlacementPolicy("team-x", fedv1a1.PlacementPolicySpec{
+		ResourceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "x"}},
+	})
+	all := newPlacementPolicy("all", fedv1a1.PlacementPolicySpec{})
+	policies := []*fedv1a1.PlacementPolicy{teamResources, pciNamespaces, all}
+
+	testCases := map[string]struct {
+		namespaced       bool
+		namespaceLabels  map[string]string
+		resourceLabels   map[string]string
+		expectedPolicies []string
+	}{
+		"no selectors match": {
+			namespaced:       true,
+			expectedPolicies: []string{"all"},
+		},
+		"namespace selector matches": {
+			namespaced:       true,
+			namespaceLabels:  map[string]string{"pci": "true"},
+			expectedPolicies: []string{"all", "pci"},
+		},
+		"resource selector matches": {
+			namespaced:       true,
+			resourceLabels:   map[string]string{"team": "x"},
+			expectedPolicies: []string{"all", "team-x"},
+		},
+		"namespace selector does not apply to cluster-scoped resource": {
+			resourceLabels:   map[string]string{"team": "x"},
+			expectedPolicies: []string{"all", "team-x"},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			matching, err := MatchingPlacementPolicies(policies, testCase.namespaced, testCase.namespaceLabels, testCase.resourceLabels)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			names := []string{}
+			for _, policy := range matching {
+				names = append(names, policy.Name)
+			}
+			if !reflect.DeepEqual(testCase.expectedPolicies, names) {
+				t.Fatalf("Expected policies %v, got %v", testCase.expectedPolicies, names)
+			}
+		})
+	}
+}
+
+func TestDeniedClusters(t *testing.T) {
+	newCluster := func(name string, labels map[string]string) *fedv1a1.FederatedCluster {
+		cluster := &fedv1a1.FederatedCluster{}
+		cluster.Name = name
+		cluster.Labels = labels
+		return cluster
+	}
+	clusters := []*fedv1a1.FederatedCluster{
+		newCluster("prod-eu", map[string]string{"pci": "true"}),
+		newCluster("prod-us", map[string]string{"pci": "true"}),
+		newCluster("dev", nil),
+	}
+
+	testCases := map[string]struct {
+		policies       []*fedv1a1.PlacementPolicy
+		expectedDenied []string
+	}{
+		"no policies": {
+			expectedDenied: []string{},
+		},
+		"cluster selector": {
+			policies: []*fedv1a1.PlacementPolicy{
+				newPlacementPolicy("pci", fedv1a1.PlacementPolicySpec{
+					ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pci": "true"}},
+				}),
+			},
+			expectedDenied: []string{"dev"},
+		},
+		"excluded clusters": {
+			policies: []*fedv1a1.PlacementPolicy{
+				newPlacementPolicy("team-x", fedv1a1.PlacementPolicySpec{
+					ExcludedClusters: []string{"prod-eu"},
+				}),
+			},
+			expectedDenied: []string{"prod-eu"},
+		},
+		"policies are combined": {
+			policies: []*fedv1a1.PlacementPolicy{
+				newPlacementPolicy("pci", fedv1a1.PlacementPolicySpec{
+					ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pci": "true"}},
+				}),
+				newPlacementPolicy("team-x", fedv1a1.PlacementPolicySpec{
+					ExcludedClusters: []string{"prod-eu"},
+				}),
+			},
+			expectedDenied: []string{"dev", "prod-eu"},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			denied, err := DeniedClusters(testCase.policies, clusters)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			deniedNames := sets.StringKeySet(denied).List()
+			if !reflect.DeepEqual(testCase.expectedDenied, deniedNames) {
+				t.Fatalf("Expected denied clusters %v, got %v", testCase.expectedDenied, deniedNames)
+			}
+		})
+	}
+}
+
+func TestApplyDefaultPlacement(t *testing.T) {
+	policies := []*fedv1a1.PlacementPolicy{
+		newPlacementPolicy("a", fedv1a1.PlacementPolicySpec{}),
+		newPlacementPolicy("b", fedv1a1.PlacementPolicySpec{
+			DefaultPlacement: &fedv1a1.DefaultPlacement{
+				ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pci": "true"}},
+			},
+		}),
+		newPlacementPolicy("c", fedv1a1.PlacementPolicySpec{
+			DefaultPlacement: &fedv1a1.DefaultPlacement{
+				ClusterNames: []string{"dev"},
+			},
+		}),
+	}
+
+	testCases := map[string]struct {
+		placement         string
+		expectedPlacement string
+	}{
+		"placement is defaulted": {
+			placement:         `{}`,
+			expectedPlacement: `{"clusterSelector": {"matchLabels": {"pci": "true"}}}`,
+		},
+		"cluster names are retained": {
+			placement:         `{"clusterNames": []}`,
+			expectedPlacement: `{"clusterNames": []}`,
+		},
+		"cluster selector is retained": {
+			placement:         `{"clusterSelector": {}}`,
+			expectedPlacement: `{"clusterSelector": {}}`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := unstructuredFromJSON(t, `{"metadata": {"name": "foo"}, "spec": {"placement": `+testCase.placement+`}}`)
+			expectedObject := unstructuredFromJSON(t, `{"metadata": {"name": "foo"}, "spec": {"placement": `+testCase.expectedPlacement+`}}`)
+			obj, err := ApplyDefaultPlacement(fedObject, policies)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(expectedObject, obj) {
+				t.Fatalf("Expected %v, got %v", expectedObject.Object, obj.Object)
+			}
+		})
+	}
+}
+
+// fakeController reports whether its informer has synced.
+type fakeController struct {
+	cache.Controller
+	synced bool
+}
+
+func (c *fakeController) HasSynced() bool {
+	return c.synced
+}
+
+func TestPlacementPolicyManagerListeners(t *testing.T) {
+	policyController := &fakeController{}
+	m := &PlacementPolicyManager{
+		policyController:    policyController,
+		namespaceController: &fakeController{synced: true},
+		listeners:           make(map[*placementPolicyListener]bool),
+	}
+	policyChanges := []int{0, 0}
+	changedNamespaces := [][]string{{}, {}}
+	removeListeners := []func(){}
+	for i := range policyChanges {
+		i := i
+		removeListeners = append(removeListeners, m.AddListener(
+			func() {
+				policyChanges[i]++
+			},
+			func(namespace string) {
+				changedNamespaces[i] = append(changedNamespaces[i], namespace)
+			},
+		))
+	}
+
+	// Changes listed before the manager has synced are not notified.
+	m.notifyPoliciesChanged()
+	m.notifyNamespaceChanged("ns1")
+	if !reflect.DeepEqual([]int{0, 0}, policyChanges) || !reflect.DeepEqual([][]string{{}, {}}, changedNamespaces) {
+		t.Fatalf("Expected no notifications before sync, got %v and %v", policyChanges, changedNamespaces)
+	}
+
+	policyController.synced = true
+	m.notifyPoliciesChanged()
+	m.notifyNamespaceChanged("ns1")
+	if !reflect.DeepEqual([]int{1, 1}, policyChanges) || !reflect.DeepEqual([][]string{{"ns1"}, {"ns1"}}, changedNamespaces) {
+		t.Fatalf("Expected every listener to be notified, got %v and %v", policyChanges, changedNamespaces)
+	}
+
+	removeListeners[0]()
+	m.notifyPoliciesChanged()
+	m.notifyNamespaceChanged("ns2")
+	if !reflect.DeepEqual([]int{1, 2}, policyChanges) || !reflect.DeepEqual([][]string{{"ns1"}, {"ns1", "ns2"}}, changedNamespaces) {
+		t.Fatalf("Expected only the remaining listener to be notified, got %v and %v", policyChanges, changedNamespaces)
+	}
+}
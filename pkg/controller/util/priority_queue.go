@@ -0,0 +1,154 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// priorityQueue is a work queue of qualified names that hands out
+// high priority items before low priority ones.  As for a
+// workqueue.Interface, an item is processed by at most one worker at a
+// time and an item added while it is being processed is queued again
+// once it is done.
+type priorityQueue struct {
+	cond *sync.Cond
+
+	// Queued keys, in order of addition.
+	high []string
+	low  []string
+
+	// Whether each key that is queued or needs to be queued once it
+	// is done being processed is high priority.
+	dirty map[string]bool
+	// The qualified names of dirty or processing keys.
+	names map[string]QualifiedName
+	// Keys being processed.
+	processing sets.String
+
+	shuttingDown bool
+}
+
+func newPriorityQueue() *priorityQueue {
+	return &priorityQueue{
+		cond:       sync.NewCond(&sync.Mutex{}),
+		dirty:      make(map[string]bool),
+		names:      make(map[string]QualifiedName),
+		processing: sets.NewString(),
+	}
+}
+
+// Add queues the given name.  A queued low priority name is promoted if
+// it is added with high priority.
+func (q *priorityQueue) Add(qualifiedName QualifiedName, highPriority bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if q.shuttingDown {
+		return
+	}
+
+	key := qualifiedName.String()
+	if wasHigh, dirty := q.dirty[key]; dirty {
+		if !highPriority || wasHigh {
+			return
+		}
+		q.dirty[key] = true
+		if !q.processing.Has(key) {
+			q.low = removeKey(q.low, key)
+			q.high = append(q.high, key)
+		}
+		return
+	}
+
+	q.dirty[key] = highPriority
+	q.names[key] = qualifiedName
+	if q.processing.Has(key) {
+		return
+	}
+	q.push(key, highPriority)
+}
+
+func (q *priorityQueue) push(key string, highPriority bool) {
+	if highPriority {
+		q.high = append(q.high, key)
+	} else {
+		q.low = append(q.low, key)
+	}
+	q.cond.Signal()
+}
+
+// Get blocks until a name can be processed and returns it, or returns
+// true if the queue is shutting down.
+func (q *priorityQueue) Get() (QualifiedName, bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	for len(q.high) == 0 && len(q.low) == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+	var key string
+	switch {
+	case len(q.high) > 0:
+		key, q.high = q.high[0], q.high[1:]
+	case len(q.low) > 0:
+		key, q.low = q.low[0], q.low[1:]
+	default:
+		return QualifiedName{}, true
+	}
+	q.processing.Insert(key)
+	delete(q.dirty, key)
+	return q.names[key], false
+}
+
+// Done indicates that processing of the given name is complete.
+func (q *priorityQueue) Done(qualifiedName QualifiedName) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	key := qualifiedName.String()
+	q.processing.Delete(key)
+	if highPriority, dirty := q.dirty[key]; dirty {
+		q.push(key, highPriority)
+		return
+	}
+	delete(q.names, key)
+}
+
+// Len returns the number of queued names.
+func (q *priorityQueue) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return len(q.high) + len(q.low)
+}
+
+// ShutDown causes Get to return once the queue is empty.
+func (q *priorityQueue) ShutDown() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
+
+func removeKey(keys []string, key string) []string {
+	for i, k := range keys {
+		if k == key {
+			return append(keys[:i], keys[i+1:]...)
+		}
+	}
+	return keys
+}
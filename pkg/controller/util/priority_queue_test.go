@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	name := func(n string) QualifiedName {
+		return QualifiedName{Namespace: "ns", Name: n}
+	}
+	type add struct {
+		name         string
+		highPriority bool
+	}
+
+	testCases := map[string]struct {
+		adds          []add
+		expectedOrder []string
+	}{
+		"high priority first": {
+			adds:          []add{{"a", false}, {"b", true}, {"c", false}, {"d", true}},
+			expectedOrder: []string{"b", "d", "a", "c"},
+		},
+		"duplicates ignored": {
+			adds:          []add{{"a", false}, {"a", false}, {"b", true}, {"b", true}},
+			expectedOrder: []string{"b", "a"},
+		},
+		"low priority promoted": {
+			adds:          []add{{"a", false}, {"b", false}, {"c", true}, {"b", true}},
+			expectedOrder: []string{"c", "b", "a"},
+		},
+		"high priority not demoted": {
+			adds:          []add{{"a", false}, {"b", true}, {"b", false}},
+			expectedOrder: []string{"b", "a"},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			q := newPriorityQueue()
+			for _, a := range testCase.adds {
+				q.Add(name(a.name), a.highPriority)
+			}
+			order := []string{}
+			for q.Len() > 0 {
+				qualifiedName, _ := q.Get()
+				order = append(order, qualifiedName.Name)
+				q.Done(qualifiedName)
+			}
+			if !reflect.DeepEqual(testCase.expectedOrder, order) {
+				t.Fatalf("Expected order %v, got %v", testCase.expectedOrder, order)
+			}
+		})
+	}
+}
+
+func TestPriorityQueueRequeuesWhileProcessing(t *testing.T) {
+	q := newPriorityQueue()
+	a := QualifiedName{Namespace: "ns", Name: "a"}
+	q.Add(a, false)
+	qualifiedName, _ := q.Get()
+
+	// An item added while being processed is not handed out until
+	// processing is done.
+	q.Add(a, true)
+	if q.Len() != 0 {
+		t.Fatalf("Expected no queued items while processing, got %d", q.Len())
+	}
+	q.Done(qualifiedName)
+	if q.Len() != 1 {
+		t.Fatalf("Expected the item to be queued once done, got %d items", q.Len())
+	}
+
+	q.ShutDown()
+	if _, shutdown := q.Get(); shutdown {
+		t.Fatalf("Expected queued items to be handed out after shutdown")
+	}
+	if _, shutdown := q.Get(); !shutdown {
+		t.Fatalf("Expected shutdown once the queue is empty")
+	}
+}
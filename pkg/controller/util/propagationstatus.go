@@ -0,0 +1,229 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type PropagationConditionType string
+
+// The conditions describing the outcome of propagating a federated
+// resource to the clusters selected by its placement.
+const (
+	// All selected clusters have the desired state of the resource.
+	PropagationConditionPropagated PropagationConditionType = "Propagated"
+	// Some but not all selected clusters have the desired state of
+	// the resource.
+	PropagationConditionPartiallyPropagated PropagationConditionType = "PartiallyPropagated"
+	// Propagation failed for the resource or for at least one
+	// cluster.
+	PropagationConditionFailed PropagationConditionType = "Failed"
+	// The resource in at least one cluster was changed in the
+	// cluster and the change was not corrected.
+	PropagationConditionDrifted PropagationConditionType = "Drifted"
+)
+
+// propagationConditionTypes are the types of the conditions written
+// by the sync controller.
+var propagationConditionTypes = map[PropagationConditionType]bool{
+	PropagationConditionPropagated:          true,
+	PropagationConditionPartiallyPropagated: true,
+	PropagationConditionFailed:              true,
+	PropagationConditionDrifted:             true,
+}
+
+// IsPropagationCondition indicates whether a condition of the given
+// type is part of the propagation status.  Other conditions may be
+// written alongside the propagation conditions to the status
+// subresource of a federated resource by the status controller.
+func IsPropagationCondition(conditionType string) bool {
+	return propagationConditionTypes[PropagationConditionType(conditionType)]
+}
+
+type ClusterPropagationState string
+
+const (
+	// The resource in the cluster has the desired state.
+	ClusterPropagationPlaced ClusterPropagationState = "Placed"
+	// An operation to propagate the resource to the cluster has not
+	// yet completed.
+	ClusterPropagationPending ClusterPropagationState = "Pending"
+	// An operation to propagate the resource to the cluster failed.
+	ClusterPropagationUpdateFailed ClusterPropagationState = "UpdateFailed"
+	// The cluster is not ready so the resource in the cluster cannot
+	// be reconciled.
+	ClusterPropagationClusterNotReady ClusterPropagationState = "ClusterNotReady"
+	// The resource was removed from a cluster that is no longer
+	// selected.
+	ClusterPropagationDeleted ClusterPropagationState = "Deleted"
+	// The resource was changed or removed in the cluster and the drift
+	// policy of the resource prevented the change from being
+	// corrected.
+	ClusterPropagationDrifted ClusterPropagationState = "Drifted"
+	// A resource with the same name that is not managed by federation
+	// exists in the cluster and the conflict policy of the resource
+	// prevented it from being adopted.
+	ClusterPropagationAlreadyExists ClusterPropagationState = "AlreadyExists"
+	// The resource was left in a cluster that is no longer selected
+	// and is no longer managed by federation.
+	ClusterPropagationOrphaned ClusterPropagationState = "Orphaned"
+	// The resource was left unchanged in a cluster that is no longer
+	// selected.
+	ClusterPropagationRetained ClusterPropagationState = "Retained"
+	// The resource has not been created in the cluster because at
+	// least one of its dependencies does not yet exist there.
+	ClusterPropagationWaitingForDependencies ClusterPropagationState = "WaitingForDependencies"
+	// Propagation of the resource is paused and the operation needed
+	// to reconcile the resource in the cluster was not performed.
+	ClusterPropagationPaused ClusterPropagationState = "Paused"
+	// The placement of the resource selects the cluster but a
+	// PlacementPolicy does not allow the resource to be placed there.
+	ClusterPropagationDenied ClusterPropagationState = "Denied"
+)
+
+// PropagationCondition describes an aspect of the propagation of a
+// federated resource.
+type PropagationCondition struct {
+	Type               PropagationConditionType `json:"type"`
+	Status             apiv1.ConditionStatus    `json:"status"`
+	Reason             string                   `json:"reason,omitempty"`
+	Message            string                   `json:"message,omitempty"`
+	LastTransitionTime metav1.Time              `json:"lastTransitionTime,omitempty"`
+}
+
+// ClusterPropagationStatus describes the propagation of a federated
+// resource to a single cluster.
+type ClusterPropagationStatus struct {
+	Cluster            string                  `json:"cluster"`
+	State              ClusterPropagationState `json:"state"`
+	Reason             string                  `json:"reason,omitempty"`
+	LastTransitionTime metav1.Time             `json:"lastTransitionTime,omitempty"`
+	// The fields that differ from the desired state if the state is
+	// Drifted.
+	DriftedFields []DriftedField `json:"driftedFields,omitempty"`
+}
+
+// PropagationStatus is the status written to a federated resource by
+// the sync controller.
+type PropagationStatus struct {
+	// The generation of the resource that was most recently
+	// reconciled.  The conditions and cluster states describe the
+	// propagation of this generation.  Only reported if the status
+	// is written to the status subresource, since a regular update
+	// of the status increments the generation.
+	ObservedGeneration int64                      `json:"observedGeneration,omitempty"`
+	Conditions         []PropagationCondition     `json:"conditions,omitempty"`
+	Clusters           []ClusterPropagationStatus `json:"clusters,omitempty"`
+	// The value of the correct-drift annotation for which drift was
+	// last corrected.
+	CorrectedDrift string `json:"correctedDrift,omitempty"`
+	// The progress of the rollout of the resource if it specifies a
+	// rollout strategy.
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+	// The name of the ControllerRevision recording the current
+	// template and overrides of the resource, if revision history is
+	// enabled for its type.
+	CurrentRevision string `json:"currentRevision,omitempty"`
+	// The last automatic rollback of the resource.
+	Rollback *RollbackStatus `json:"rollback,omitempty"`
+	// The failure of the current revision that will trigger an
+	// automatic rollback if it persists.
+	RevisionFailure *RevisionFailure `json:"revisionFailure,omitempty"`
+}
+
+type GenericPropagationStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status *PropagationStatus `json:"status,omitempty"`
+}
+
+// GetPropagationStatus returns the propagation status of the given
+// federated resource.  An empty status is returned if the resource
+// does not have a status.
+func GetPropagationStatus(fedObject *unstructured.Unstructured) (*PropagationStatus, error) {
+	status := GenericPropagationStatus{}
+	err := UnstructuredToInterface(fedObject, &status)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error retrieving propagation status")
+	}
+	if status.Status == nil {
+		return &PropagationStatus{}, nil
+	}
+	conditions := status.Status.Conditions
+	status.Status.Conditions = nil
+	for _, condition := range conditions {
+		if IsPropagationCondition(string(condition.Type)) {
+			status.Status.Conditions = append(status.Status.Conditions, condition)
+		}
+	}
+	return status.Status, nil
+}
+
+// SetPropagationStatus sets the status field of the given federated
+// resource.  The status collected from member clusters and its
+// conditions are retained if the existing status includes them.
+func SetPropagationStatus(fedObject *unstructured.Unstructured, status *PropagationStatus) error {
+	content, err := json.Marshal(status)
+	if err != nil {
+		return errors.Wrap(err, "Error marshalling propagation status")
+	}
+	statusMap := make(map[string]interface{})
+	err = json.Unmarshal(content, &statusMap)
+	if err != nil {
+		return errors.Wrap(err, "Error unmarshalling propagation status")
+	}
+	if existing, ok := fedObject.Object[StatusField].(map[string]interface{}); ok {
+		for _, field := range []string{ClusterStatusField, AggregatedStatusField} {
+			if value, ok := existing[field]; ok {
+				statusMap[field] = value
+			}
+		}
+		conditions, _ := statusMap[ConditionsField].([]interface{})
+		statusMap[ConditionsField] = existing[ConditionsField]
+		SetStatusConditions(statusMap, conditions, IsPropagationCondition)
+	}
+	fedObject.Object[StatusField] = statusMap
+	return nil
+}
+
+// Condition returns the condition of the given type.
+func (s *PropagationStatus) Condition(conditionType PropagationConditionType) *PropagationCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == conditionType {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// ClusterStatus returns the status for the named cluster.
+func (s *PropagationStatus) ClusterStatus(clusterName string) *ClusterPropagationStatus {
+	for i := range s.Clusters {
+		if s.Clusters[i].Cluster == clusterName {
+			return &s.Clusters[i]
+		}
+	}
+	return nil
+}
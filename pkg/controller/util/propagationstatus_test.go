@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSetPropagationStatus(t *testing.T) {
+	healthy := map[string]interface{}{"type": "Healthy", "status": "True"}
+	clusterStatus := []interface{}{map[string]interface{}{"clusterName": "c1", "state": "Present"}}
+	propagationStatus := &PropagationStatus{
+		ObservedGeneration: 2,
+		Conditions: []PropagationCondition{{
+			Type:   PropagationConditionPropagated,
+			Status: apiv1.ConditionTrue,
+		}},
+	}
+
+	testCases := map[string]struct {
+		existingStatus map[string]interface{}
+		expectedStatus map[string]interface{}
+	}{
+		"status is set": {
+			expectedStatus: map[string]interface{}{
+				"observedGeneration": float64(2),
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Propagated", "status": "True", "lastTransitionTime": nil},
+				},
+			},
+		},
+		"propagation conditions are replaced": {
+			existingStatus: map[string]interface{}{
+				"observedGeneration": int64(1),
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Failed", "status": "True"},
+				},
+			},
+			expectedStatus: map[string]interface{}{
+				"observedGeneration": float64(2),
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Propagated", "status": "True", "lastTransitionTime": nil},
+				},
+			},
+		},
+		"collected status and its conditions are retained": {
+			existingStatus: map[string]interface{}{
+				"observedGeneration": int64(1),
+				"clusterStatus":      clusterStatus,
+				"conditions": []interface{}{
+					healthy,
+					map[string]interface{}{"type": "Failed", "status": "True"},
+				},
+			},
+			expectedStatus: map[string]interface{}{
+				"observedGeneration": float64(2),
+				"clusterStatus":      clusterStatus,
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Propagated", "status": "True", "lastTransitionTime": nil},
+					healthy,
+				},
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			if testCase.existingStatus != nil {
+				fedObject.Object[StatusField] = testCase.existingStatus
+			}
+			err := SetPropagationStatus(fedObject, propagationStatus)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(testCase.expectedStatus, fedObject.Object[StatusField]) {
+				t.Fatalf("Expected %v, got %v", testCase.expectedStatus, fedObject.Object[StatusField])
+			}
+
+			status, err := GetPropagationStatus(fedObject)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(propagationStatus, status) {
+				t.Fatalf("Expected propagation status %v, got %v", propagationStatus, status)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	RemovalPolicyField = "removalPolicy"
+)
+
+// RemovalPolicy determines what happens to the target resource in a
+// member cluster when the cluster is no longer selected by the
+// placement of a federated resource or the federated resource is
+// deleted.  The target resource of an Orphan or Retain policy is
+// orphaned when its cluster is unjoined.
+type RemovalPolicy string
+
+const (
+	// RemovalPolicyDelete deletes the target resource.
+	RemovalPolicyDelete RemovalPolicy = "Delete"
+	// RemovalPolicyOrphan leaves the target resource in the cluster
+	// and removes the label marking it as managed by federation.
+	RemovalPolicyOrphan RemovalPolicy = "Orphan"
+	// RemovalPolicyRetain leaves the target resource in the cluster
+	// unchanged.  It remains labeled as managed by federation so that
+	// it is updated without regard to the conflict policy if the
+	// cluster is selected again.
+	RemovalPolicyRetain RemovalPolicy = "Retain"
+)
+
+type GenericRemovalSpec struct {
+	RemovalPolicy RemovalPolicy `json:"removalPolicy,omitempty"`
+}
+
+type GenericRemoval struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GenericRemovalSpec `json:"spec,omitempty"`
+}
+
+// GetRemovalPolicy returns the removal policy of the given federated
+// resource.  Defaults to Delete.
+func GetRemovalPolicy(fedObject *unstructured.Unstructured) (RemovalPolicy, error) {
+	removal := GenericRemoval{}
+	err := UnstructuredToInterface(fedObject, &removal)
+	if err != nil {
+		return "", errors.Wrap(err, "Error retrieving removal policy")
+	}
+	switch policy := removal.Spec.RemovalPolicy; policy {
+	case "":
+		return RemovalPolicyDelete, nil
+	case RemovalPolicyDelete, RemovalPolicyOrphan, RemovalPolicyRetain:
+		return policy, nil
+	default:
+		return "", errors.Errorf("Invalid removal policy %q: must be %q, %q or %q", policy,
+			RemovalPolicyDelete, RemovalPolicyOrphan, RemovalPolicyRetain)
+	}
+}
+
+// OrphanedObject returns a copy of the given target resource without
+// the metadata added by federation, for use in updating the resource
+// in its member cluster so that it is no longer managed.
+func OrphanedObject(obj pkgruntime.Object) (pkgruntime.Object, error) {
+	orphanedObj := obj.DeepCopyObject()
+	metaObj, err := meta.Accessor(orphanedObj)
+	if err != nil {
+		return nil, err
+	}
+	labels := metaObj.GetLabels()
+	delete(labels, ManagedByFederationLabel)
+	metaObj.SetLabels(labels)
+	annotations := metaObj.GetAnnotations()
+	delete(annotations, LastAppliedConfigurationAnnotation)
+	metaObj.SetAnnotations(annotations)
+	return orphanedObj, nil
+}
+
+// OrphanPatch returns a merge patch that removes the metadata added by
+// federation from a target resource.  Patching rather than updating
+// the resource ensures that a cached resource lacking fields (e.g.
+// due to trimming) does not clear those fields in its member cluster.
+func OrphanPatch() (types.PatchType, []byte, error) {
+	patch, err := json.Marshal(map[string]interface{}{
+		MetadataField: map[string]interface{}{
+			"labels": map[string]interface{}{
+				ManagedByFederationLabel: nil,
+			},
+			"annotations": map[string]interface{}{
+				LastAppliedConfigurationAnnotation: nil,
+			},
+		},
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return types.MergePatchType, patch, nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestGetRemovalPolicy(t *testing.T) {
+	testCases := map[string]struct {
+		spec           string
+		expectedPolicy RemovalPolicy
+		expectedErr    bool
+	}{
+		"default policy": {
+			spec:           `{}`,
+			expectedPolicy: RemovalPolicyDelete,
+		},
+		"explicit policy": {
+			spec:           `{"removalPolicy": "Orphan"}`,
+			expectedPolicy: RemovalPolicyOrphan,
+		},
+		"invalid policy": {
+			spec:        `{"removalPolicy": "Ignore"}`,
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := unstructuredFromJSON(t, `{"apiVersion": "core.federation.k8s.io/v1alpha1", "kind": "FederatedConfigMap", "metadata": {"name": "foo"}, "spec": `+testCase.spec+`}`)
+			policy, err := GetRemovalPolicy(fedObject)
+			if testCase.expectedErr != (err != nil) {
+				t.Fatalf("Expected error to be %v, got %v", testCase.expectedErr, err)
+			}
+			if policy != testCase.expectedPolicy {
+				t.Fatalf("Expected policy %q, got %q", testCase.expectedPolicy, policy)
+			}
+		})
+	}
+}
+
+func TestOrphanedObject(t *testing.T) {
+	clusterObj := unstructuredFromJSON(t, `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "foo", "labels": {"app": "foo", "federation.k8s.io/managed": "true"}, "annotations": {"federation.k8s.io/last-applied-configuration": "{}", "note": "kept"}}, "data": {"a": "1"}}`)
+	expectedObj := unstructuredFromJSON(t, `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "foo", "labels": {"app": "foo"}, "annotations": {"note": "kept"}}, "data": {"a": "1"}}`)
+
+	orphanedObj, err := OrphanedObject(clusterObj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(expectedObj, orphanedObj) {
+		t.Fatalf("Expected %v, got %v", expectedObj.Object, orphanedObj.(*unstructured.Unstructured).Object)
+	}
+	if !IsManagedByFederation(clusterObj) {
+		t.Fatalf("Expected the original object not to be modified")
+	}
+}
+
+func TestOrphanPatch(t *testing.T) {
+	patchType, patch, err := OrphanPatch()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if patchType != types.MergePatchType {
+		t.Fatalf("Expected patch type %q, got %q", types.MergePatchType, patchType)
+	}
+	expectedPatch := `{"metadata":{"annotations":{"federation.k8s.io/last-applied-configuration":null},"labels":{"federation.k8s.io/managed":null}}}`
+	if string(patch) != expectedPatch {
+		t.Fatalf("Expected patch %s, got %s", expectedPatch, patch)
+	}
+}
@@ -26,6 +26,9 @@ import (
 type ResourceClient interface {
 	Resources(namespace string) dynamic.ResourceInterface
 	Kind() string
+	// ForResource returns a client for the given resource that
+	// shares the connection of this client.
+	ForResource(apiResource *metav1.APIResource) ResourceClient
 }
 
 type resourceClient struct {
@@ -36,22 +39,25 @@ type resourceClient struct {
 }
 
 func NewResourceClient(config *rest.Config, apiResource *metav1.APIResource) (ResourceClient, error) {
-	resource := schema.GroupVersionResource{
-		Group:    apiResource.Group,
-		Version:  apiResource.Version,
-		Resource: apiResource.Name,
-	}
 	client, err := dynamic.NewForConfig(config)
 	if err != nil {
 		return nil, err
 	}
 
+	return newResourceClient(client, apiResource), nil
+}
+
+func newResourceClient(client dynamic.Interface, apiResource *metav1.APIResource) *resourceClient {
 	return &resourceClient{
-		client:      client,
-		apiResource: resource,
-		namespaced:  apiResource.Namespaced,
-		kind:        apiResource.Kind,
-	}, nil
+		client: client,
+		apiResource: schema.GroupVersionResource{
+			Group:    apiResource.Group,
+			Version:  apiResource.Version,
+			Resource: apiResource.Name,
+		},
+		namespaced: apiResource.Namespaced,
+		kind:       apiResource.Kind,
+	}
 }
 
 func (c *resourceClient) Resources(namespace string) dynamic.ResourceInterface {
@@ -68,3 +74,7 @@ func (c *resourceClient) Resources(namespace string) dynamic.ResourceInterface {
 func (c *resourceClient) Kind() string {
 	return c.kind
 }
+
+func (c *resourceClient) ForResource(apiResource *metav1.APIResource) ResourceClient {
+	return newResourceClient(c.client, apiResource)
+}
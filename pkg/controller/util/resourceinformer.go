@@ -20,6 +20,7 @@ import (
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	pkgruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/watch"
@@ -42,6 +43,45 @@ func NewResourceInformer(client ResourceClient, namespace string, triggerFunc fu
 	)
 }
 
+// NewManagedResourceInformer returns an informer for the resources
+// labeled as managed by federation that caches the result of trimming
+// each resource with the given function.
+func NewManagedResourceInformer(client ResourceClient, namespace string, trimFunc TrimFunc, triggerFunc func(pkgruntime.Object)) (cache.Store, cache.Controller) {
+	labelSelector := labels.SelectorFromSet(labels.Set{ManagedByFederationLabel: ManagedByFederationLabelValue}).String()
+	trimEvent := func(event watch.Event) (watch.Event, bool) {
+		if obj, ok := event.Object.(*unstructured.Unstructured); ok {
+			event.Object = trimFunc(obj)
+		}
+		return event, true
+	}
+	return cache.NewInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (pkgruntime.Object, error) {
+				options.LabelSelector = labelSelector
+				list, err := client.Resources(namespace).List(options)
+				if err != nil {
+					return nil, err
+				}
+				for i := range list.Items {
+					list.Items[i] = *trimFunc(&list.Items[i])
+				}
+				return list, nil
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				options.LabelSelector = labelSelector
+				w, err := client.Resources(namespace).Watch(options)
+				if err != nil {
+					return nil, err
+				}
+				return watch.Filter(w, trimEvent), nil
+			},
+		},
+		nil, // Skip checks for expected type since the type will depend on the client
+		NoResyncPeriod,
+		NewTriggerOnAllChanges(triggerFunc),
+	)
+}
+
 func ObjFromCache(store cache.Store, kind, key string) (*unstructured.Unstructured, error) {
 	obj, err := rawObjFromCache(store, kind, key)
 	if err != nil {
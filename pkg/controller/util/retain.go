@@ -0,0 +1,233 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+const listPathSuffix = "[]"
+
+// defaultRetainedFields are the fields that are always retained for a
+// given target kind, regardless of the retained fields configured for
+// the type.
+var defaultRetainedFields = map[string][]fedv1a1.RetainedField{
+	// ClusterIP and NodePort are allocated to Service by cluster, so
+	// retain the same if any while updating.
+	ServiceKind: {
+		{
+			Path: "spec.clusterIP",
+		},
+		{
+			Path:      "spec.ports[].nodePort",
+			MatchKeys: []string{"name", "protocol", "port"},
+		},
+	},
+	// Retain the secrets of a service account if the desired
+	// representation does not include a value for the field.  This
+	// ensures that the sync controller doesn't continually clear a
+	// generated secret from a service account, prompting continual
+	// regeneration by the service account controller in the member
+	// cluster.
+	//
+	// TODO(marun) Clearing a manually-set secrets field will require
+	// resetting placement.  Is there a better way to do this?
+	ServiceAccountKind: {
+		{
+			Path:        SecretsField,
+			OnlyIfUnset: true,
+		},
+	},
+}
+
+// RetainedFieldsForType returns the fields that should be retained
+// for target resources of the given kind: the defaults for the kind
+// followed by the configured fields.
+func RetainedFieldsForType(targetKind string, configuredFields []fedv1a1.RetainedField) ([]fedv1a1.RetainedField, error) {
+	retainedFields := append([]fedv1a1.RetainedField{}, defaultRetainedFields[targetKind]...)
+	for i, field := range configuredFields {
+		if _, err := parseRetainedFieldPath(field.Path); err != nil {
+			return nil, errors.Wrapf(err, "retainedFields[%d] is invalid", i)
+		}
+		retainedFields = append(retainedFields, field)
+	}
+	return retainedFields, nil
+}
+
+type retainedPathElement struct {
+	name string
+	list bool
+}
+
+func parseRetainedFieldPath(path string) ([]retainedPathElement, error) {
+	if len(path) == 0 {
+		return nil, errors.New("The path must not be empty")
+	}
+	elements := []retainedPathElement{}
+	for _, name := range strings.Split(path, ".") {
+		element := retainedPathElement{name: name}
+		if strings.HasSuffix(name, listPathSuffix) {
+			element.name = strings.TrimSuffix(name, listPathSuffix)
+			element.list = true
+		}
+		if len(element.name) == 0 {
+			return nil, errors.Errorf("The path %q contains an empty element", path)
+		}
+		elements = append(elements, element)
+	}
+	if elements[len(elements)-1].list {
+		return nil, errors.Errorf("The path %q must not end with a list", path)
+	}
+	return elements, nil
+}
+
+// RetainFields sets the value of each of the given fields in the
+// desired object to the value in the cluster object.  A value is only
+// retained if the value in the cluster object is not empty (i.e. null
+// or an empty string, list or object).  If a field specifies
+// OnlyIfUnset, the value is only retained if the value in the desired
+// object is empty.
+func RetainFields(desiredObj, clusterObj *unstructured.Unstructured, fields []fedv1a1.RetainedField) error {
+	for _, field := range fields {
+		elements, err := parseRetainedFieldPath(field.Path)
+		if err != nil {
+			return err
+		}
+		err = retainField(desiredObj.Object, clusterObj.Object, elements, field)
+		if err != nil {
+			return errors.Wrapf(err, "Error retaining %q", field.Path)
+		}
+	}
+	return nil
+}
+
+func retainField(desired, cluster map[string]interface{}, elements []retainedPathElement, field fedv1a1.RetainedField) error {
+	element := elements[0]
+	clusterValue, ok := cluster[element.name]
+	if !ok || isEmptyValue(clusterValue) {
+		return nil
+	}
+
+	if len(elements) == 1 {
+		if field.OnlyIfUnset && !isEmptyValue(desired[element.name]) {
+			return nil
+		}
+		desired[element.name] = pkgruntime.DeepCopyJSONValue(clusterValue)
+		return nil
+	}
+
+	if !element.list {
+		clusterMap, ok := clusterValue.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("Expected %q to be an object, got %T", element.name, clusterValue)
+		}
+		desiredValue, ok := desired[element.name]
+		if !ok || desiredValue == nil {
+			// Only add the containing object to the desired object
+			// if a value was retained.
+			desiredMap := make(map[string]interface{})
+			err := retainField(desiredMap, clusterMap, elements[1:], field)
+			if err == nil && len(desiredMap) > 0 {
+				desired[element.name] = desiredMap
+			}
+			return err
+		}
+		desiredMap, ok := desiredValue.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("Expected %q to be an object, got %T", element.name, desiredValue)
+		}
+		return retainField(desiredMap, clusterMap, elements[1:], field)
+	}
+
+	clusterList, ok := clusterValue.([]interface{})
+	if !ok {
+		return errors.Errorf("Expected %q to be a list, got %T", element.name, clusterValue)
+	}
+	desiredList, ok := desired[element.name].([]interface{})
+	if !ok {
+		// Elements can only be retained for a list present in the
+		// desired object.
+		return nil
+	}
+	for i, rawDesiredElement := range desiredList {
+		desiredElement, ok := rawDesiredElement.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("Expected the elements of %q to be objects, got %T", element.name, rawDesiredElement)
+		}
+		clusterElement := matchingListElement(clusterList, i, desiredElement, field.MatchKeys)
+		if clusterElement == nil {
+			continue
+		}
+		err := retainField(desiredElement, clusterElement, elements[1:], field)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchingListElement returns the element of the given list that
+// matches the desired element by the given keys, or by index if no
+// keys are provided.
+func matchingListElement(list []interface{}, index int, desiredElement map[string]interface{}, keys []string) map[string]interface{} {
+	if len(keys) == 0 {
+		if index >= len(list) {
+			return nil
+		}
+		element, _ := list[index].(map[string]interface{})
+		return element
+	}
+	for _, rawElement := range list {
+		element, ok := rawElement.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		matches := true
+		for _, key := range keys {
+			if !reflect.DeepEqual(element[key], desiredElement[key]) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return element
+		}
+	}
+	return nil
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
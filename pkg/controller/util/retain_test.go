@@ -0,0 +1,163 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+func TestRetainFields(t *testing.T) {
+	testCases := map[string]struct {
+		kind        string
+		fields      []fedv1a1.RetainedField
+		desiredObj  string
+		clusterObj  string
+		expectedObj string
+		expectedErr bool
+	}{
+		"service retains clusterIP and matching nodePorts": {
+			kind:        ServiceKind,
+			desiredObj:  `{"spec": {"ports": [{"name": "http", "protocol": "TCP", "port": 80}, {"name": "https", "protocol": "TCP", "port": 443}]}}`,
+			clusterObj:  `{"spec": {"clusterIP": "10.0.0.1", "ports": [{"name": "https", "protocol": "TCP", "port": 443, "nodePort": 30443}, {"name": "http", "protocol": "TCP", "port": 8080, "nodePort": 30080}]}}`,
+			expectedObj: `{"spec": {"clusterIP": "10.0.0.1", "ports": [{"name": "http", "protocol": "TCP", "port": 80}, {"name": "https", "protocol": "TCP", "port": 443, "nodePort": 30443}]}}`,
+		},
+		"service without an allocated clusterIP": {
+			kind:        ServiceKind,
+			desiredObj:  `{"spec": {"type": "ClusterIP"}}`,
+			clusterObj:  `{"spec": {"clusterIP": ""}}`,
+			expectedObj: `{"spec": {"type": "ClusterIP"}}`,
+		},
+		"service account retains unset secrets": {
+			kind:        ServiceAccountKind,
+			desiredObj:  `{"metadata": {"name": "foo"}}`,
+			clusterObj:  `{"metadata": {"name": "foo"}, "secrets": [{"name": "foo-token"}]}`,
+			expectedObj: `{"metadata": {"name": "foo"}, "secrets": [{"name": "foo-token"}]}`,
+		},
+		"service account does not retain set secrets": {
+			kind:        ServiceAccountKind,
+			desiredObj:  `{"secrets": [{"name": "bar"}]}`,
+			clusterObj:  `{"secrets": [{"name": "foo-token"}]}`,
+			expectedObj: `{"secrets": [{"name": "bar"}]}`,
+		},
+		"configured field absent from the desired object": {
+			fields: []fedv1a1.RetainedField{
+				{Path: "spec.template.metadata.annotations"},
+			},
+			desiredObj:  `{"spec": {"replicas": 1}}`,
+			clusterObj:  `{"spec": {"template": {"metadata": {"annotations": {"foo": "bar"}}}}}`,
+			expectedObj: `{"spec": {"replicas": 1, "template": {"metadata": {"annotations": {"foo": "bar"}}}}}`,
+		},
+		"configured field absent from the cluster object": {
+			fields: []fedv1a1.RetainedField{
+				{Path: "spec.template.metadata.annotations"},
+			},
+			desiredObj:  `{"spec": {"replicas": 1}}`,
+			clusterObj:  `{"spec": {"template": {"metadata": {"labels": {"foo": "bar"}}}}}`,
+			expectedObj: `{"spec": {"replicas": 1}}`,
+		},
+		"configured list field matched by index": {
+			fields: []fedv1a1.RetainedField{
+				{Path: "spec.containers[].image"},
+			},
+			desiredObj:  `{"spec": {"containers": [{"name": "a", "image": "a:1"}, {"name": "b"}]}}`,
+			clusterObj:  `{"spec": {"containers": [{"name": "a", "image": "a:2"}]}}`,
+			expectedObj: `{"spec": {"containers": [{"name": "a", "image": "a:2"}, {"name": "b"}]}}`,
+		},
+		"configured list field only if unset": {
+			fields: []fedv1a1.RetainedField{
+				{Path: "spec.containers[].image", MatchKeys: []string{"name"}, OnlyIfUnset: true},
+			},
+			desiredObj:  `{"spec": {"containers": [{"name": "a", "image": "a:1"}, {"name": "b"}]}}`,
+			clusterObj:  `{"spec": {"containers": [{"name": "b", "image": "b:2"}, {"name": "a", "image": "a:2"}]}}`,
+			expectedObj: `{"spec": {"containers": [{"name": "a", "image": "a:1"}, {"name": "b", "image": "b:2"}]}}`,
+		},
+		"path through a non-object value": {
+			fields: []fedv1a1.RetainedField{
+				{Path: "spec.replicas.value"},
+			},
+			desiredObj:  `{"spec": {"replicas": 1}}`,
+			clusterObj:  `{"spec": {"replicas": {"value": 1}}}`,
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fields, err := RetainedFieldsForType(testCase.kind, testCase.fields)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			desiredObj := unstructuredFromJSON(t, testCase.desiredObj)
+			clusterObj := unstructuredFromJSON(t, testCase.clusterObj)
+			err = RetainFields(desiredObj, clusterObj, fields)
+			if testCase.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			expectedObj := unstructuredFromJSON(t, testCase.expectedObj)
+			if !reflect.DeepEqual(expectedObj.Object, desiredObj.Object) {
+				t.Fatalf("Expected %v, got %v", expectedObj.Object, desiredObj.Object)
+			}
+		})
+	}
+}
+
+func TestRetainedFieldsForType(t *testing.T) {
+	testCases := map[string]struct {
+		path        string
+		expectedErr bool
+	}{
+		"field": {
+			path: "spec.replicas",
+		},
+		"list field": {
+			path: "spec.ports[].nodePort",
+		},
+		"empty path": {
+			path:        "",
+			expectedErr: true,
+		},
+		"empty element": {
+			path:        "spec..replicas",
+			expectedErr: true,
+		},
+		"path ending with a list": {
+			path:        "spec.ports[]",
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			_, err := RetainedFieldsForType("Foo", []fedv1a1.RetainedField{{Path: testCase.path}})
+			if testCase.expectedErr && err == nil {
+				t.Fatalf("Expected an error")
+			}
+			if !testCase.expectedErr && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
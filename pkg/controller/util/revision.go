@@ -0,0 +1,313 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+	kubeclient "k8s.io/client-go/kubernetes"
+)
+
+const (
+	// RevisionOwnerLabel identifies the federated resource, by uid,
+	// whose template and overrides are recorded by a
+	// ControllerRevision.
+	RevisionOwnerLabel = "federation.k8s.io/revision-owner"
+
+	AutoRollbackField = "autoRollback"
+
+	defaultFailureThresholdPercent = 50
+)
+
+// revisionFields are the fields of the spec of a federated resource
+// recorded by a revision.
+var revisionFields = []string{TemplateField, OverridesField}
+
+// RevisionData returns the JSON-encoded template and overrides of the
+// given federated resource.
+func RevisionData(fedObject *unstructured.Unstructured) ([]byte, error) {
+	spec := make(map[string]interface{})
+	for _, field := range revisionFields {
+		value, ok, err := unstructured.NestedFieldCopy(fedObject.Object, SpecField, field)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error retrieving %s", field)
+		}
+		if ok {
+			spec[field] = value
+		}
+	}
+	data, err := json.Marshal(map[string]interface{}{SpecField: spec})
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling revision")
+	}
+	return data, nil
+}
+
+// RevisionName returns the name of the ControllerRevision recording
+// the given revision data of the given federated resource.  The name
+// is derived from the data so that a given template and overrides are
+// recorded by a single revision.
+func RevisionName(fedObject *unstructured.Unstructured, data []byte) string {
+	hasher := fnv.New32a()
+	hasher.Write(data)
+	return fmt.Sprintf("%s-%x", fedObject.GetName(), hasher.Sum32())
+}
+
+// RevisionNamespace returns the namespace of the ControllerRevisions
+// of the given federated resource.  The revisions of cluster-scoped
+// resources are stored in the federation namespace.
+func RevisionNamespace(fedObject *unstructured.Unstructured, federationNamespace string) string {
+	if len(fedObject.GetNamespace()) > 0 {
+		return fedObject.GetNamespace()
+	}
+	return federationNamespace
+}
+
+// ListRevisions returns the revisions of the given federated
+// resource ordered by revision number.
+func ListRevisions(client kubeclient.Interface, namespace string, fedObject *unstructured.Unstructured) ([]*appsv1.ControllerRevision, error) {
+	revisionList, err := client.AppsV1().ControllerRevisions(namespace).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", RevisionOwnerLabel, fedObject.GetUID()),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Error listing revisions")
+	}
+	revisions := []*appsv1.ControllerRevision{}
+	for i := range revisionList.Items {
+		revisions = append(revisions, &revisionList.Items[i])
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+// RecordRevision ensures that the current template and overrides of
+// the given federated resource are recorded by its latest revision,
+// and deletes the oldest revisions in excess of the given limit.
+func RecordRevision(client kubeclient.Interface, namespace string, fedObject *unstructured.Unstructured, limit int32) (*appsv1.ControllerRevision, error) {
+	data, err := RevisionData(fedObject)
+	if err != nil {
+		return nil, err
+	}
+	name := RevisionName(fedObject, data)
+
+	revisions, err := ListRevisions(client, namespace, fedObject)
+	if err != nil {
+		return nil, err
+	}
+	revisionClient := client.AppsV1().ControllerRevisions(namespace)
+	nextRevision := int64(1)
+	var current *appsv1.ControllerRevision
+	for _, revision := range revisions {
+		if revision.Name == name {
+			current = revision
+		}
+		nextRevision = revision.Revision + 1
+	}
+
+	switch {
+	case current == nil:
+		current = &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels: map[string]string{
+					RevisionOwnerLabel: string(fedObject.GetUID()),
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: fedObject.GetAPIVersion(),
+						Kind:       fedObject.GetKind(),
+						Name:       fedObject.GetName(),
+						UID:        fedObject.GetUID(),
+					},
+				},
+			},
+			Data:     pkgruntime.RawExtension{Raw: data},
+			Revision: nextRevision,
+		}
+		current, err = revisionClient.Create(current)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error creating revision %q", name)
+		}
+		revisions = append(revisions, current)
+	case current.Revision != nextRevision-1:
+		// A previous revision was restored and becomes the latest.
+		current = current.DeepCopy()
+		current.Revision = nextRevision
+		current, err = revisionClient.Update(current)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error updating revision %q", name)
+		}
+	}
+
+	for _, revision := range revisionsToPrune(revisions, current.Name, limit) {
+		err := revisionClient.Delete(revision.Name, nil)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "Error deleting revision %q", revision.Name)
+		}
+	}
+	return current, nil
+}
+
+// revisionsToPrune returns the oldest of the given revisions, ordered
+// by revision number, in excess of the given limit.  The named current
+// revision is never pruned.
+func revisionsToPrune(revisions []*appsv1.ControllerRevision, currentName string, limit int32) []*appsv1.ControllerRevision {
+	excess := len(revisions) - int(limit)
+	prune := []*appsv1.ControllerRevision{}
+	for _, revision := range revisions {
+		if excess <= 0 {
+			break
+		}
+		if revision.Name == currentName {
+			continue
+		}
+		prune = append(prune, revision)
+		excess--
+	}
+	return prune
+}
+
+// PreviousRevision returns the most recent of the given revisions,
+// ordered by revision number, other than the named current revision.
+func PreviousRevision(revisions []*appsv1.ControllerRevision, currentName string) *appsv1.ControllerRevision {
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if revisions[i].Name != currentName {
+			return revisions[i]
+		}
+	}
+	return nil
+}
+
+// ApplyRevision sets the template and overrides of the given federated
+// resource to those recorded by the given revision.
+func ApplyRevision(fedObject *unstructured.Unstructured, revision *appsv1.ControllerRevision) error {
+	content := make(map[string]interface{})
+	err := json.Unmarshal(revision.Data.Raw, &content)
+	if err != nil {
+		return errors.Wrapf(err, "Error unmarshalling revision %q", revision.Name)
+	}
+	for _, field := range revisionFields {
+		value, ok, err := unstructured.NestedFieldNoCopy(content, SpecField, field)
+		if err != nil {
+			return errors.Wrapf(err, "Error retrieving %s from revision %q", field, revision.Name)
+		}
+		if !ok {
+			unstructured.RemoveNestedField(fedObject.Object, SpecField, field)
+			continue
+		}
+		err = unstructured.SetNestedField(fedObject.Object, value, SpecField, field)
+		if err != nil {
+			return errors.Wrapf(err, "Error setting %s", field)
+		}
+	}
+	return nil
+}
+
+// AutoRollback determines when a federated resource is automatically
+// rolled back to its previous revision.
+type AutoRollback struct {
+	// The percentage of selected clusters whose update must fail to
+	// trigger a rollback.  Defaults to 50.
+	FailureThresholdPercent *int32 `json:"failureThresholdPercent,omitempty"`
+}
+
+type GenericAutoRollbackSpec struct {
+	AutoRollback *AutoRollback `json:"autoRollback,omitempty"`
+}
+
+type GenericAutoRollback struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GenericAutoRollbackSpec `json:"spec,omitempty"`
+}
+
+// GetFailureThresholdPercent returns the failure threshold of the
+// automatic rollback of the given federated resource, or 0 if it does
+// not specify automatic rollback.
+func GetFailureThresholdPercent(fedObject *unstructured.Unstructured) (int32, error) {
+	rollback := GenericAutoRollback{}
+	err := UnstructuredToInterface(fedObject, &rollback)
+	if err != nil {
+		return 0, errors.Wrap(err, "Error retrieving automatic rollback")
+	}
+	autoRollback := rollback.Spec.AutoRollback
+	if autoRollback == nil {
+		return 0, nil
+	}
+	if autoRollback.FailureThresholdPercent == nil {
+		return defaultFailureThresholdPercent, nil
+	}
+	threshold := *autoRollback.FailureThresholdPercent
+	if threshold < 1 || threshold > 100 {
+		return 0, errors.New("failureThresholdPercent must be between 1 and 100")
+	}
+	return threshold, nil
+}
+
+// GetRollbackDeadline returns how long a failure of the given
+// federated resource must persist before it is automatically rolled
+// back.  This is the progress deadline of the rollout strategy of the
+// resource, or the default progress deadline if it does not specify
+// a rollout strategy.
+func GetRollbackDeadline(fedObject *unstructured.Unstructured) (time.Duration, error) {
+	directive, err := GetRolloutDirective(fedObject)
+	if err != nil {
+		return 0, err
+	}
+	if directive == nil {
+		return defaultProgressDeadlineSeconds * time.Second, nil
+	}
+	return directive.ProgressDeadline, nil
+}
+
+// RollbackStatus records the automatic rollback of a federated
+// resource.
+type RollbackStatus struct {
+	// The name of the revision that was rolled back.
+	FailedRevision string `json:"failedRevision"`
+	// The name of the revision that was restored.
+	RestoredRevision string `json:"restoredRevision"`
+	// Why the rollback was performed.
+	Reason string `json:"reason,omitempty"`
+	// When the rollback was performed.
+	Time metav1.Time `json:"time"`
+}
+
+// RevisionFailure records when the current failure of a revision of
+// a federated resource began, so that the failure can be rolled back
+// once it has persisted for the rollback deadline.
+type RevisionFailure struct {
+	// The name of the revision that is failing.
+	Revision string `json:"revision"`
+	// When the failure of the revision began.
+	StartTime metav1.Time `json:"startTime"`
+}
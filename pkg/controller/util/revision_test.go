@@ -0,0 +1,174 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestApplyRevision(t *testing.T) {
+	const (
+		original = `{"apiVersion": "core.federation.k8s.io/v1alpha1", "kind": "FederatedConfigMap", "metadata": {"name": "foo"}, "spec": {"template": {"data": {"a": "1"}}, "placement": {"clusterNames": ["c1"]}}}`
+		changed  = `{"apiVersion": "core.federation.k8s.io/v1alpha1", "kind": "FederatedConfigMap", "metadata": {"name": "foo"}, "spec": {"template": {"data": {"a": "2"}}, "overrides": [{"clusterName": "c1", "clusterOverrides": [{"path": "data.a", "value": "3"}]}], "placement": {"clusterNames": ["c1", "c2"]}}}`
+		expected = `{"apiVersion": "core.federation.k8s.io/v1alpha1", "kind": "FederatedConfigMap", "metadata": {"name": "foo"}, "spec": {"template": {"data": {"a": "1"}}, "placement": {"clusterNames": ["c1", "c2"]}}}`
+	)
+
+	originalObj := unstructuredFromJSON(t, original)
+	data, err := RevisionData(originalObj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	changedObj := unstructuredFromJSON(t, changed)
+	changedData, err := RevisionData(changedObj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if RevisionName(originalObj, data) == RevisionName(changedObj, changedData) {
+		t.Fatalf("Expected revisions with different content to have different names")
+	}
+
+	revision := &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: RevisionName(originalObj, data)},
+		Data:       pkgruntime.RawExtension{Raw: data},
+	}
+	err = ApplyRevision(changedObj, revision)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Placement is not part of a revision and is not restored.
+	expectedObj := unstructuredFromJSON(t, expected)
+	if !reflect.DeepEqual(expectedObj, changedObj) {
+		t.Fatalf("Expected %v, got %v", expectedObj.Object, changedObj.Object)
+	}
+	restoredData, err := RevisionData(changedObj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if RevisionName(changedObj, restoredData) != revision.Name {
+		t.Fatalf("Expected the restored resource to match revision %q", revision.Name)
+	}
+}
+
+func TestRevisionsToPrune(t *testing.T) {
+	newRevisions := func(names ...string) []*appsv1.ControllerRevision {
+		revisions := []*appsv1.ControllerRevision{}
+		for i, name := range names {
+			revisions = append(revisions, &appsv1.ControllerRevision{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Revision:   int64(i + 1),
+			})
+		}
+		return revisions
+	}
+
+	testCases := map[string]struct {
+		revisions      []*appsv1.ControllerRevision
+		current        string
+		limit          int32
+		expectedPruned []string
+	}{
+		"within limit": {
+			revisions:      newRevisions("r1", "r2"),
+			current:        "r2",
+			limit:          2,
+			expectedPruned: []string{},
+		},
+		"oldest revisions pruned": {
+			revisions:      newRevisions("r1", "r2", "r3", "r4"),
+			current:        "r4",
+			limit:          2,
+			expectedPruned: []string{"r1", "r2"},
+		},
+		"current revision retained": {
+			revisions:      newRevisions("r1", "r2", "r3"),
+			current:        "r1",
+			limit:          1,
+			expectedPruned: []string{"r2", "r3"},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			pruned := []string{}
+			for _, revision := range revisionsToPrune(testCase.revisions, testCase.current, testCase.limit) {
+				pruned = append(pruned, revision.Name)
+			}
+			if !reflect.DeepEqual(testCase.expectedPruned, pruned) {
+				t.Fatalf("Expected %v to be pruned, got %v", testCase.expectedPruned, pruned)
+			}
+		})
+	}
+}
+
+func TestPreviousRevision(t *testing.T) {
+	revisions := []*appsv1.ControllerRevision{
+		{ObjectMeta: metav1.ObjectMeta{Name: "r1"}, Revision: 1},
+		{ObjectMeta: metav1.ObjectMeta{Name: "r2"}, Revision: 2},
+	}
+	if previous := PreviousRevision(revisions, "r2"); previous == nil || previous.Name != "r1" {
+		t.Fatalf("Expected the previous revision to be r1, got %v", previous)
+	}
+	// The current revision may not yet have been recorded.
+	if previous := PreviousRevision(revisions, "r3"); previous == nil || previous.Name != "r2" {
+		t.Fatalf("Expected the previous revision to be r2, got %v", previous)
+	}
+	if previous := PreviousRevision(revisions[:1], "r1"); previous != nil {
+		t.Fatalf("Expected no previous revision, got %v", previous)
+	}
+}
+
+func TestGetFailureThresholdPercent(t *testing.T) {
+	testCases := map[string]struct {
+		spec              string
+		expectedThreshold int32
+		expectedErr       bool
+	}{
+		"no automatic rollback": {
+			spec: `{}`,
+		},
+		"default threshold": {
+			spec:              `{"autoRollback": {}}`,
+			expectedThreshold: 50,
+		},
+		"explicit threshold": {
+			spec:              `{"autoRollback": {"failureThresholdPercent": 100}}`,
+			expectedThreshold: 100,
+		},
+		"invalid threshold": {
+			spec:        `{"autoRollback": {"failureThresholdPercent": 0}}`,
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := unstructuredFromJSON(t, `{"apiVersion": "core.federation.k8s.io/v1alpha1", "kind": "FederatedConfigMap", "metadata": {"name": "foo"}, "spec": `+testCase.spec+`}`)
+			threshold, err := GetFailureThresholdPercent(fedObject)
+			if testCase.expectedErr != (err != nil) {
+				t.Fatalf("Expected error to be %v, got %v", testCase.expectedErr, err)
+			}
+			if threshold != testCase.expectedThreshold {
+				t.Fatalf("Expected threshold %d, got %d", testCase.expectedThreshold, threshold)
+			}
+		})
+	}
+}
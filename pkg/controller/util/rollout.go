@@ -0,0 +1,197 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	RolloutStrategyField = "rolloutStrategy"
+
+	defaultMaxUnavailableClusters  = 1
+	defaultProgressDeadlineSeconds = 600
+)
+
+// RolloutStrategy determines how changes to a federated resource are
+// rolled out to the selected clusters.  Clusters are divided into
+// ordered waves, and the clusters of a wave are updated in batches
+// of at most MaxUnavailableClusters.  A batch is only started once
+// the target resources in previously updated clusters are healthy.
+type RolloutStrategy struct {
+	// The maximum number of clusters whose target resource may be
+	// updating or unhealthy at once.  Defaults to 1.
+	MaxUnavailableClusters *int32 `json:"maxUnavailableClusters,omitempty"`
+	// Ordered waves of clusters.  A cluster belongs to the first wave
+	// whose selector matches its labels, and clusters that do not
+	// match any wave belong to an implicit final wave.
+	Waves []RolloutWave `json:"waves,omitempty"`
+	// How long to wait after a wave is healthy before starting the
+	// next wave.
+	PauseSeconds *int32 `json:"pauseSeconds,omitempty"`
+	// How long to wait for updated clusters to become healthy before
+	// halting the rollout.  Defaults to 600 seconds.
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+}
+
+// RolloutWave identifies the clusters of a wave of a rollout.
+type RolloutWave struct {
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector"`
+}
+
+type GenericRolloutSpec struct {
+	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+}
+
+type GenericRollout struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GenericRolloutSpec `json:"spec,omitempty"`
+}
+
+// RolloutDirective is the parsed form of a rollout strategy.
+type RolloutDirective struct {
+	MaxUnavailableClusters int
+	WaveSelectors          []labels.Selector
+	Pause                  time.Duration
+	ProgressDeadline       time.Duration
+}
+
+// GetRolloutDirective returns the rollout directive of the given
+// federated resource, or nil if it does not specify a rollout
+// strategy.
+func GetRolloutDirective(fedObject *unstructured.Unstructured) (*RolloutDirective, error) {
+	rollout := GenericRollout{}
+	err := UnstructuredToInterface(fedObject, &rollout)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error retrieving rollout strategy")
+	}
+	strategy := rollout.Spec.RolloutStrategy
+	if strategy == nil {
+		return nil, nil
+	}
+
+	directive := &RolloutDirective{
+		MaxUnavailableClusters: defaultMaxUnavailableClusters,
+		ProgressDeadline:       defaultProgressDeadlineSeconds * time.Second,
+	}
+	if strategy.MaxUnavailableClusters != nil {
+		if *strategy.MaxUnavailableClusters < 1 {
+			return nil, errors.New("maxUnavailableClusters must be at least 1")
+		}
+		directive.MaxUnavailableClusters = int(*strategy.MaxUnavailableClusters)
+	}
+	if strategy.PauseSeconds != nil {
+		if *strategy.PauseSeconds < 0 {
+			return nil, errors.New("pauseSeconds must not be negative")
+		}
+		directive.Pause = time.Duration(*strategy.PauseSeconds) * time.Second
+	}
+	if strategy.ProgressDeadlineSeconds != nil {
+		if *strategy.ProgressDeadlineSeconds < 1 {
+			return nil, errors.New("progressDeadlineSeconds must be at least 1")
+		}
+		directive.ProgressDeadline = time.Duration(*strategy.ProgressDeadlineSeconds) * time.Second
+	}
+	for i, wave := range strategy.Waves {
+		if wave.ClusterSelector == nil {
+			return nil, errors.Errorf("waves[%d] must specify a clusterSelector", i)
+		}
+		selector, err := metav1.LabelSelectorAsSelector(wave.ClusterSelector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "waves[%d] has an invalid clusterSelector", i)
+		}
+		directive.WaveSelectors = append(directive.WaveSelectors, selector)
+	}
+	return directive, nil
+}
+
+// RolloutStatus records the progress of rolling out a revision of a
+// federated resource.
+type RolloutStatus struct {
+	// The revision of the federated resource being rolled out.
+	Revision string `json:"revision"`
+	// When the rollout of the revision started.
+	StartTime metav1.Time `json:"startTime"`
+	// The index of the current wave.
+	CurrentWave int32 `json:"currentWave"`
+	// When the last batch of clusters was updated.
+	BatchStartTime *metav1.Time `json:"batchStartTime,omitempty"`
+	// When the previous wave became healthy, if the rollout is
+	// pausing before starting the current wave.
+	PauseStartTime *metav1.Time `json:"pauseStartTime,omitempty"`
+	// Whether the rollout completed.
+	Complete bool `json:"complete,omitempty"`
+	// Whether the rollout was halted.  A halted rollout resumes only
+	// when a new revision is rolled out.
+	Halted bool `json:"halted,omitempty"`
+	// A human-readable description of the state of the rollout.
+	Message string `json:"message,omitempty"`
+}
+
+// TargetHealthy indicates whether the given target resource is
+// healthy, and if not, why.  A resource whose status reports an
+// observedGeneration older than its generation is not healthy.
+// Workload types are healthy when all of their replicas have been
+// updated and are available.  Resources of other types are always
+// considered healthy.
+func TargetHealthy(clusterObj *unstructured.Unstructured) (bool, string) {
+	observedGeneration, ok, _ := unstructured.NestedInt64(clusterObj.Object, StatusField, "observedGeneration")
+	if ok && observedGeneration < clusterObj.GetGeneration() {
+		return false, "The latest generation has not been observed"
+	}
+
+	statusInt := func(field string) int64 {
+		value, _, _ := unstructured.NestedInt64(clusterObj.Object, StatusField, field)
+		return value
+	}
+	replicas, ok, _ := unstructured.NestedInt64(clusterObj.Object, SpecField, "replicas")
+	if !ok {
+		replicas = 1
+	}
+	var ready bool
+	var message string
+	switch clusterObj.GetKind() {
+	case "Deployment":
+		ready = statusInt("updatedReplicas") == replicas && statusInt("availableReplicas") == replicas
+		message = fmt.Sprintf("%d of %d replicas updated and %d available", statusInt("updatedReplicas"), replicas, statusInt("availableReplicas"))
+	case "StatefulSet":
+		ready = statusInt("updatedReplicas") == replicas && statusInt("readyReplicas") == replicas
+		message = fmt.Sprintf("%d of %d replicas updated and %d ready", statusInt("updatedReplicas"), replicas, statusInt("readyReplicas"))
+	case "ReplicaSet":
+		ready = statusInt("availableReplicas") == replicas
+		message = fmt.Sprintf("%d of %d replicas available", statusInt("availableReplicas"), replicas)
+	case "DaemonSet":
+		desired := statusInt("desiredNumberScheduled")
+		ready = statusInt("updatedNumberScheduled") == desired && statusInt("numberAvailable") == desired
+		message = fmt.Sprintf("%d of %d pods updated and %d available", statusInt("updatedNumberScheduled"), desired, statusInt("numberAvailable"))
+	default:
+		return true, ""
+	}
+	if ready {
+		return true, ""
+	}
+	return false, message
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGetRolloutDirective(t *testing.T) {
+	testCases := map[string]struct {
+		spec             string
+		expectedNil      bool
+		expectedErr      bool
+		expectedMax      int
+		expectedWaves    int
+		expectedPause    time.Duration
+		expectedProgress time.Duration
+	}{
+		"no rollout strategy": {
+			spec:        `{}`,
+			expectedNil: true,
+		},
+		"defaults": {
+			spec:             `{"rolloutStrategy": {}}`,
+			expectedMax:      1,
+			expectedProgress: 600 * time.Second,
+		},
+		"all fields": {
+			spec:             `{"rolloutStrategy": {"maxUnavailableClusters": 3, "pauseSeconds": 30, "progressDeadlineSeconds": 60, "waves": [{"clusterSelector": {"matchLabels": {"stage": "canary"}}}]}}`,
+			expectedMax:      3,
+			expectedWaves:    1,
+			expectedPause:    30 * time.Second,
+			expectedProgress: 60 * time.Second,
+		},
+		"invalid maxUnavailableClusters": {
+			spec:        `{"rolloutStrategy": {"maxUnavailableClusters": 0}}`,
+			expectedErr: true,
+		},
+		"wave without a selector": {
+			spec:        `{"rolloutStrategy": {"waves": [{}]}}`,
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := unstructuredFromJSON(t, `{"apiVersion": "core.federation.k8s.io/v1alpha1", "kind": "FederatedConfigMap", "metadata": {"name": "foo"}, "spec": `+testCase.spec+`}`)
+			directive, err := GetRolloutDirective(fedObject)
+			if testCase.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if testCase.expectedNil {
+				if directive != nil {
+					t.Fatalf("Expected no directive, got %v", directive)
+				}
+				return
+			}
+			if directive.MaxUnavailableClusters != testCase.expectedMax {
+				t.Errorf("Expected maxUnavailableClusters %d, got %d", testCase.expectedMax, directive.MaxUnavailableClusters)
+			}
+			if len(directive.WaveSelectors) != testCase.expectedWaves {
+				t.Errorf("Expected %d waves, got %d", testCase.expectedWaves, len(directive.WaveSelectors))
+			}
+			if directive.Pause != testCase.expectedPause {
+				t.Errorf("Expected pause %v, got %v", testCase.expectedPause, directive.Pause)
+			}
+			if directive.ProgressDeadline != testCase.expectedProgress {
+				t.Errorf("Expected progress deadline %v, got %v", testCase.expectedProgress, directive.ProgressDeadline)
+			}
+		})
+	}
+}
+
+func TestTargetHealthy(t *testing.T) {
+	testCases := map[string]struct {
+		clusterObj      string
+		expectedHealthy bool
+	}{
+		"resource without health checks": {
+			clusterObj:      `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "foo"}}`,
+			expectedHealthy: true,
+		},
+		"generation not observed": {
+			clusterObj:      `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "foo", "generation": 2}, "spec": {"replicas": 2}, "status": {"observedGeneration": 1, "updatedReplicas": 2, "availableReplicas": 2}}`,
+			expectedHealthy: false,
+		},
+		"deployment updating": {
+			clusterObj:      `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "foo", "generation": 2}, "spec": {"replicas": 2}, "status": {"observedGeneration": 2, "updatedReplicas": 1, "availableReplicas": 2}}`,
+			expectedHealthy: false,
+		},
+		"deployment available": {
+			clusterObj:      `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "foo", "generation": 2}, "spec": {"replicas": 2}, "status": {"observedGeneration": 2, "updatedReplicas": 2, "availableReplicas": 2}}`,
+			expectedHealthy: true,
+		},
+		"daemonset available": {
+			clusterObj:      `{"apiVersion": "apps/v1", "kind": "DaemonSet", "metadata": {"name": "foo"}, "status": {"desiredNumberScheduled": 3, "updatedNumberScheduled": 3, "numberAvailable": 3}}`,
+			expectedHealthy: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			// Decode with the unstructured decoder, as for objects
+			// retrieved from a member cluster, so that numbers are
+			// decoded as integers.
+			clusterObj := &unstructured.Unstructured{}
+			if err := clusterObj.UnmarshalJSON([]byte(testCase.clusterObj)); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			healthy, reason := TargetHealthy(clusterObj)
+			if healthy != testCase.expectedHealthy {
+				t.Fatalf("Expected healthy to be %v, got %v (%s)", testCase.expectedHealthy, healthy, reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+const (
+	// ClusterTemplatingAnnotation enables the rendering of cluster
+	// variables in the template and overrides of a federated resource
+	// when set to "true".
+	ClusterTemplatingAnnotation = "federation.k8s.io/cluster-templating"
+
+	// ClusterTemplatingFieldsAnnotation limits the rendering of
+	// cluster variables to a comma-separated list of field paths
+	// (e.g. "spec.rules[].host").  Setting it also enables
+	// templating.
+	ClusterTemplatingFieldsAnnotation = "federation.k8s.io/cluster-templating-fields"
+)
+
+// ClusterTemplateVariables are the variables of a member cluster that
+// may be referenced as .Cluster by a rendered template.
+type ClusterTemplateVariables struct {
+	Name   string
+	Labels map[string]string
+	Status ClusterTemplateStatus
+}
+
+type ClusterTemplateStatus struct {
+	Region string
+	Zone   string
+}
+
+type clusterTemplateData struct {
+	Cluster ClusterTemplateVariables
+}
+
+// NewClusterTemplateVariables returns the template variables for the
+// given cluster.
+func NewClusterTemplateVariables(cluster *fedv1a1.FederatedCluster) ClusterTemplateVariables {
+	labels := make(map[string]string)
+	for key, value := range cluster.Labels {
+		labels[key] = value
+	}
+	return ClusterTemplateVariables{
+		Name:   cluster.Name,
+		Labels: labels,
+		Status: ClusterTemplateStatus{
+			Region: cluster.Status.Region,
+			Zone:   cluster.Status.Zone,
+		},
+	}
+}
+
+// IsClusterTemplatingEnabled indicates whether cluster variables
+// should be rendered for the given federated resource.
+func IsClusterTemplatingEnabled(fedObject *unstructured.Unstructured) bool {
+	annotations := fedObject.GetAnnotations()
+	return annotations[ClusterTemplatingAnnotation] == "true" || len(ClusterTemplatingFields(fedObject)) > 0
+}
+
+// ClusterTemplatingFields returns the paths of the fields of the given
+// federated resource in which cluster variables should be rendered.
+// All fields are rendered if no paths are returned.
+func ClusterTemplatingFields(fedObject *unstructured.Unstructured) []string {
+	value := fedObject.GetAnnotations()[ClusterTemplatingFieldsAnnotation]
+	fields := []string{}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if len(field) > 0 {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// RenderClusterTemplate renders the string values of the given object
+// that contain a template action (e.g. "{{ .Cluster.Name }}") with the
+// variables of the given cluster.  If fields are given, only values
+// at or beneath those paths are rendered, and the elements of a list
+// are matched by a path element suffixed with "[]".  Referencing a
+// variable that is not defined, such as a label the cluster does not
+// have, is an error.
+func RenderClusterTemplate(obj *unstructured.Unstructured, cluster *fedv1a1.FederatedCluster, fields []string) error {
+	data := clusterTemplateData{
+		Cluster: NewClusterTemplateVariables(cluster),
+	}
+	rendered, err := renderValue(obj.Object, "", "", fields, data)
+	if err != nil {
+		return err
+	}
+	obj.Object = rendered.(map[string]interface{})
+	return nil
+}
+
+// renderValue renders the given value found at path.  The fieldPath
+// is the path without list indices that is matched against the
+// fields to render.
+func renderValue(value interface{}, path, fieldPath string, fields []string, data clusterTemplateData) (interface{}, error) {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for key, fieldValue := range typedValue {
+			keyPath, keyFieldPath := key, key
+			if len(path) > 0 {
+				keyPath = fmt.Sprintf("%s.%s", path, key)
+				keyFieldPath = fmt.Sprintf("%s.%s", fieldPath, key)
+			}
+			renderedValue, err := renderValue(fieldValue, keyPath, keyFieldPath, fields, data)
+			if err != nil {
+				return nil, err
+			}
+			typedValue[key] = renderedValue
+		}
+	case []interface{}:
+		for i, item := range typedValue {
+			renderedValue, err := renderValue(item, fmt.Sprintf("%s[%d]", path, i), fieldPath+listPathSuffix, fields, data)
+			if err != nil {
+				return nil, err
+			}
+			typedValue[i] = renderedValue
+		}
+	case string:
+		if !strings.Contains(typedValue, "{{") || !isTemplatedField(fieldPath, fields) {
+			return typedValue, nil
+		}
+		tmpl, err := template.New(path).Option("missingkey=error").Parse(typedValue)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error parsing template at %q", path)
+		}
+		buf := &bytes.Buffer{}
+		err = tmpl.Execute(buf, data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error rendering template at %q", path)
+		}
+		return buf.String(), nil
+	}
+	return value, nil
+}
+
+// isTemplatedField indicates whether the value at the given field path
+// should be rendered.
+func isTemplatedField(fieldPath string, fields []string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	for _, field := range fields {
+		if fieldPath == field || strings.HasPrefix(fieldPath, field+".") || strings.HasPrefix(fieldPath, field+listPathSuffix) {
+			return true
+		}
+	}
+	return false
+}
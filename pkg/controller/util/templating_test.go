@@ -0,0 +1,132 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+func TestRenderClusterTemplate(t *testing.T) {
+	cluster := &fedv1a1.FederatedCluster{}
+	cluster.Name = "cluster1"
+	cluster.Labels = map[string]string{"environment": "prod"}
+	cluster.Status.Region = "us-east1"
+	cluster.Status.Zone = "us-east1-a"
+
+	testCases := map[string]struct {
+		data         string
+		fields       []string
+		expectedData string
+		expectedErr  bool
+	}{
+		"no variables": {
+			data:         `{"host": "example.com", "replicas": 3}`,
+			expectedData: `{"host": "example.com", "replicas": 3}`,
+		},
+		"name and labels": {
+			data:         `{"host": "{{ .Cluster.Name }}.example.com", "tags": ["env-{{ .Cluster.Labels.environment }}"]}`,
+			expectedData: `{"host": "cluster1.example.com", "tags": ["env-prod"]}`,
+		},
+		"region and zone": {
+			data:         `{"nested": {"location": "{{ .Cluster.Status.Region }}/{{ .Cluster.Status.Zone }}"}}`,
+			expectedData: `{"nested": {"location": "us-east1/us-east1-a"}}`,
+		},
+		"only listed fields": {
+			data:         `{"host": "{{ .Cluster.Name }}", "chart": "{{ .Values.name }}", "hosts": ["{{ .Cluster.Name }}"]}`,
+			fields:       []string{"data.host", "data.hosts"},
+			expectedData: `{"host": "cluster1", "chart": "{{ .Values.name }}", "hosts": ["cluster1"]}`,
+		},
+		"fields of list elements": {
+			data:         `{"rules": [{"host": "{{ .Cluster.Name }}", "path": "{{ .Path }}"}]}`,
+			fields:       []string{"data.rules[].host"},
+			expectedData: `{"rules": [{"host": "cluster1", "path": "{{ .Path }}"}]}`,
+		},
+		"missing label": {
+			data:        `{"tag": "{{ .Cluster.Labels.tier }}"}`,
+			expectedErr: true,
+		},
+		"invalid template": {
+			data:        `{"tag": "{{ .Cluster.Name "}`,
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			obj := unstructuredFromJSON(t, `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "foo"}, "data": `+testCase.data+`}`)
+			err := RenderClusterTemplate(obj, cluster, testCase.fields)
+			if testCase.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			expectedObj := unstructuredFromJSON(t, `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "foo"}, "data": `+testCase.expectedData+`}`)
+			if !reflect.DeepEqual(expectedObj, obj) {
+				t.Fatalf("Expected %v, got %v", expectedObj.Object, obj.Object)
+			}
+		})
+	}
+}
+
+func TestClusterTemplatingAnnotations(t *testing.T) {
+	testCases := map[string]struct {
+		annotations     map[string]string
+		expectedEnabled bool
+		expectedFields  []string
+	}{
+		"no annotations": {
+			expectedFields: []string{},
+		},
+		"templating disabled": {
+			annotations:    map[string]string{ClusterTemplatingAnnotation: "false"},
+			expectedFields: []string{},
+		},
+		"templating enabled": {
+			annotations:     map[string]string{ClusterTemplatingAnnotation: "true"},
+			expectedEnabled: true,
+			expectedFields:  []string{},
+		},
+		"templating of fields": {
+			annotations:     map[string]string{ClusterTemplatingFieldsAnnotation: "spec.rules[].host, metadata.labels,"},
+			expectedEnabled: true,
+			expectedFields:  []string{"spec.rules[].host", "metadata.labels"},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			fedObject.SetAnnotations(testCase.annotations)
+			if enabled := IsClusterTemplatingEnabled(fedObject); enabled != testCase.expectedEnabled {
+				t.Fatalf("Expected enabled to be %v, got %v", testCase.expectedEnabled, enabled)
+			}
+			fields := ClusterTemplatingFields(fedObject)
+			if !reflect.DeepEqual(testCase.expectedFields, fields) {
+				t.Fatalf("Expected fields %v, got %v", testCase.expectedFields, fields)
+			}
+		})
+	}
+}
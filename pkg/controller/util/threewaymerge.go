@@ -0,0 +1,185 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// LastAppliedConfigurationAnnotation records on a target resource the
+// desired state last propagated to it in the Merge propagation mode.
+const LastAppliedConfigurationAnnotation = "federation.k8s.io/last-applied-configuration"
+
+// SetLastAppliedConfiguration records the given desired object in its
+// last-applied configuration annotation.
+func SetLastAppliedConfiguration(desiredObj *unstructured.Unstructured) error {
+	configObj := desiredObj.DeepCopy()
+	configObj.SetResourceVersion("")
+	annotations := configObj.GetAnnotations()
+	delete(annotations, LastAppliedConfigurationAnnotation)
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(configObj.Object, "metadata", "annotations")
+	} else {
+		configObj.SetAnnotations(annotations)
+	}
+	config, err := configObj.MarshalJSON()
+	if err != nil {
+		return errors.Wrap(err, "Error marshalling last-applied configuration")
+	}
+
+	annotations = desiredObj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[LastAppliedConfigurationAnnotation] = string(config)
+	desiredObj.SetAnnotations(annotations)
+	return nil
+}
+
+// ThreeWayMergePatch returns a patch that updates the fields of the
+// cluster object managed by federation to match the desired object.
+// Managed fields are those set in the desired object or in the
+// last-applied configuration recorded on the cluster object, and
+// fields set only in the cluster object are left alone.  A strategic
+// merge patch is computed for kinds registered with the client scheme
+// and a JSON merge patch otherwise.  An empty patch indicates that no
+// update is required.
+func ThreeWayMergePatch(desiredObj, clusterObj *unstructured.Unstructured) (types.PatchType, []byte, error) {
+	original := []byte(clusterObj.GetAnnotations()[LastAppliedConfigurationAnnotation])
+	modified, err := desiredObj.MarshalJSON()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "Error marshalling desired object")
+	}
+	current, err := clusterObj.MarshalJSON()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "Error marshalling cluster object")
+	}
+
+	dataStruct, err := scheme.Scheme.New(desiredObj.GroupVersionKind())
+	if pkgruntime.IsNotRegisteredError(err) {
+		patch, err := jsonThreeWayMergePatch(original, modified, current)
+		return types.MergePatchType, patch, err
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	lookupPatchMeta, err := strategicpatch.NewPatchMetaFromStruct(dataStruct)
+	if err != nil {
+		return "", nil, err
+	}
+	patch, err := strategicpatch.CreateThreeWayMergePatch(original, modified, current, lookupPatchMeta, true)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "Error computing strategic merge patch")
+	}
+	// A strategic merge patch may contain only directives (e.g. the
+	// order of list elements) that do not change the cluster object.
+	patched, err := strategicpatch.StrategicMergePatchUsingLookupPatchMeta(current, patch, lookupPatchMeta)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "Error applying strategic merge patch")
+	}
+	unchanged, err := jsonEqual(current, patched)
+	if err != nil {
+		return "", nil, err
+	}
+	if unchanged {
+		return types.StrategicMergePatchType, nil, nil
+	}
+	return types.StrategicMergePatchType, patch, nil
+}
+
+// jsonEqual indicates whether the given JSON documents are
+// semantically equal.
+func jsonEqual(a, b []byte) (bool, error) {
+	var aValue, bValue interface{}
+	if err := json.Unmarshal(a, &aValue); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(b, &bValue); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(aValue, bValue), nil
+}
+
+// jsonThreeWayMergePatch computes an RFC 7386 merge patch from the
+// JSON-encoded original (last-applied), modified (desired) and current
+// (cluster) objects.  As for any merge patch, lists are replaced in
+// their entirety.
+func jsonThreeWayMergePatch(original, modified, current []byte) ([]byte, error) {
+	originalMap := make(map[string]interface{})
+	if len(original) > 0 {
+		if err := json.Unmarshal(original, &originalMap); err != nil {
+			return nil, errors.Wrap(err, "Error unmarshalling last-applied configuration")
+		}
+	}
+	modifiedMap := make(map[string]interface{})
+	if err := json.Unmarshal(modified, &modifiedMap); err != nil {
+		return nil, errors.Wrap(err, "Error unmarshalling desired object")
+	}
+	currentMap := make(map[string]interface{})
+	if err := json.Unmarshal(current, &currentMap); err != nil {
+		return nil, errors.Wrap(err, "Error unmarshalling cluster object")
+	}
+
+	patch := jsonThreeWayMergeMapPatch(originalMap, modifiedMap, currentMap)
+	if len(patch) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(patch)
+}
+
+func jsonThreeWayMergeMapPatch(original, modified, current map[string]interface{}) map[string]interface{} {
+	patch := make(map[string]interface{})
+	// Remove fields previously set by federation that are no longer
+	// desired.
+	for key := range original {
+		if _, ok := modified[key]; ok {
+			continue
+		}
+		if _, ok := current[key]; ok {
+			patch[key] = nil
+		}
+	}
+	for key, modifiedValue := range modified {
+		currentValue, ok := current[key]
+		if !ok && modifiedValue == nil {
+			continue
+		}
+		modifiedMap, modifiedIsMap := modifiedValue.(map[string]interface{})
+		currentMap, currentIsMap := currentValue.(map[string]interface{})
+		if ok && modifiedIsMap && currentIsMap {
+			originalMap, _ := original[key].(map[string]interface{})
+			childPatch := jsonThreeWayMergeMapPatch(originalMap, modifiedMap, currentMap)
+			if len(childPatch) > 0 {
+				patch[key] = childPatch
+			}
+			continue
+		}
+		if !ok || !reflect.DeepEqual(modifiedValue, currentValue) {
+			patch[key] = modifiedValue
+		}
+	}
+	return patch
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestThreeWayMergePatch(t *testing.T) {
+	const (
+		deployment         = `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "foo"}, "spec": {"replicas": 1, "template": {"spec": {"containers": [{"name": "app", "image": "app:1"}]}}}}`
+		unscaledDeployment = `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "foo"}, "spec": {"template": {"spec": {"containers": [{"name": "app", "image": "app:1"}]}}}}`
+		customResource     = `{"apiVersion": "example.com/v1", "kind": "Foo", "metadata": {"name": "foo"}, "spec": {"replicas": 1, "items": ["a"]}}`
+	)
+
+	testCases := map[string]struct {
+		// The object last applied to the cluster.
+		applied string
+		// Changes made to the cluster object in the member cluster.
+		clusterPatch string
+		desired      string
+		// The expected cluster object after patching, or empty if
+		// no patch is expected.
+		expected          string
+		expectedPatchType types.PatchType
+	}{
+		"unchanged resource": {
+			applied: deployment,
+			desired: deployment,
+		},
+		"fields set in the member cluster are not patched": {
+			applied:      unscaledDeployment,
+			clusterPatch: `{"metadata": {"annotations": {"injected": "true"}}, "spec": {"replicas": 3, "template": {"spec": {"containers": [{"name": "app", "image": "app:1"}, {"name": "sidecar", "image": "sidecar:1"}]}}}, "status": {"replicas": 3}}`,
+			desired:      unscaledDeployment,
+		},
+		"managed field is updated without removing a merged list element": {
+			applied:           deployment,
+			clusterPatch:      `{"spec": {"template": {"spec": {"containers": [{"name": "app", "image": "app:1"}, {"name": "sidecar", "image": "sidecar:1"}]}}}}`,
+			desired:           `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "foo"}, "spec": {"replicas": 1, "template": {"spec": {"containers": [{"name": "app", "image": "app:2"}]}}}}`,
+			expected:          `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "foo"}, "spec": {"replicas": 1, "template": {"spec": {"containers": [{"name": "app", "image": "app:2"}, {"name": "sidecar", "image": "sidecar:1"}]}}}}`,
+			expectedPatchType: types.StrategicMergePatchType,
+		},
+		"managed field no longer desired is removed": {
+			applied:           deployment,
+			clusterPatch:      `{"metadata": {"annotations": {"injected": "true"}}}`,
+			desired:           unscaledDeployment,
+			expected:          `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "foo", "annotations": {"injected": "true"}}, "spec": {"template": {"spec": {"containers": [{"name": "app", "image": "app:1"}]}}}}`,
+			expectedPatchType: types.StrategicMergePatchType,
+		},
+		"managed field changed in the member cluster is reverted": {
+			applied:           customResource,
+			clusterPatch:      `{"spec": {"replicas": 2}}`,
+			desired:           customResource,
+			expected:          customResource,
+			expectedPatchType: types.MergePatchType,
+		},
+		"unmanaged field of a custom resource is not patched": {
+			applied:      customResource,
+			clusterPatch: `{"spec": {"defaulted": true}}`,
+			desired:      customResource,
+		},
+		"list replaced and field no longer desired is removed": {
+			applied:           customResource,
+			clusterPatch:      `{"spec": {"defaulted": true}}`,
+			desired:           `{"apiVersion": "example.com/v1", "kind": "Foo", "metadata": {"name": "foo"}, "spec": {"items": ["a", "b"]}}`,
+			expected:          `{"apiVersion": "example.com/v1", "kind": "Foo", "metadata": {"name": "foo"}, "spec": {"defaulted": true, "items": ["a", "b"]}}`,
+			expectedPatchType: types.MergePatchType,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			appliedObj := unstructuredFromJSON(t, testCase.applied)
+			if err := SetLastAppliedConfiguration(appliedObj); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			clusterObj := appliedObj.DeepCopy()
+			if len(testCase.clusterPatch) > 0 {
+				clusterObj.Object = jsonMergePatch(clusterObj.Object, unstructuredFromJSON(t, testCase.clusterPatch).Object).(map[string]interface{})
+			}
+			desiredObj := unstructuredFromJSON(t, testCase.desired)
+			if err := SetLastAppliedConfiguration(desiredObj); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			patchType, patch, err := ThreeWayMergePatch(desiredObj, clusterObj)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(testCase.expected) == 0 {
+				if patch != nil {
+					t.Fatalf("Expected no patch, got %s", string(patch))
+				}
+				return
+			}
+			if patch == nil {
+				t.Fatalf("Expected a patch")
+			}
+			if patchType != testCase.expectedPatchType {
+				t.Fatalf("Expected patch type %q, got %q", testCase.expectedPatchType, patchType)
+			}
+
+			current, err := clusterObj.MarshalJSON()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var patched []byte
+			if patchType == types.StrategicMergePatchType {
+				var dataStruct runtime.Object
+				dataStruct, err = scheme.Scheme.New(clusterObj.GroupVersionKind())
+				if err == nil {
+					patched, err = strategicpatch.StrategicMergePatch(current, patch, dataStruct)
+				}
+			} else {
+				patchedMap := make(map[string]interface{})
+				patchMap := make(map[string]interface{})
+				if err = json.Unmarshal(current, &patchedMap); err == nil {
+					err = json.Unmarshal(patch, &patchMap)
+				}
+				if err == nil {
+					patched, err = json.Marshal(jsonMergePatch(patchedMap, patchMap))
+				}
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			patchedObj := unstructuredFromJSON(t, string(patched))
+			expectedObj := unstructuredFromJSON(t, testCase.expected)
+			if err := SetLastAppliedConfiguration(expectedObj); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			// The last-applied configuration is expected to be that
+			// of the desired object.
+			annotations := expectedObj.GetAnnotations()
+			annotations[LastAppliedConfigurationAnnotation] = desiredObj.GetAnnotations()[LastAppliedConfigurationAnnotation]
+			expectedObj.SetAnnotations(annotations)
+			if !reflect.DeepEqual(expectedObj.Object, patchedObj.Object) {
+				t.Fatalf("Expected %v, got %v", expectedObj.Object, patchedObj.Object)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+// TrimFunc returns a copy of a target resource containing only the
+// fields that should be cached.
+type TrimFunc func(obj *unstructured.Unstructured) *unstructured.Unstructured
+
+// trimmedFieldPaths are the fields of a target resource that are
+// always cached by a trimmed cache.  Metadata is required to compare
+// versions and determine whether a resource is managed, and the
+// status and replica count are required to determine the health of a
+// resource.
+var trimmedFieldPaths = [][]string{
+	{"apiVersion"},
+	{"kind"},
+	{MetadataField},
+	{StatusField},
+	{SpecField, "replicas"},
+}
+
+// NewTrimFunc returns a function that trims a target resource to the
+// fields always cached by a trimmed cache and the given retained
+// fields.  A retained field nested in a list is cached with the whole
+// list so that list elements can be matched.
+func NewTrimFunc(retainedFields []fedv1a1.RetainedField) (TrimFunc, error) {
+	paths := append([][]string{}, trimmedFieldPaths...)
+	for _, field := range retainedFields {
+		elements, err := parseRetainedFieldPath(field.Path)
+		if err != nil {
+			return nil, err
+		}
+		path := []string{}
+		for _, element := range elements {
+			path = append(path, element.name)
+			if element.list {
+				break
+			}
+		}
+		paths = append(paths, path)
+	}
+
+	return func(obj *unstructured.Unstructured) *unstructured.Unstructured {
+		trimmedObj := &unstructured.Unstructured{Object: make(map[string]interface{})}
+		for _, path := range paths {
+			value, ok, err := unstructured.NestedFieldNoCopy(obj.Object, path...)
+			if err != nil || !ok {
+				continue
+			}
+			// Setting a value found at the same path in the
+			// original object cannot fail.
+			unstructured.SetNestedField(trimmedObj.Object, value, path...)
+		}
+		return trimmedObj
+	}, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+func TestTrimFunc(t *testing.T) {
+	testCases := map[string]struct {
+		retainedFields []fedv1a1.RetainedField
+		obj            string
+		expectedObj    string
+	}{
+		"only cached fields are kept": {
+			obj:         `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "foo", "resourceVersion": "1"}, "spec": {"replicas": 2, "template": {"spec": {}}}, "status": {"readyReplicas": 2}}`,
+			expectedObj: `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "foo", "resourceVersion": "1"}, "spec": {"replicas": 2}, "status": {"readyReplicas": 2}}`,
+		},
+		"retained fields are kept": {
+			retainedFields: []fedv1a1.RetainedField{
+				{Path: "spec.clusterIP"},
+				{Path: "spec.ports[].nodePort", MatchKeys: []string{"port"}},
+			},
+			obj:         `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "foo"}, "spec": {"clusterIP": "10.0.0.1", "ports": [{"port": 80, "nodePort": 30080}], "selector": {"app": "foo"}}}`,
+			expectedObj: `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "foo"}, "spec": {"clusterIP": "10.0.0.1", "ports": [{"port": 80, "nodePort": 30080}]}}`,
+		},
+		"absent retained fields are ignored": {
+			retainedFields: []fedv1a1.RetainedField{
+				{Path: "spec.volumeName"},
+			},
+			obj:         `{"apiVersion": "v1", "kind": "PersistentVolumeClaim", "metadata": {"name": "foo"}, "spec": {"storageClassName": "fast"}}`,
+			expectedObj: `{"apiVersion": "v1", "kind": "PersistentVolumeClaim", "metadata": {"name": "foo"}}`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			trimFunc, err := NewTrimFunc(testCase.retainedFields)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			obj := unstructuredFromJSON(t, testCase.obj)
+			expectedObj := unstructuredFromJSON(t, testCase.expectedObj)
+			trimmedObj := trimFunc(obj)
+			if !reflect.DeepEqual(expectedObj, trimmedObj) {
+				t.Fatalf("Expected %v, got %v", expectedObj.Object, trimmedObj.Object)
+			}
+		})
+	}
+}
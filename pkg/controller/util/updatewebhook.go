@@ -0,0 +1,132 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+const defaultUpdateWebhookTimeout = 10 * time.Second
+
+// UpdateWebhookRequest is the body of the request sent to an update
+// webhook.
+type UpdateWebhookRequest struct {
+	// The name of the member cluster containing the resource.
+	ClusterName string `json:"clusterName"`
+	// The object that federation would use to update the resource.
+	Desired map[string]interface{} `json:"desired"`
+	// The resource in the member cluster.
+	Cluster map[string]interface{} `json:"cluster"`
+}
+
+// UpdateWebhookResponse is the body of the response expected from an
+// update webhook.
+type UpdateWebhookResponse struct {
+	// The object to use to update the resource.  Its apiVersion,
+	// kind, namespace and name must match those of the desired
+	// object in the request.
+	Desired map[string]interface{} `json:"desired"`
+}
+
+// UpdateWebhookClient calls an update webhook to compute the object
+// used to update a resource in a member cluster.
+type UpdateWebhookClient struct {
+	url    string
+	client *http.Client
+}
+
+func NewUpdateWebhookClient(webhook *fedv1a1.UpdateWebhook) (*UpdateWebhookClient, error) {
+	if len(webhook.URL) == 0 {
+		return nil, errors.New("The url of the update webhook must be provided")
+	}
+	timeout := defaultUpdateWebhookTimeout
+	if webhook.TimeoutSeconds != nil {
+		timeout = time.Duration(*webhook.TimeoutSeconds) * time.Second
+	}
+	transport := &http.Transport{}
+	if len(webhook.CABundle) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(webhook.CABundle) {
+			return nil, errors.New("Unable to parse the caBundle of the update webhook")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return &UpdateWebhookClient{
+		url: webhook.URL,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+	}, nil
+}
+
+// DesiredObject returns the object computed by the webhook for
+// updating the given cluster object.
+func (c *UpdateWebhookClient) DesiredObject(clusterName string, desiredObj, clusterObj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	request := UpdateWebhookRequest{
+		ClusterName: clusterName,
+		Desired:     desiredObj.Object,
+		Cluster:     clusterObj.Object,
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling update webhook request")
+	}
+
+	httpResponse, err := c.client.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "Error calling update webhook")
+	}
+	defer httpResponse.Body.Close()
+	responseBody, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading update webhook response")
+	}
+	if httpResponse.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("Update webhook responded with status %d: %s", httpResponse.StatusCode, string(responseBody))
+	}
+
+	response := UpdateWebhookResponse{}
+	err = json.Unmarshal(responseBody, &response)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error unmarshalling update webhook response")
+	}
+	if response.Desired == nil {
+		return nil, errors.New("Update webhook response did not include a desired object")
+	}
+
+	obj := &unstructured.Unstructured{Object: response.Desired}
+	if obj.GetAPIVersion() != desiredObj.GetAPIVersion() || obj.GetKind() != desiredObj.GetKind() ||
+		obj.GetNamespace() != desiredObj.GetNamespace() || obj.GetName() != desiredObj.GetName() {
+		return nil, errors.Errorf("Update webhook response must not change the apiVersion, kind, namespace or name of the desired object")
+	}
+	// The resource version is required to update the cluster object.
+	obj.SetResourceVersion(desiredObj.GetResourceVersion())
+	return obj, nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+func TestUpdateWebhookDesiredObject(t *testing.T) {
+	testCases := map[string]struct {
+		status      int
+		modify      func(obj *unstructured.Unstructured)
+		expectedErr bool
+	}{
+		"desired object is modified": {
+			status: http.StatusOK,
+			modify: func(obj *unstructured.Unstructured) {
+				obj.SetLabels(map[string]string{"foo": "bar"})
+			},
+		},
+		"error status": {
+			status:      http.StatusInternalServerError,
+			expectedErr: true,
+		},
+		"name is changed": {
+			status: http.StatusOK,
+			modify: func(obj *unstructured.Unstructured) {
+				obj.SetName("bar")
+			},
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request := UpdateWebhookRequest{}
+				err := json.NewDecoder(r.Body).Decode(&request)
+				if err != nil || request.ClusterName != "c1" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				if testCase.status != http.StatusOK {
+					w.WriteHeader(testCase.status)
+					return
+				}
+				obj := &unstructured.Unstructured{Object: request.Desired}
+				obj.SetResourceVersion("")
+				testCase.modify(obj)
+				json.NewEncoder(w).Encode(UpdateWebhookResponse{Desired: obj.Object})
+			}))
+			defer server.Close()
+
+			client, err := NewUpdateWebhookClient(&fedv1a1.UpdateWebhook{URL: server.URL})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			desiredObj := unstructuredFromJSON(t, `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"namespace": "ns", "name": "foo", "resourceVersion": "1"}}`)
+			clusterObj := desiredObj.DeepCopy()
+
+			obj, err := client.DesiredObject("c1", desiredObj, clusterObj)
+			if testCase.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if obj.GetLabels()["foo"] != "bar" {
+				t.Fatalf("Expected the object returned by the webhook, got %v", obj.Object)
+			}
+			if obj.GetResourceVersion() != "1" {
+				t.Fatalf("Expected the resource version of the desired object to be retained, got %q", obj.GetResourceVersion())
+			}
+		})
+	}
+}
+
+func TestNewUpdateWebhookClient(t *testing.T) {
+	_, err := NewUpdateWebhookClient(&fedv1a1.UpdateWebhook{URL: "https://example.com", CABundle: []byte("invalid")})
+	if err == nil {
+		t.Fatalf("Expected an error for an invalid caBundle")
+	}
+}
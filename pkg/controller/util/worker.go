@@ -22,7 +22,6 @@ import (
 	pkgruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/flowcontrol"
-	"k8s.io/client-go/util/workqueue"
 )
 
 type ReconcileFunc func(qualifiedName QualifiedName) ReconciliationStatus
@@ -44,6 +43,8 @@ type WorkerTiming struct {
 	ClusterSyncDelay time.Duration
 	InitialBackoff   time.Duration
 	MaxBackoff       time.Duration
+	// The number of resources that may be reconciled concurrently.
+	MaxConcurrentReconciles int
 }
 
 type asyncWorker struct {
@@ -57,8 +58,10 @@ type asyncWorker struct {
 	// federation.
 	deliverer *DelayingDeliverer
 
-	// Work queue allowing parallel processing of resources
-	queue workqueue.Interface
+	// Work queue allowing parallel processing of resources.
+	// Resources enqueued in response to changes are processed ahead
+	// of those enqueued for retry or resync.
+	queue *priorityQueue
 
 	// Backoff manager
 	backoff *flowcontrol.Backoff
@@ -77,17 +80,21 @@ func NewReconcileWorker(reconcile ReconcileFunc, timing WorkerTiming) ReconcileW
 	if timing.MaxBackoff == 0 {
 		timing.MaxBackoff = time.Minute
 	}
+	if timing.MaxConcurrentReconciles < 1 {
+		timing.MaxConcurrentReconciles = 1
+	}
 	return &asyncWorker{
 		reconcile: reconcile,
 		timing:    timing,
 		deliverer: NewDelayingDeliverer(),
-		queue:     workqueue.New(),
+		queue:     newPriorityQueue(),
 		backoff:   flowcontrol.NewBackOff(timing.InitialBackoff, timing.MaxBackoff),
 	}
 }
 
 func (w *asyncWorker) Enqueue(qualifiedName QualifiedName) {
-	w.deliver(qualifiedName, 0, false)
+	w.backoff.Reset(qualifiedName.String())
+	w.queue.Add(qualifiedName, true)
 }
 
 func (w *asyncWorker) EnqueueForError(qualifiedName QualifiedName) {
@@ -114,9 +121,11 @@ func (w *asyncWorker) EnqueueWithDelay(qualifiedName QualifiedName, delay time.D
 func (w *asyncWorker) Run(stopChan <-chan struct{}) {
 	StartBackoffGC(w.backoff, stopChan)
 	w.deliverer.StartWithHandler(func(item *DelayingDelivererItem) {
-		w.queue.Add(item)
+		w.queue.Add(*item.Value.(*QualifiedName), false)
 	})
-	go wait.Until(w.worker, w.timing.Interval, stopChan)
+	for i := 0; i < w.timing.MaxConcurrentReconciles; i++ {
+		go wait.Until(w.worker, w.timing.Interval, stopChan)
+	}
 
 	// Ensure all goroutines are cleaned up when the stop channel closes
 	go func() {
@@ -132,7 +141,8 @@ func (w *asyncWorker) SetDelay(retryDelay, clusterSyncDelay time.Duration) {
 }
 
 // deliver adds backoff to delay if this delivery is related to some
-// failure. Resets backoff if there was no failure.
+// failure. Resets backoff if there was no failure.  Delivered items
+// are queued with low priority.
 func (w *asyncWorker) deliver(qualifiedName QualifiedName, delay time.Duration, failed bool) {
 	key := qualifiedName.String()
 	if failed {
@@ -146,25 +156,23 @@ func (w *asyncWorker) deliver(qualifiedName QualifiedName, delay time.Duration,
 
 func (w *asyncWorker) worker() {
 	for {
-		obj, quit := w.queue.Get()
+		qualifiedName, quit := w.queue.Get()
 		if quit {
 			return
 		}
 
-		item := obj.(*DelayingDelivererItem)
-		qualifiedName := item.Value.(*QualifiedName)
-		status := w.reconcile(*qualifiedName)
-		w.queue.Done(item)
+		status := w.reconcile(qualifiedName)
+		w.queue.Done(qualifiedName)
 
 		switch status {
 		case StatusAllOK:
 			break
 		case StatusError:
-			w.EnqueueForError(*qualifiedName)
+			w.EnqueueForError(qualifiedName)
 		case StatusNeedsRecheck:
-			w.EnqueueForRetry(*qualifiedName)
+			w.EnqueueForRetry(qualifiedName)
 		case StatusNotSynced:
-			w.EnqueueForClusterSync(*qualifiedName)
+			w.EnqueueForClusterSync(qualifiedName)
 		}
 	}
 }
@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"github.com/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kubernetes-sigs/federation-v2/pkg/apis/core/typeconfig"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+)
+
+// DefaultFederatedResource returns a copy of the given federated
+// resource with defaults applied:
+//
+//   - fields of the template that should not be propagated, like
+//     those populated by the API server, are removed
+//   - overrides are ordered so that their hash is stable
+//   - placement is defaulted from the given federated namespace, if
+//     provided, when the resource does not specify placement
+func DefaultFederatedResource(typeConfig typeconfig.Interface, fedObject, fedNamespace *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	obj := fedObject.DeepCopy()
+
+	template, ok, err := unstructured.NestedFieldNoCopy(obj.Object, util.SpecField, util.TemplateField)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error retrieving template")
+	}
+	if ok {
+		templateMap, ok := template.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("%s.%s is not an object", util.SpecField, util.TemplateField)
+		}
+		util.RemoveUnwantedFields(templateMap, typeConfig.GetTarget().Kind)
+	}
+
+	err = util.NormalizeOverrides(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if fedNamespace != nil {
+		obj, err = util.DefaultPlacementFromNamespace(obj, fedNamespace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return obj, nil
+}
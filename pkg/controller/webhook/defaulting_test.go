@@ -0,0 +1,73 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	corev1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+func TestDefaultFederatedResource(t *testing.T) {
+	typeConfig := &corev1a1.FederatedTypeConfig{
+		Spec: corev1a1.FederatedTypeConfigSpec{
+			Target: corev1a1.APIResource{Kind: "ServiceAccount"},
+		},
+	}
+	fedNamespace := decodeTestObject(t, `{"apiVersion": "types.federation.k8s.io/v1alpha1", "kind": "FederatedNamespace", "metadata": {"name": "bar", "namespace": "bar"}, "spec": {"placement": {"clusterNames": ["cluster1"]}}}`)
+
+	testCases := map[string]struct {
+		spec         string
+		fedNamespace *unstructured.Unstructured
+		expectedSpec string
+	}{
+		"defaults are applied": {
+			spec:         `{"template": {"metadata": {"name": "foo", "uid": "1"}, "secrets": [{"name": "foo-token"}]}, "overrides": [{"clusterName": "b"}, {"clusterName": "a"}]}`,
+			fedNamespace: fedNamespace,
+			expectedSpec: `{"template": {"metadata": {}}, "overrides": [{"clusterName": "a"}, {"clusterName": "b"}], "placement": {"clusterNames": ["cluster1"]}}`,
+		},
+		"placement is not defaulted without a namespace": {
+			spec:         `{"template": {"metadata": {}}}`,
+			expectedSpec: `{"template": {"metadata": {}}}`,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject := decodeTestObject(t, `{"apiVersion": "types.federation.k8s.io/v1alpha1", "kind": "FederatedServiceAccount", "metadata": {"name": "foo", "namespace": "bar"}, "spec": `+testCase.spec+`}`)
+			expectedObject := decodeTestObject(t, `{"apiVersion": "types.federation.k8s.io/v1alpha1", "kind": "FederatedServiceAccount", "metadata": {"name": "foo", "namespace": "bar"}, "spec": `+testCase.expectedSpec+`}`)
+			obj, err := DefaultFederatedResource(typeConfig, fedObject, testCase.fedNamespace)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(expectedObject, obj) {
+				t.Fatalf("Expected %v, got %v", expectedObject.Object, obj.Object)
+			}
+		})
+	}
+}
+
+func decodeTestObject(t *testing.T, content string) *unstructured.Unstructured {
+	obj, err := decodeObject([]byte(content))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return obj
+}
@@ -0,0 +1,660 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+
+	admissionv1b1 "k8s.io/api/admission/v1beta1"
+	admissionregv1b1 "k8s.io/api/admissionregistration/v1beta1"
+	apiextv1b1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	kubeclientset "k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kubernetes-sigs/federation-v2/pkg/apis/core/typeconfig"
+	corev1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+	"github.com/kubernetes-sigs/federation-v2/pkg/kubefed2/enable"
+)
+
+const (
+	// The name of the ValidatingWebhookConfiguration registering the
+	// validating webhook for federated types.
+	validatingWebhookConfigurationName = "federation-v2-validating-webhook"
+	validatingWebhookName              = "federatedtypes.validation.core.federation.k8s.io"
+	validatingWebhookPath              = "/validate-federated-types"
+
+	// The name of the MutatingWebhookConfiguration registering the
+	// defaulting webhook for federated types.
+	mutatingWebhookConfigurationName = "federation-v2-mutating-webhook"
+	mutatingWebhookName              = "federatedtypes.mutation.core.federation.k8s.io"
+	mutatingWebhookPath              = "/mutate-federated-types"
+
+	certFile   = "tls.crt"
+	keyFile    = "tls.key"
+	caCertFile = "ca.crt"
+)
+
+// ServerOptions configures the serving of the admission webhooks for
+// federated types.
+type ServerOptions struct {
+	// The port to serve webhooks on.
+	Port int
+	// The directory containing the tls.crt and tls.key used to serve
+	// webhooks, and the ca.crt the API server uses to verify them.
+	// The tls.crt is used to verify the webhooks if ca.crt is not
+	// present.
+	CertDir string
+	// The name of the service in the federation namespace that routes
+	// to the webhook server.
+	ServiceName string
+	// Whether the template of a federated resource is validated
+	// against the schema of its target type.
+	ValidateTemplates bool
+}
+
+// placementPolicySource provides the PlacementPolicies that apply to a
+// federated resource.
+type placementPolicySource interface {
+	HasSynced() bool
+	PoliciesFor(fedObject *unstructured.Unstructured) ([]*corev1a1.PlacementPolicy, error)
+}
+
+// Server serves the admission webhooks for the federated types
+// configured by FederatedTypeConfigs, and registers the webhooks with
+// the API server as types are added or removed.
+type Server struct {
+	controllerConfig *util.ControllerConfig
+	options          ServerOptions
+
+	kubeClient kubeclientset.Interface
+
+	// The bundle of CA certificates used by the API server to verify
+	// the webhook server.
+	caBundle []byte
+
+	// Store for the FederatedTypeConfig objects
+	store cache.Store
+	// Informer for the FederatedTypeConfig objects
+	controller cache.Controller
+
+	// Registers the webhooks whenever the FederatedTypeConfigs change
+	worker util.ReconcileWorker
+
+	// Determines whether placement will be defaulted by a
+	// PlacementPolicy rather than from the containing namespace.
+	// Shared with the sync controllers, which run it.
+	policyManager placementPolicySource
+
+	// Store for the federated namespaces whose placement is the
+	// default placement of the resources they contain
+	namespaceStore cache.Store
+	// Informer for the federated namespaces, started once the
+	// federated type for namespaces is configured
+	namespaceController cache.Controller
+	namespaceKind       string
+	namespaceLock       sync.Mutex
+
+	stopChan <-chan struct{}
+
+	// Template schemas keyed by the name of the FederatedTypeConfig
+	// of the federated type.
+	templateSchemas map[string]map[string]apiextv1b1.JSONSchemaProps
+	schemaLock      sync.Mutex
+}
+
+// StartWebhookServer starts serving the admission webhooks for
+// federated types.
+func StartWebhookServer(config *util.ControllerConfig, options ServerOptions, placementPolicyManager *util.PlacementPolicyManager,
+	stopChan <-chan struct{}) error {
+	server, err := newServer(config, options, placementPolicyManager)
+	if err != nil {
+		return err
+	}
+	glog.Infof("Starting admission webhook server on port %d", options.Port)
+	server.Run(stopChan)
+	return nil
+}
+
+func newServer(config *util.ControllerConfig, options ServerOptions, placementPolicyManager *util.PlacementPolicyManager) (*Server, error) {
+	caBundle, err := ioutil.ReadFile(filepath.Join(options.CertDir, caCertFile))
+	if os.IsNotExist(err) {
+		caBundle, err = ioutil.ReadFile(filepath.Join(options.CertDir, certFile))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading the CA bundle for admission webhooks")
+	}
+
+	kubeConfig := restclient.CopyConfig(config.KubeConfig)
+	restclient.AddUserAgent(kubeConfig, "federation-webhook")
+	kubeClient, err := kubeclientset.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		controllerConfig: config,
+		options:          options,
+		kubeClient:       kubeClient,
+		caBundle:         caBundle,
+		policyManager:    placementPolicyManager,
+		templateSchemas:  make(map[string]map[string]apiextv1b1.JSONSchemaProps),
+	}
+
+	s.worker = util.NewReconcileWorker(s.reconcile, util.WorkerTiming{})
+
+	s.store, s.controller, err = util.NewGenericInformerWithEventHandler(
+		kubeConfig,
+		config.FederationNamespace,
+		&corev1a1.FederatedTypeConfig{},
+		util.NoResyncPeriod,
+		&cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				s.typeConfigChanged(obj)
+			},
+			UpdateFunc: func(old, cur interface{}) {
+				s.typeConfigChanged(cur)
+			},
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				s.typeConfigChanged(obj)
+			},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Run runs the Server.
+func (s *Server) Run(stopChan <-chan struct{}) {
+	s.stopChan = stopChan
+	go s.controller.Run(stopChan)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(validatingWebhookPath, func(w http.ResponseWriter, r *http.Request) {
+		serveAdmission(w, r, s.validate)
+	})
+	mux.HandleFunc(mutatingWebhookPath, func(w http.ResponseWriter, r *http.Request) {
+		serveAdmission(w, r, s.mutate)
+	})
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", s.options.Port),
+		Handler: mux,
+	}
+	go func() {
+		err := httpServer.ListenAndServeTLS(filepath.Join(s.options.CertDir, certFile), filepath.Join(s.options.CertDir, keyFile))
+		if err != http.ErrServerClosed {
+			glog.Fatalf("Error serving admission webhooks: %v", err)
+		}
+	}()
+	go func() {
+		<-stopChan
+		httpServer.Shutdown(context.Background())
+	}()
+
+	// wait for the caches to synchronize before registering the webhooks
+	if !cache.WaitForCacheSync(stopChan, s.controller.HasSynced, s.policyManager.HasSynced) {
+		runtime.HandleError(errors.New("Timed out waiting for cache to sync"))
+		return
+	}
+
+	s.worker.Run(stopChan)
+	s.worker.Enqueue(util.QualifiedName{Name: validatingWebhookConfigurationName})
+}
+
+func (s *Server) typeConfigChanged(obj interface{}) {
+	typeConfig, ok := obj.(*corev1a1.FederatedTypeConfig)
+	if !ok {
+		return
+	}
+	s.schemaLock.Lock()
+	delete(s.templateSchemas, typeConfig.Name)
+	s.schemaLock.Unlock()
+	s.worker.Enqueue(util.QualifiedName{Name: validatingWebhookConfigurationName})
+}
+
+// reconcile ensures the validating and mutating webhooks are
+// registered for every configured federated type.
+func (s *Server) reconcile(qualifiedName util.QualifiedName) util.ReconciliationStatus {
+	synced, err := s.ensureNamespaceInformer()
+	if err != nil {
+		runtime.HandleError(err)
+		return util.StatusError
+	}
+	if !synced {
+		return util.StatusNotSynced
+	}
+
+	var rules []admissionregv1b1.RuleWithOperations
+	for _, typeConfig := range s.typeConfigs() {
+		federatedType := typeConfig.GetFederatedType()
+		rules = append(rules, admissionregv1b1.RuleWithOperations{
+			Operations: []admissionregv1b1.OperationType{
+				admissionregv1b1.Create,
+				admissionregv1b1.Update,
+			},
+			Rule: admissionregv1b1.Rule{
+				APIGroups:   []string{federatedType.Group},
+				APIVersions: []string{federatedType.Version},
+				Resources:   []string{federatedType.Name},
+			},
+		})
+	}
+
+	err = s.registerValidatingWebhook(s.newWebhook(validatingWebhookName, validatingWebhookPath, rules))
+	if err != nil {
+		runtime.HandleError(err)
+		return util.StatusError
+	}
+	err = s.registerMutatingWebhook(s.newWebhook(mutatingWebhookName, mutatingWebhookPath, rules))
+	if err != nil {
+		runtime.HandleError(err)
+		return util.StatusError
+	}
+	glog.V(2).Infof("Registered admission webhooks for %d federated types", len(rules))
+	return util.StatusAllOK
+}
+
+func (s *Server) newWebhook(name, path string, rules []admissionregv1b1.RuleWithOperations) admissionregv1b1.Webhook {
+	// Admission should not be prevented by the controller manager
+	// being unavailable.
+	failurePolicy := admissionregv1b1.Ignore
+	return admissionregv1b1.Webhook{
+		Name:  name,
+		Rules: rules,
+		ClientConfig: admissionregv1b1.WebhookClientConfig{
+			Service: &admissionregv1b1.ServiceReference{
+				Namespace: s.controllerConfig.FederationNamespace,
+				Name:      s.options.ServiceName,
+				Path:      &path,
+			},
+			CABundle: s.caBundle,
+		},
+		FailurePolicy: &failurePolicy,
+	}
+}
+
+func (s *Server) registerValidatingWebhook(webhook admissionregv1b1.Webhook) error {
+	webhooks := []admissionregv1b1.Webhook{webhook}
+	client := s.kubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations()
+	webhookConfig, err := client.Get(validatingWebhookConfigurationName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		webhookConfig = &admissionregv1b1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: validatingWebhookConfigurationName,
+			},
+			Webhooks: webhooks,
+		}
+		_, err = client.Create(webhookConfig)
+		return errors.Wrapf(err, "Failed to create ValidatingWebhookConfiguration %q", validatingWebhookConfigurationName)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "Failed to retrieve ValidatingWebhookConfiguration %q", validatingWebhookConfigurationName)
+	}
+	if !webhooksChanged(webhookConfig.Webhooks, webhooks) {
+		return nil
+	}
+	webhookConfig.Webhooks = webhooks
+	_, err = client.Update(webhookConfig)
+	return errors.Wrapf(err, "Failed to update ValidatingWebhookConfiguration %q", validatingWebhookConfigurationName)
+}
+
+func (s *Server) registerMutatingWebhook(webhook admissionregv1b1.Webhook) error {
+	webhooks := []admissionregv1b1.Webhook{webhook}
+	client := s.kubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+	webhookConfig, err := client.Get(mutatingWebhookConfigurationName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		webhookConfig = &admissionregv1b1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: mutatingWebhookConfigurationName,
+			},
+			Webhooks: webhooks,
+		}
+		_, err = client.Create(webhookConfig)
+		return errors.Wrapf(err, "Failed to create MutatingWebhookConfiguration %q", mutatingWebhookConfigurationName)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "Failed to retrieve MutatingWebhookConfiguration %q", mutatingWebhookConfigurationName)
+	}
+	if !webhooksChanged(webhookConfig.Webhooks, webhooks) {
+		return nil
+	}
+	webhookConfig.Webhooks = webhooks
+	_, err = client.Update(webhookConfig)
+	return errors.Wrapf(err, "Failed to update MutatingWebhookConfiguration %q", mutatingWebhookConfigurationName)
+}
+
+// webhooksChanged indicates whether the registered webhooks differ
+// from the desired webhooks.  Fields defaulted by the API server are
+// not compared.
+func webhooksChanged(registered, desired []admissionregv1b1.Webhook) bool {
+	if len(registered) != len(desired) {
+		return true
+	}
+	for i := range desired {
+		if registered[i].Name != desired[i].Name ||
+			!reflect.DeepEqual(registered[i].Rules, desired[i].Rules) ||
+			!reflect.DeepEqual(registered[i].ClientConfig, desired[i].ClientConfig) ||
+			!reflect.DeepEqual(registered[i].FailurePolicy, desired[i].FailurePolicy) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) typeConfigs() []typeconfig.Interface {
+	typeConfigs := []typeconfig.Interface{}
+	for _, obj := range s.store.List() {
+		typeConfig := obj.(*corev1a1.FederatedTypeConfig)
+		if typeConfig.DeletionTimestamp != nil {
+			continue
+		}
+		typeConfig = typeConfig.DeepCopy()
+		corev1a1.SetFederatedTypeConfigDefaults(typeConfig)
+		typeConfigs = append(typeConfigs, typeConfig)
+	}
+	return typeConfigs
+}
+
+// typeConfigForResource returns the configuration of the federated
+// type of the given resource, or nil if the type is not configured.
+func (s *Server) typeConfigForResource(resource metav1.GroupVersionResource) typeconfig.Interface {
+	for _, typeConfig := range s.typeConfigs() {
+		federatedType := typeConfig.GetFederatedType()
+		if federatedType.Group == resource.Group && federatedType.Version == resource.Version && federatedType.Name == resource.Resource {
+			return typeConfig
+		}
+	}
+	return nil
+}
+
+// templateSchema returns the schema of the template of the given
+// federated type, retrieving it from the API server if it has not
+// been retrieved since the type was last changed.
+func (s *Server) templateSchema(typeConfig typeconfig.Interface) (map[string]apiextv1b1.JSONSchemaProps, error) {
+	name := typeConfig.GetObjectMeta().Name
+	s.schemaLock.Lock()
+	defer s.schemaLock.Unlock()
+	if schema, ok := s.templateSchemas[name]; ok {
+		return schema, nil
+	}
+	schema, err := enable.TemplateSchema(s.controllerConfig.KubeConfig, typeConfig.GetTarget())
+	if err != nil {
+		return nil, err
+	}
+	s.templateSchemas[name] = schema
+	return schema, nil
+}
+
+// validate admits a federated resource if it is valid.
+func (s *Server) validate(request *admissionv1b1.AdmissionRequest) *admissionv1b1.AdmissionResponse {
+	typeConfig := s.typeConfigForResource(request.Resource)
+	if typeConfig == nil {
+		return allowed()
+	}
+
+	fedObject, err := decodeObject(request.Object.Raw)
+	if err != nil {
+		return denied(http.StatusBadRequest, metav1.StatusReasonBadRequest, err)
+	}
+
+	// A resource being deleted is not validated so that the removal
+	// of finalizers cannot be prevented by a resource admitted before
+	// validation was stricter.
+	if fedObject.GetDeletionTimestamp() != nil {
+		return allowed()
+	}
+	// Similarly, an update is only validated if it changes the spec,
+	// so that the status of such a resource can still be written.
+	if request.Operation == admissionv1b1.Update {
+		oldObject, err := decodeObject(request.OldObject.Raw)
+		if err != nil {
+			return denied(http.StatusBadRequest, metav1.StatusReasonBadRequest, err)
+		}
+		if reflect.DeepEqual(oldObject.Object[util.SpecField], fedObject.Object[util.SpecField]) {
+			return allowed()
+		}
+	}
+
+	errs := ValidateFederatedResource(typeConfig, fedObject)
+	if s.options.ValidateTemplates && typeConfig.GetTarget().Kind != util.NamespaceKind {
+		schema, err := s.templateSchema(typeConfig)
+		if err != nil {
+			runtime.HandleError(errors.Wrapf(err, "Unable to validate the template of %s %q", typeConfig.GetFederatedType().Kind, util.NewQualifiedName(fedObject)))
+		} else {
+			errs = append(errs, ValidateTemplate(fedObject, schema)...)
+		}
+	}
+	if len(errs) > 0 {
+		return denied(http.StatusUnprocessableEntity, metav1.StatusReasonInvalid, utilerrors.NewAggregate(errs))
+	}
+	return allowed()
+}
+
+// mutate admits a federated resource with defaults applied.  Failure
+// to apply defaults does not prevent admission.
+func (s *Server) mutate(request *admissionv1b1.AdmissionRequest) *admissionv1b1.AdmissionResponse {
+	typeConfig := s.typeConfigForResource(request.Resource)
+	if typeConfig == nil {
+		return allowed()
+	}
+
+	fedObject, err := decodeObject(request.Object.Raw)
+	if err != nil {
+		return denied(http.StatusBadRequest, metav1.StatusReasonBadRequest, err)
+	}
+
+	fedKind := typeConfig.GetFederatedType().Kind
+	qualifiedName := util.NewQualifiedName(fedObject)
+	// Placement is only defaulted on creation.  A resource admitted
+	// without placement, or whose placement was removed to withdraw
+	// it from its clusters, must not acquire the placement of its
+	// namespace when it is next updated.
+	var fedNamespace *unstructured.Unstructured
+	if request.Operation == admissionv1b1.Create {
+		fedNamespace, err = s.federatedNamespaceFor(typeConfig, fedObject)
+		if err != nil {
+			runtime.HandleError(errors.Wrapf(err, "Unable to default placement of %s %q", fedKind, qualifiedName))
+		}
+	}
+	defaultedObject, err := DefaultFederatedResource(typeConfig, fedObject, fedNamespace)
+	if err != nil {
+		runtime.HandleError(errors.Wrapf(err, "Unable to apply defaults to %s %q", fedKind, qualifiedName))
+		return allowed()
+	}
+
+	spec := defaultedObject.Object[util.SpecField]
+	if reflect.DeepEqual(fedObject.Object[util.SpecField], spec) {
+		return allowed()
+	}
+	patch, err := json.Marshal([]map[string]interface{}{
+		{
+			"op":    "add",
+			"path":  "/" + util.SpecField,
+			"value": spec,
+		},
+	})
+	if err != nil {
+		runtime.HandleError(errors.Wrapf(err, "Unable to apply defaults to %s %q", fedKind, qualifiedName))
+		return allowed()
+	}
+	patchType := admissionv1b1.PatchTypeJSONPatch
+	return &admissionv1b1.AdmissionResponse{
+		Allowed:   true,
+		Patch:     patch,
+		PatchType: &patchType,
+	}
+}
+
+// federatedNamespaceFor returns the federated namespace whose placement
+// should be the default placement of the given federated resource, or
+// nil if placement should not be defaulted from the namespace.
+// Placement is not defaulted if the resource specifies placement or
+// if a PlacementPolicy applying to the resource defines a default
+// placement, since the sync controller will apply it.
+func (s *Server) federatedNamespaceFor(typeConfig typeconfig.Interface, fedObject *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	namespace := fedObject.GetNamespace()
+	if !typeConfig.GetNamespaced() || typeConfig.GetTarget().Kind == util.NamespaceKind || len(namespace) == 0 {
+		return nil, nil
+	}
+	specified, err := util.PlacementSpecified(fedObject)
+	if err != nil || specified {
+		return nil, err
+	}
+	policies, err := s.policyManager.PoliciesFor(fedObject)
+	if err != nil {
+		return nil, err
+	}
+	for _, policy := range policies {
+		if policy.Spec.DefaultPlacement != nil {
+			return nil, nil
+		}
+	}
+
+	s.namespaceLock.Lock()
+	store, kind := s.namespaceStore, s.namespaceKind
+	s.namespaceLock.Unlock()
+	if store == nil {
+		if s.namespaceTypeConfig() != nil {
+			return nil, errors.New("Federated namespaces are not yet cached")
+		}
+		return nil, nil
+	}
+	key := util.QualifiedName{Namespace: namespace, Name: namespace}.String()
+	return util.ObjFromCache(store, kind, key)
+}
+
+// namespaceTypeConfig returns the configuration of the federated type
+// for namespaces, or nil if namespaces are not federated.
+func (s *Server) namespaceTypeConfig() typeconfig.Interface {
+	for _, typeConfig := range s.typeConfigs() {
+		if typeConfig.GetTarget().Kind == util.NamespaceKind {
+			return typeConfig
+		}
+	}
+	return nil
+}
+
+// ensureNamespaceInformer starts the informer for federated namespaces
+// once the federated type for namespaces is configured, and indicates
+// whether it has synced.
+func (s *Server) ensureNamespaceInformer() (bool, error) {
+	namespaceTypeConfig := s.namespaceTypeConfig()
+
+	s.namespaceLock.Lock()
+	defer s.namespaceLock.Unlock()
+	if s.namespaceController == nil {
+		if namespaceTypeConfig == nil {
+			return true, nil
+		}
+		apiResource := namespaceTypeConfig.GetFederatedType()
+		client, err := util.NewResourceClient(s.controllerConfig.KubeConfig, &apiResource)
+		if err != nil {
+			return false, errors.Wrapf(err, "Failed to create a client for %s", apiResource.Kind)
+		}
+		// Federated namespaces are only consulted at admission, so
+		// changes require no action.
+		s.namespaceStore, s.namespaceController = util.NewResourceInformer(client, s.controllerConfig.TargetNamespace, func(pkgruntime.Object) {})
+		s.namespaceKind = apiResource.Kind
+		go s.namespaceController.Run(s.stopChan)
+	}
+	return s.namespaceController.HasSynced(), nil
+}
+
+type admitFunc func(request *admissionv1b1.AdmissionRequest) *admissionv1b1.AdmissionResponse
+
+// serveAdmission decodes the AdmissionReview in the body of the given
+// request and responds with the result of admitting it.
+func serveAdmission(w http.ResponseWriter, r *http.Request, admit admitFunc) {
+	if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+		http.Error(w, fmt.Sprintf("Unsupported content type %q", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := &admissionv1b1.AdmissionReview{}
+	err = json.Unmarshal(body, review)
+	if err != nil || review.Request == nil {
+		http.Error(w, "Unable to decode the admission review", http.StatusBadRequest)
+		return
+	}
+
+	response := admit(review.Request)
+	response.UID = review.Request.UID
+	review.Response = response
+	review.Request = nil
+
+	data, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func decodeObject(raw []byte) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	err := obj.UnmarshalJSON(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error decoding object")
+	}
+	return obj, nil
+}
+
+func allowed() *admissionv1b1.AdmissionResponse {
+	return &admissionv1b1.AdmissionResponse{Allowed: true}
+}
+
+func denied(code int32, reason metav1.StatusReason, err error) *admissionv1b1.AdmissionResponse {
+	return &admissionv1b1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    code,
+			Reason:  reason,
+			Message: err.Error(),
+		},
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	apiextv1b1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kubernetes-sigs/federation-v2/pkg/apis/core/typeconfig"
+	"github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+)
+
+// ValidateFederatedResource returns the errors that would prevent the
+// sync controller from propagating the given federated resource.
+func ValidateFederatedResource(typeConfig typeconfig.Interface, fedObject *unstructured.Unstructured) []error {
+	errs := []error{}
+	if _, err := util.GetPlacementDirective(fedObject); err != nil {
+		errs = append(errs, errors.Wrap(err, "Invalid placement"))
+	}
+	if _, err := util.GetOverrides(fedObject); err != nil {
+		errs = append(errs, errors.Wrap(err, "Invalid overrides"))
+	}
+	if _, err := util.GetRemovalPolicy(fedObject); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := util.GetConflictPolicy(fedObject, typeConfig.GetConflictPolicy()); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := util.GetDriftPolicy(fedObject, typeConfig.GetDriftPolicy()); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := util.GetRolloutDirective(fedObject); err != nil {
+		errs = append(errs, errors.Wrap(err, "Invalid rolloutStrategy"))
+	}
+	if _, err := util.GetFailureThresholdPercent(fedObject); err != nil {
+		errs = append(errs, errors.Wrap(err, "Invalid autoRollback"))
+	}
+	if _, err := util.GetDependencies(fedObject); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// ValidateTemplate returns the errors resulting from validating the
+// template of the given federated resource against the given schema
+// of its target type.  Fields that are not defined by the schema and
+// values of the wrong type are reported.  Required fields are not
+// checked since they may be provided by overrides.
+func ValidateTemplate(fedObject *unstructured.Unstructured, templateSchema map[string]apiextv1b1.JSONSchemaProps) []error {
+	if templateSchema == nil {
+		return nil
+	}
+	template, ok, err := unstructured.NestedFieldNoCopy(fedObject.Object, util.SpecField, util.TemplateField)
+	if err != nil {
+		return []error{errors.Wrap(err, "Error retrieving template")}
+	}
+	if !ok {
+		return nil
+	}
+	schema := apiextv1b1.JSONSchemaProps{
+		Type:       "object",
+		Properties: templateSchema,
+	}
+	return validateValue(fmt.Sprintf("%s.%s", util.SpecField, util.TemplateField), template, schema)
+}
+
+func validateValue(path string, value interface{}, schema apiextv1b1.JSONSchemaProps) []error {
+	if value == nil {
+		return nil
+	}
+	if len(schema.AnyOf) > 0 {
+		for _, alternative := range schema.AnyOf {
+			if len(validateValue(path, value, alternative)) == 0 {
+				return nil
+			}
+		}
+		return []error{errors.Errorf("%s has a value of an invalid type", path)}
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []error{invalidTypeError(path, schema.Type)}
+		}
+		errs := []error{}
+		for _, key := range sortedKeys(object) {
+			fieldPath := fmt.Sprintf("%s.%s", path, key)
+			if fieldSchema, ok := schema.Properties[key]; ok {
+				errs = append(errs, validateValue(fieldPath, object[key], fieldSchema)...)
+				continue
+			}
+			if schema.AdditionalProperties != nil {
+				if schema.AdditionalProperties.Schema != nil {
+					errs = append(errs, validateValue(fieldPath, object[key], *schema.AdditionalProperties.Schema)...)
+				}
+				continue
+			}
+			// An object without properties may contain any fields
+			if len(schema.Properties) > 0 {
+				errs = append(errs, errors.Errorf("%s is not a known field", fieldPath))
+			}
+		}
+		return errs
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []error{invalidTypeError(path, schema.Type)}
+		}
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return nil
+		}
+		errs := []error{}
+		for i, item := range items {
+			errs = append(errs, validateValue(fmt.Sprintf("%s[%d]", path, i), item, *schema.Items.Schema)...)
+		}
+		return errs
+	case "string":
+		if _, ok := value.(string); !ok {
+			return []error{invalidTypeError(path, schema.Type)}
+		}
+	case "integer":
+		if _, ok := value.(int64); !ok {
+			return []error{invalidTypeError(path, schema.Type)}
+		}
+	case "number":
+		switch value.(type) {
+		case int64, float64:
+		default:
+			return []error{invalidTypeError(path, schema.Type)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []error{invalidTypeError(path, schema.Type)}
+		}
+	}
+	return nil
+}
+
+func invalidTypeError(path, expectedType string) error {
+	return errors.Errorf("%s must be of type %s", path, expectedType)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,341 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"reflect"
+	"testing"
+
+	admissionv1b1 "k8s.io/api/admission/v1beta1"
+	apiextv1b1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	corev1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+func TestValidateFederatedResource(t *testing.T) {
+	testCases := map[string]struct {
+		spec        string
+		annotations string
+		expectedErr bool
+	}{
+		"valid": {
+			spec: `{"placement": {"clusterNames": ["cluster1"]}, "overrides": [{"clusterName": "cluster1", "clusterOverrides": [{"path": "spec.replicas", "value": 2}]}]}`,
+		},
+		"invalid placement selector": {
+			spec:        `{"placement": {"clusterSelector": {"matchExpressions": [{"key": "foo", "operator": "Bogus"}]}}}`,
+			expectedErr: true,
+		},
+		"duplicate override cluster": {
+			spec:        `{"overrides": [{"clusterName": "cluster1"}, {"clusterName": "cluster1"}]}`,
+			expectedErr: true,
+		},
+		"override of metadata.name": {
+			spec:        `{"overrides": [{"clusterName": "cluster1", "clusterOverrides": [{"path": "metadata.name", "value": "foo"}]}]}`,
+			expectedErr: true,
+		},
+		"invalid removal policy": {
+			spec:        `{"removalPolicy": "Bogus"}`,
+			expectedErr: true,
+		},
+		"invalid conflict policy": {
+			spec:        `{}`,
+			annotations: `{"federation.k8s.io/conflict-policy": "Bogus"}`,
+			expectedErr: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			annotations := testCase.annotations
+			if len(annotations) == 0 {
+				annotations = `{}`
+			}
+			fedObject, err := decodeObject([]byte(`{"apiVersion": "types.federation.k8s.io/v1alpha1", "kind": "FederatedDeployment", "metadata": {"name": "foo", "annotations": ` + annotations + `}, "spec": ` + testCase.spec + `}`))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			errs := ValidateFederatedResource(&corev1a1.FederatedTypeConfig{}, fedObject)
+			if testCase.expectedErr && len(errs) == 0 {
+				t.Fatalf("Expected an error")
+			}
+			if !testCase.expectedErr && len(errs) > 0 {
+				t.Fatalf("Unexpected errors: %v", errs)
+			}
+		})
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	templateSchema := map[string]apiextv1b1.JSONSchemaProps{
+		"metadata": {
+			Type: "object",
+			Properties: map[string]apiextv1b1.JSONSchemaProps{
+				"labels": {
+					Type: "object",
+					AdditionalProperties: &apiextv1b1.JSONSchemaPropsOrBool{
+						Allows: true,
+						Schema: &apiextv1b1.JSONSchemaProps{Type: "string"},
+					},
+				},
+			},
+		},
+		"spec": {
+			Type: "object",
+			Properties: map[string]apiextv1b1.JSONSchemaProps{
+				"replicas": {Type: "integer"},
+				"ports": {
+					Type: "array",
+					Items: &apiextv1b1.JSONSchemaPropsOrArray{
+						Schema: &apiextv1b1.JSONSchemaProps{
+							Type: "object",
+							Properties: map[string]apiextv1b1.JSONSchemaProps{
+								"port": {
+									AnyOf: []apiextv1b1.JSONSchemaProps{
+										{Type: "integer"},
+										{Type: "string"},
+									},
+								},
+							},
+						},
+					},
+				},
+				"config": {Type: "object"},
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		template       string
+		expectedErrors []string
+	}{
+		"valid": {
+			template: `{"metadata": {"labels": {"app": "foo"}}, "spec": {"replicas": 2, "ports": [{"port": 80}, {"port": "http"}], "config": {"any": ["value"]}}}`,
+		},
+		"unknown field": {
+			template: `{"spec": {"replica": 2}}`,
+			expectedErrors: []string{
+				"spec.template.spec.replica is not a known field",
+			},
+		},
+		"invalid types": {
+			template: `{"metadata": {"labels": {"app": 1}}, "spec": {"replicas": "2", "ports": [{"port": true}]}}`,
+			expectedErrors: []string{
+				"spec.template.metadata.labels.app must be of type string",
+				"spec.template.spec.ports[0].port has a value of an invalid type",
+				"spec.template.spec.replicas must be of type integer",
+			},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			fedObject, err := decodeObject([]byte(`{"apiVersion": "types.federation.k8s.io/v1alpha1", "kind": "FederatedDeployment", "metadata": {"name": "foo"}, "spec": {"template": ` + testCase.template + `}}`))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			errs := []string{}
+			for _, err := range ValidateTemplate(fedObject, templateSchema) {
+				errs = append(errs, err.Error())
+			}
+			if len(testCase.expectedErrors) == 0 {
+				testCase.expectedErrors = []string{}
+			}
+			if !reflect.DeepEqual(testCase.expectedErrors, errs) {
+				t.Fatalf("Expected errors %v, got %v", testCase.expectedErrors, errs)
+			}
+		})
+	}
+}
+
+func TestValidateAdmission(t *testing.T) {
+	validSpec := `{"placement": {"clusterNames": ["cluster1"]}}`
+	invalidSpec := `{"removalPolicy": "Bogus"}`
+	federatedDeployment := func(spec, extraMetadata string) []byte {
+		return []byte(`{"apiVersion": "types.federation.k8s.io/v1alpha1", "kind": "FederatedDeployment", "metadata": {"name": "foo"` + extraMetadata + `}, "spec": ` + spec + `}`)
+	}
+
+	testCases := map[string]struct {
+		operation       admissionv1b1.Operation
+		object          []byte
+		oldObject       []byte
+		expectedAllowed bool
+	}{
+		"valid resource is created": {
+			operation:       admissionv1b1.Create,
+			object:          federatedDeployment(validSpec, ""),
+			expectedAllowed: true,
+		},
+		"invalid resource is not created": {
+			operation: admissionv1b1.Create,
+			object:    federatedDeployment(invalidSpec, ""),
+		},
+		"invalid spec change is denied": {
+			operation: admissionv1b1.Update,
+			object:    federatedDeployment(invalidSpec, ""),
+			oldObject: federatedDeployment(validSpec, ""),
+		},
+		"update of invalid resource without spec change is allowed": {
+			operation:       admissionv1b1.Update,
+			object:          federatedDeployment(invalidSpec, `, "labels": {"foo": "bar"}`),
+			oldObject:       federatedDeployment(invalidSpec, ""),
+			expectedAllowed: true,
+		},
+		"update of invalid resource being deleted is allowed": {
+			operation:       admissionv1b1.Update,
+			object:          federatedDeployment(`{"removalPolicy": "Other"}`, `, "deletionTimestamp": "2019-01-01T00:00:00Z"`),
+			oldObject:       federatedDeployment(invalidSpec, `, "deletionTimestamp": "2019-01-01T00:00:00Z", "finalizers": ["federation.k8s.io/delete-from-underlying-clusters"]`),
+			expectedAllowed: true,
+		},
+	}
+
+	typeConfig := &corev1a1.FederatedTypeConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "deployments.apps"},
+		Spec: corev1a1.FederatedTypeConfigSpec{
+			Target: corev1a1.APIResource{Version: "v1", Kind: "Deployment"},
+			FederatedType: corev1a1.APIResource{
+				Group:   "types.federation.k8s.io",
+				Version: "v1alpha1",
+				Kind:    "FederatedDeployment",
+			},
+		},
+	}
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	store.Add(typeConfig)
+	server := &Server{store: store}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			request := &admissionv1b1.AdmissionRequest{
+				Resource: metav1.GroupVersionResource{
+					Group:    "types.federation.k8s.io",
+					Version:  "v1alpha1",
+					Resource: "federateddeployments",
+				},
+				Operation: testCase.operation,
+				Object:    runtime.RawExtension{Raw: testCase.object},
+				OldObject: runtime.RawExtension{Raw: testCase.oldObject},
+			}
+			response := server.validate(request)
+			if response.Allowed != testCase.expectedAllowed {
+				t.Fatalf("Expected allowed to be %v, got %v: %v", testCase.expectedAllowed, response.Allowed, response.Result)
+			}
+		})
+	}
+}
+
+// fakePlacementPolicySource provides no PlacementPolicies.
+type fakePlacementPolicySource struct{}
+
+func (fakePlacementPolicySource) HasSynced() bool {
+	return true
+}
+
+func (fakePlacementPolicySource) PoliciesFor(fedObject *unstructured.Unstructured) ([]*corev1a1.PlacementPolicy, error) {
+	return nil, nil
+}
+
+func TestMutateAdmission(t *testing.T) {
+	federatedDeployment := func(spec string) []byte {
+		return []byte(`{"apiVersion": "types.federation.k8s.io/v1alpha1", "kind": "FederatedDeployment", "metadata": {"namespace": "ns", "name": "foo"}, "spec": ` + spec + `}`)
+	}
+	withoutPlacement := `{"template": {"spec": {"replicas": 1}}}`
+	withPlacement := `{"template": {"spec": {"replicas": 1}}, "placement": {"clusterNames": ["cluster2"]}}`
+	withStatus := `{"template": {"spec": {"replicas": 1}, "status": {"replicas": 1}}}`
+
+	testCases := map[string]struct {
+		operation     admissionv1b1.Operation
+		object        []byte
+		expectedPatch string
+	}{
+		"placement is defaulted from the namespace on creation": {
+			operation:     admissionv1b1.Create,
+			object:        federatedDeployment(withoutPlacement),
+			expectedPatch: `[{"op":"add","path":"/spec","value":{"placement":{"clusterNames":["cluster1"]},"template":{"spec":{"replicas":1}}}}]`,
+		},
+		"specified placement is not defaulted on creation": {
+			operation: admissionv1b1.Create,
+			object:    federatedDeployment(withPlacement),
+		},
+		"placement is not defaulted on update": {
+			operation: admissionv1b1.Update,
+			object:    federatedDeployment(withoutPlacement),
+		},
+		"template is defaulted on update": {
+			operation:     admissionv1b1.Update,
+			object:        federatedDeployment(withStatus),
+			expectedPatch: `[{"op":"add","path":"/spec","value":{"template":{"spec":{"replicas":1}}}}]`,
+		},
+	}
+
+	typeConfig := &corev1a1.FederatedTypeConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "deployments.apps"},
+		Spec: corev1a1.FederatedTypeConfigSpec{
+			Namespaced: true,
+			Target:     corev1a1.APIResource{Group: "apps", Version: "v1", Kind: "Deployment"},
+			FederatedType: corev1a1.APIResource{
+				Group:   "types.federation.k8s.io",
+				Version: "v1alpha1",
+				Kind:    "FederatedDeployment",
+			},
+		},
+	}
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	store.Add(typeConfig)
+	fedNamespace := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"placement": map[string]interface{}{
+				"clusterNames": []interface{}{"cluster1"},
+			},
+		},
+	}}
+	fedNamespace.SetNamespace("ns")
+	fedNamespace.SetName("ns")
+	namespaceStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	namespaceStore.Add(fedNamespace)
+	server := &Server{
+		store:          store,
+		policyManager:  fakePlacementPolicySource{},
+		namespaceStore: namespaceStore,
+		namespaceKind:  "FederatedNamespace",
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			request := &admissionv1b1.AdmissionRequest{
+				Resource: metav1.GroupVersionResource{
+					Group:    "types.federation.k8s.io",
+					Version:  "v1alpha1",
+					Resource: "federateddeployments",
+				},
+				Operation: testCase.operation,
+				Object:    runtime.RawExtension{Raw: testCase.object},
+				OldObject: runtime.RawExtension{Raw: testCase.object},
+			}
+			response := server.mutate(request)
+			if !response.Allowed {
+				t.Fatalf("Expected the resource to be allowed: %v", response.Result)
+			}
+			if testCase.expectedPatch != string(response.Patch) {
+				t.Fatalf("Expected patch %q, got %q", testCase.expectedPatch, string(response.Patch))
+			}
+		})
+	}
+}
@@ -86,6 +86,7 @@ func init() {
 	Injector.CRDs = append(Injector.CRDs, &corev1alpha1.FederatedClusterCRD)
 	Injector.CRDs = append(Injector.CRDs, &corev1alpha1.FederatedServiceStatusCRD)
 	Injector.CRDs = append(Injector.CRDs, &corev1alpha1.FederatedTypeConfigCRD)
+	Injector.CRDs = append(Injector.CRDs, &corev1alpha1.PlacementPolicyCRD)
 	Injector.CRDs = append(Injector.CRDs, &corev1alpha1.PropagatedVersionCRD)
 	Injector.CRDs = append(Injector.CRDs, &multiclusterdnsv1alpha1.DNSEndpointCRD)
 	Injector.CRDs = append(Injector.CRDs, &multiclusterdnsv1alpha1.DomainCRD)
@@ -199,11 +199,20 @@ func deleteFederatedType(config *rest.Config, typeConfig typeconfig.Interface, w
 		return errors.Wrap(err, "Error creating crd client")
 	}
 
-	crdName := typeconfig.GroupQualifiedName(typeConfig.GetFederatedType())
-	err = client.CustomResourceDefinitions().Delete(crdName, nil)
-	if err != nil {
-		return errors.Wrapf(err, "Error deleting crd %q", crdName)
+	crdNames := []string{typeconfig.GroupQualifiedName(typeConfig.GetFederatedType())}
+	// A status type in the group of the federated type was generated
+	// along with the federated type.  A status type in another group
+	// (e.g. FederatedServiceStatus) is not removed.
+	statusAPIResource := typeConfig.GetStatus()
+	if statusAPIResource != nil && statusAPIResource.Group == typeConfig.GetFederatedType().Group {
+		crdNames = append(crdNames, typeconfig.GroupQualifiedName(*statusAPIResource))
+	}
+	for _, crdName := range crdNames {
+		err = client.CustomResourceDefinitions().Delete(crdName, nil)
+		if err != nil {
+			return errors.Wrapf(err, "Error deleting crd %q", crdName)
+		}
+		write(fmt.Sprintf("customresourcedefinition %q deleted\n", crdName))
 	}
-	write(fmt.Sprintf("customresourcedefinition %q deleted\n", crdName))
 	return nil
 }
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubefed2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+func TestDeleteFederatedType(t *testing.T) {
+	federatedType := fedv1a1.APIResource{
+		Group:      "types.federation.k8s.io",
+		Version:    "v1alpha1",
+		Kind:       "FederatedDeployment",
+		PluralName: "federateddeployments",
+	}
+
+	testCases := map[string]struct {
+		status           *fedv1a1.APIResource
+		expectedDeletion []string
+	}{
+		"federated type without a status type": {
+			expectedDeletion: []string{"federateddeployments.types.federation.k8s.io"},
+		},
+		"status type in the group of the federated type is deleted": {
+			status: &fedv1a1.APIResource{
+				Group:      "types.federation.k8s.io",
+				Version:    "v1alpha1",
+				Kind:       "FederatedDeploymentStatus",
+				PluralName: "federateddeploymentstatuses",
+			},
+			expectedDeletion: []string{
+				"federateddeployments.types.federation.k8s.io",
+				"federateddeploymentstatuses.types.federation.k8s.io",
+			},
+		},
+		"status type in another group is retained": {
+			status: &fedv1a1.APIResource{
+				Group:      "core.federation.k8s.io",
+				Version:    "v1alpha1",
+				Kind:       "FederatedServiceStatus",
+				PluralName: "federatedservicestatuses",
+			},
+			expectedDeletion: []string{"federateddeployments.types.federation.k8s.io"},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			var lock sync.Mutex
+			deleted := []string{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				prefix := "/apis/apiextensions.k8s.io/v1beta1/customresourcedefinitions/"
+				if r.Method != http.MethodDelete || !strings.HasPrefix(r.URL.Path, prefix) {
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				lock.Lock()
+				deleted = append(deleted, strings.TrimPrefix(r.URL.Path, prefix))
+				lock.Unlock()
+				w.Header().Set("Content-Type", "application/json")
+				if err := json.NewEncoder(w).Encode(&metav1.Status{Status: metav1.StatusSuccess}); err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			typeConfig := &fedv1a1.FederatedTypeConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "deployments.apps"},
+				Spec: fedv1a1.FederatedTypeConfigSpec{
+					Namespaced:    true,
+					FederatedType: federatedType,
+					Status:        testCase.status,
+				},
+			}
+			err := deleteFederatedType(&rest.Config{Host: server.URL}, typeConfig, func(string) {})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(testCase.expectedDeletion, deleted) {
+				t.Fatalf("Expected deletion of %v, got %v", testCase.expectedDeletion, deleted)
+			}
+		})
+	}
+}
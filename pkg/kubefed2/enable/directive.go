@@ -39,6 +39,20 @@ type EnableTypeDirectiveSpec struct {
 	// The API version to use for generated federation types.
 	// +optional
 	FederationVersion string `json:"federationVersion,omitempty"`
+
+	// Whether to generate a status type for the federated type and
+	// collect the status of the target type from member clusters.
+	// +optional
+	EnableStatus bool `json:"enableStatus,omitempty"`
+
+	// Whether to write the status of the federated type to its status
+	// subresource rather than with a regular update, and the status
+	// collected from member clusters to the federated type rather
+	// than to a separate status type.  Defaults to true so that the
+	// propagation status of the federated type reports the observed
+	// generation.
+	// +optional
+	StatusSubresource bool `json:"statusSubresource,omitempty"`
 }
 
 // TODO(marun) This should become a proper API type and drive enabling
@@ -54,6 +68,7 @@ type EnableTypeDirective struct {
 func (ft *EnableTypeDirective) SetDefaults() {
 	ft.Spec.FederationGroup = defaultFederationGroup
 	ft.Spec.FederationVersion = defaultFederationVersion
+	ft.Spec.StatusSubresource = true
 }
 
 func NewEnableTypeDirective() *EnableTypeDirective {
@@ -53,7 +53,14 @@ var (
 		Enables a Kubernetes API type (including a CRD) to be propagated
 		to members of a federation.  A CRD for the federated type will be
 		generated and a FederatedTypeConfig will be created to configure
-		a sync controller.
+		a sync controller.  The federated type enables the status
+		subresource, so that its propagation status reports the
+		observed generation.  If status collection is enabled, a status
+		controller will be configured to write the status of the target
+		type in member clusters to the federated type.  With
+		--status-subresource=false, the status subresource is not
+		enabled and a CRD for a separate status type recording the
+		collected status will be generated instead.
 
 		Current context is assumed to be a Kubernetes cluster hosting
 		the federation control plane. Please use the
@@ -61,7 +68,14 @@ var (
 
 	enable_example = `
 		# Enable federation of Services with service type overrideable
-		kubefed2 enable Service --override-paths=spec.type --host-cluster-context=cluster1`
+		kubefed2 enable Service --override-paths=spec.type --host-cluster-context=cluster1
+
+		# Enable federation of Deployments and collection of their status
+		kubefed2 enable Deployment --enable-status --host-cluster-context=cluster1
+
+		# Enable federation of ReplicaSets with their collected status
+		# written to a separate status type
+		kubefed2 enable ReplicaSet --enable-status --status-subresource=false --host-cluster-context=cluster1`
 )
 
 type enableType struct {
@@ -77,6 +91,8 @@ type enableTypeOptions struct {
 	output              string
 	outputYAML          bool
 	filename            string
+	enableStatus        bool
+	statusSubresource   bool
 	enableTypeDirective *EnableTypeDirective
 }
 
@@ -88,6 +104,8 @@ func (o *enableTypeOptions) Bind(flags *pflag.FlagSet) {
 	flags.StringVar(&o.federationVersion, "federation-version", defaultFederationVersion, "The API version to use for the generated federation type.")
 	flags.StringVarP(&o.output, "output", "o", "", "If provided, the resources that would be created in the API by the command are instead output to stdout in the provided format.  Valid values are ['yaml'].")
 	flags.StringVarP(&o.filename, "filename", "f", "", "If provided, the command will be configured from the provided yaml file.  Only --output wll be accepted from the command line")
+	flags.BoolVar(&o.enableStatus, "enable-status", false, "Whether to generate a status type for the federated type and collect the status of the target type from member clusters.")
+	flags.BoolVar(&o.statusSubresource, "status-subresource", true, "Whether to enable the status subresource of the federated type and write all status to it.  The status collected with --enable-status is then written to the federated type instead of a separate status type.")
 }
 
 // NewCmdTypeEnable defines the `enable` command that
@@ -153,6 +171,8 @@ func (j *enableType) Complete(args []string) error {
 	if len(j.federationVersion) > 0 {
 		fd.Spec.FederationVersion = j.federationVersion
 	}
+	fd.Spec.EnableStatus = j.enableStatus
+	fd.Spec.StatusSubresource = j.statusSubresource
 
 	return nil
 }
@@ -172,6 +192,9 @@ func (j *enableType) Run(cmdOut io.Writer, config util.FedConfig) error {
 	if j.outputYAML {
 		concreteTypeConfig := resources.TypeConfig.(*fedv1a1.FederatedTypeConfig)
 		objects := []pkgruntime.Object{concreteTypeConfig, resources.CRD}
+		if resources.StatusCRD != nil {
+			objects = append(objects, resources.StatusCRD)
+		}
 		err := writeObjectsToYAML(objects, cmdOut)
 		if err != nil {
 			return errors.Wrap(err, "Failed to write objects to YAML")
@@ -191,6 +214,8 @@ func (j *enableType) Run(cmdOut io.Writer, config util.FedConfig) error {
 type typeResources struct {
 	TypeConfig typeconfig.Interface
 	CRD        *apiextv1b1.CustomResourceDefinition
+	// The CRD of the status type, if status collection is enabled.
+	StatusCRD *apiextv1b1.CustomResourceDefinition
 }
 
 func GetResources(config *rest.Config, enableTypeDirective *EnableTypeDirective) (*typeResources, error) {
@@ -200,6 +225,13 @@ func GetResources(config *rest.Config, enableTypeDirective *EnableTypeDirective)
 	}
 	glog.V(2).Infof("Found resource %q", resourceKey(*apiResource))
 
+	// A FederatedNamespace is namespaced but its target is not, so a
+	// status type with the scope of the target could not hold the
+	// status of a FederatedNamespace.
+	if enableTypeDirective.Spec.EnableStatus && apiResource.Kind == ctlutil.NamespaceKind {
+		return nil, errors.New("Status collection is not supported for namespaces")
+	}
+
 	typeConfig := typeConfigForTarget(*apiResource, enableTypeDirective)
 
 	accessor, err := newSchemaAccessor(config, *apiResource)
@@ -214,9 +246,15 @@ func GetResources(config *rest.Config, enableTypeDirective *EnableTypeDirective)
 
 	crd := federatedTypeCRD(typeConfig, accessor, shortNames)
 
+	var statusCRD *apiextv1b1.CustomResourceDefinition
+	if typeConfig.GetEnableStatus() && typeConfig.GetStatusMode() == fedv1a1.StatusModeObject {
+		statusCRD = federatedStatusCRD(typeConfig, accessor)
+	}
+
 	return &typeResources{
 		TypeConfig: typeConfig,
 		CRD:        crd,
+		StatusCRD:  statusCRD,
 	}, nil
 }
 
@@ -234,11 +272,17 @@ func CreateResources(cmdOut io.Writer, config *rest.Config, resources *typeResou
 	if err != nil {
 		return errors.Wrap(err, "Failed to create crd clientset")
 	}
-	_, err = crdClient.CustomResourceDefinitions().Create(resources.CRD)
-	if err != nil {
-		return errors.Wrapf(err, "Error creating CRD %q", resources.CRD.Name)
+	crds := []*apiextv1b1.CustomResourceDefinition{resources.CRD}
+	if resources.StatusCRD != nil {
+		crds = append(crds, resources.StatusCRD)
+	}
+	for _, crd := range crds {
+		_, err = crdClient.CustomResourceDefinitions().Create(crd)
+		if err != nil {
+			return errors.Wrapf(err, "Error creating CRD %q", crd.Name)
+		}
+		write(fmt.Sprintf("customresourcedefinition.apiextensions.k8s.io/%s created\n", crd.Name))
 	}
-	write(fmt.Sprintf("customresourcedefinition.apiextensions.k8s.io/%s created\n", resources.CRD.Name))
 
 	client, err := genericclient.New(config)
 	if err != nil {
@@ -284,6 +328,17 @@ func typeConfigForTarget(apiResource metav1.APIResource, enableTypeDirective *En
 			},
 		},
 	}
+	if spec.StatusSubresource {
+		typeConfig.Spec.StatusMode = fedv1a1.StatusModeSubresource
+	}
+	if spec.EnableStatus {
+		if !spec.StatusSubresource {
+			typeConfig.Spec.Status = &fedv1a1.APIResource{
+				Kind: fmt.Sprintf("Federated%sStatus", kind),
+			}
+		}
+		typeConfig.Spec.EnableStatus = true
+	}
 
 	// Set defaults that would normally be set by the api
 	fedv1a1.SetFederatedTypeConfigDefaults(typeConfig)
@@ -301,7 +356,18 @@ func federatedTypeCRD(typeConfig typeconfig.Interface, accessor schemaAccessor,
 
 	schema := federatedTypeValidationSchema(templateSchema)
 
-	return CrdForAPIResource(typeConfig.GetFederatedType(), schema, shortNames)
+	crd := CrdForAPIResource(typeConfig.GetFederatedType(), schema, shortNames)
+	if typeConfig.GetStatusMode() == fedv1a1.StatusModeSubresource {
+		crd.Spec.Subresources = &apiextv1b1.CustomResourceSubresources{
+			Status: &apiextv1b1.CustomResourceSubresourceStatus{},
+		}
+	}
+	return crd
+}
+
+func federatedStatusCRD(typeConfig typeconfig.Interface, accessor schemaAccessor) *apiextv1b1.CustomResourceDefinition {
+	schema := federatedStatusValidationSchema(accessor.statusSchema())
+	return CrdForAPIResource(*typeConfig.GetStatus(), schema, nil)
 }
 
 func writeObjectsToYAML(objects []pkgruntime.Object, w io.Writer) error {
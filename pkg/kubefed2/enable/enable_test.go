@@ -0,0 +1,209 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package enable
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	apiextv1b1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+)
+
+func TestFederatedStatusCRD(t *testing.T) {
+	apiResource := metav1.APIResource{
+		Name:       "deployments",
+		Group:      "apps",
+		Version:    "v1",
+		Kind:       "Deployment",
+		Namespaced: true,
+	}
+	directive := NewEnableTypeDirective()
+	directive.Spec.EnableStatus = true
+	directive.Spec.StatusSubresource = false
+	typeConfig := typeConfigForTarget(apiResource, directive)
+
+	statusSchema := apiextv1b1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextv1b1.JSONSchemaProps{
+			"replicas": {Type: "integer"},
+		},
+	}
+
+	testCases := map[string]struct {
+		validation           *apiextv1b1.CustomResourceValidation
+		expectedStatusSchema apiextv1b1.JSONSchemaProps
+	}{
+		"status of each cluster is validated by the target status schema": {
+			validation: &apiextv1b1.CustomResourceValidation{
+				OpenAPIV3Schema: &apiextv1b1.JSONSchemaProps{
+					Properties: map[string]apiextv1b1.JSONSchemaProps{
+						"status": statusSchema,
+					},
+				},
+			},
+			expectedStatusSchema: statusSchema,
+		},
+		"status of each cluster is an object if the target has no status schema": {
+			expectedStatusSchema: apiextv1b1.JSONSchemaProps{Type: "object"},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			crd := federatedStatusCRD(typeConfig, &crdSchemaAccessor{validation: testCase.validation})
+
+			if crd.Name != "federateddeploymentstatuses.types.federation.k8s.io" {
+				t.Fatalf("Unexpected name %q", crd.Name)
+			}
+			expectedNames := apiextv1b1.CustomResourceDefinitionNames{
+				Plural: "federateddeploymentstatuses",
+				Kind:   "FederatedDeploymentStatus",
+			}
+			if !reflect.DeepEqual(expectedNames, crd.Spec.Names) {
+				t.Fatalf("Expected names %#v, got %#v", expectedNames, crd.Spec.Names)
+			}
+			if crd.Spec.Group != "types.federation.k8s.io" || crd.Spec.Version != "v1alpha1" {
+				t.Fatalf("Unexpected group version %s/%s", crd.Spec.Group, crd.Spec.Version)
+			}
+			if crd.Spec.Scope != apiextv1b1.NamespaceScoped {
+				t.Fatalf("Expected scope %q, got %q", apiextv1b1.NamespaceScoped, crd.Spec.Scope)
+			}
+			if crd.Spec.Subresources != nil {
+				t.Fatalf("Expected no subresources, got %#v", crd.Spec.Subresources)
+			}
+
+			properties := crd.Spec.Validation.OpenAPIV3Schema.Properties
+			for _, name := range []string{"apiVersion", "kind", "metadata", "clusterStatus", "aggregatedStatus"} {
+				if _, ok := properties[name]; !ok {
+					t.Fatalf("Expected property %q in %v", name, properties)
+				}
+			}
+			clusterStatus := properties["clusterStatus"]
+			if clusterStatus.Type != "array" {
+				t.Fatalf("Expected clusterStatus to be an array, got %q", clusterStatus.Type)
+			}
+			clusterProperties := clusterStatus.Items.Schema.Properties
+			for _, name := range []string{"clusterName", "state", "lastUpdateTime"} {
+				if _, ok := clusterProperties[name]; !ok {
+					t.Fatalf("Expected cluster status property %q in %v", name, clusterProperties)
+				}
+			}
+			if !reflect.DeepEqual(testCase.expectedStatusSchema, clusterProperties["status"]) {
+				t.Fatalf("Expected status schema %#v, got %#v", testCase.expectedStatusSchema, clusterProperties["status"])
+			}
+		})
+	}
+}
+
+func TestStatusSubresourceIsEnabledByDefault(t *testing.T) {
+	apiResource := metav1.APIResource{
+		Name:       "deployments",
+		Group:      "apps",
+		Version:    "v1",
+		Kind:       "Deployment",
+		Namespaced: true,
+	}
+
+	testCases := map[string]struct {
+		statusSubresource    *bool
+		expectedStatusMode   fedv1a1.StatusMode
+		expectedSubresources *apiextv1b1.CustomResourceSubresources
+		expectedStatusType   bool
+	}{
+		"status subresource is enabled by default": {
+			expectedStatusMode: fedv1a1.StatusModeSubresource,
+			expectedSubresources: &apiextv1b1.CustomResourceSubresources{
+				Status: &apiextv1b1.CustomResourceSubresourceStatus{},
+			},
+		},
+		"status subresource can be disabled": {
+			statusSubresource:  new(bool),
+			expectedStatusMode: fedv1a1.StatusModeObject,
+			expectedStatusType: true,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			directive := NewEnableTypeDirective()
+			directive.Spec.EnableStatus = true
+			if testCase.statusSubresource != nil {
+				directive.Spec.StatusSubresource = *testCase.statusSubresource
+			}
+			typeConfig := typeConfigForTarget(apiResource, directive)
+			if statusMode := typeConfig.GetStatusMode(); statusMode != testCase.expectedStatusMode {
+				t.Fatalf("Expected status mode %q, got %q", testCase.expectedStatusMode, statusMode)
+			}
+			if statusType := typeConfig.GetStatus() != nil; statusType != testCase.expectedStatusType {
+				t.Fatalf("Expected a status type to be %v, got %v", testCase.expectedStatusType, statusType)
+			}
+			crd := federatedTypeCRD(typeConfig, &crdSchemaAccessor{}, nil)
+			if !reflect.DeepEqual(testCase.expectedSubresources, crd.Spec.Subresources) {
+				t.Fatalf("Expected subresources %#v, got %#v", testCase.expectedSubresources, crd.Spec.Subresources)
+			}
+		})
+	}
+}
+
+// newDiscoveryServer returns a server for the discovery of the core
+// api group with the given resources.
+func newDiscoveryServer(t *testing.T, resources []metav1.APIResource) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var obj interface{}
+		switch r.URL.Path {
+		case "/api":
+			obj = &metav1.APIVersions{Versions: []string{"v1"}}
+		case "/apis":
+			obj = &metav1.APIGroupList{}
+		case "/api/v1":
+			obj = &metav1.APIResourceList{GroupVersion: "v1", APIResources: resources}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(obj); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}))
+}
+
+func TestGetResourcesRejectsNamespaceStatus(t *testing.T) {
+	server := newDiscoveryServer(t, []metav1.APIResource{
+		{Name: "namespaces", Kind: "Namespace", Verbs: metav1.Verbs{"get", "list"}},
+	})
+	defer server.Close()
+
+	directive := NewEnableTypeDirective()
+	directive.Name = "namespaces"
+	directive.Spec.EnableStatus = true
+	_, err := GetResources(&rest.Config{Host: server.URL}, directive)
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+	if !strings.Contains(err.Error(), "not supported for namespaces") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
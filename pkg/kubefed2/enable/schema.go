@@ -34,6 +34,7 @@ import (
 
 type schemaAccessor interface {
 	templateSchema() map[string]apiextv1b1.JSONSchemaProps
+	statusSchema() *apiextv1b1.JSONSchemaProps
 }
 
 func newSchemaAccessor(config *rest.Config, apiResource metav1.APIResource) (schemaAccessor, error) {
@@ -48,6 +49,17 @@ func newSchemaAccessor(config *rest.Config, apiResource metav1.APIResource) (sch
 	return newOpenAPISchemaAccessor(config, apiResource)
 }
 
+// TemplateSchema returns the schema of the template of a federated
+// type targeting the given api resource.  It is the same schema used
+// to generate the validation of the federated type's CRD.
+func TemplateSchema(config *rest.Config, apiResource metav1.APIResource) (map[string]apiextv1b1.JSONSchemaProps, error) {
+	accessor, err := newSchemaAccessor(config, apiResource)
+	if err != nil {
+		return nil, err
+	}
+	return accessor.templateSchema(), nil
+}
+
 type crdSchemaAccessor struct {
 	validation *apiextv1b1.CustomResourceValidation
 }
@@ -80,6 +92,17 @@ func (a *crdSchemaAccessor) templateSchema() map[string]apiextv1b1.JSONSchemaPro
 	return nil
 }
 
+func (a *crdSchemaAccessor) statusSchema() *apiextv1b1.JSONSchemaProps {
+	if a.validation == nil || a.validation.OpenAPIV3Schema == nil {
+		return nil
+	}
+	statusSchema, ok := a.validation.OpenAPIV3Schema.Properties["status"]
+	if !ok {
+		return nil
+	}
+	return &statusSchema
+}
+
 type openAPISchemaAccessor struct {
 	targetResource proto.Schema
 }
@@ -119,6 +142,34 @@ func (a *openAPISchemaAccessor) templateSchema() map[string]apiextv1b1.JSONSchem
 	return templateSchema.Properties
 }
 
+func (a *openAPISchemaAccessor) statusSchema() *apiextv1b1.JSONSchemaProps {
+	var kind *proto.Kind
+	switch schema := a.targetResource.(type) {
+	case *proto.Kind:
+		kind = schema
+	case proto.Reference:
+		kind, _ = schema.SubSchema().(*proto.Kind)
+	}
+	if kind == nil {
+		return nil
+	}
+	fieldSchema, ok := kind.Fields["status"]
+	if !ok {
+		return nil
+	}
+
+	var statusSchema *apiextv1b1.JSONSchemaProps
+	visitor := &jsonSchemaVistor{
+		includeStatus: true,
+		collect: func(schema apiextv1b1.JSONSchemaProps) {
+			statusSchema = &schema
+		},
+	}
+	fieldSchema.Accept(visitor)
+
+	return statusSchema
+}
+
 // jsonSchemaVistor converts proto.Schema resources into json schema.
 // A local visitor (and associated callback) is intended to be created
 // whenever a function needs to recurse.
@@ -127,6 +178,9 @@ func (a *openAPISchemaAccessor) templateSchema() map[string]apiextv1b1.JSONSchem
 // provides more detail as per https://github.com/ant31/crd-validation
 type jsonSchemaVistor struct {
 	collect func(schema apiextv1b1.JSONSchemaProps)
+	// Whether fields named status are included.  They are omitted
+	// from the schema of a template.
+	includeStatus bool
 }
 
 func (v *jsonSchemaVistor) VisitArray(a *proto.Array) {
@@ -135,6 +189,7 @@ func (v *jsonSchemaVistor) VisitArray(a *proto.Array) {
 		Items: &apiextv1b1.JSONSchemaPropsOrArray{},
 	}
 	localVisitor := &jsonSchemaVistor{
+		includeStatus: v.includeStatus,
 		collect: func(schema apiextv1b1.JSONSchemaProps) {
 			arraySchema.Items.Schema = &schema
 		},
@@ -151,6 +206,7 @@ func (v *jsonSchemaVistor) VisitMap(m *proto.Map) {
 		},
 	}
 	localVisitor := &jsonSchemaVistor{
+		includeStatus: v.includeStatus,
 		collect: func(schema apiextv1b1.JSONSchemaProps) {
 			mapSchema.AdditionalProperties.Schema = &schema
 		},
@@ -172,10 +228,11 @@ func (v *jsonSchemaVistor) VisitKind(k *proto.Kind) {
 	}
 	for key, fieldSchema := range k.Fields {
 		// Status cannot be defined for a template
-		if key == "status" {
+		if key == "status" && !v.includeStatus {
 			continue
 		}
 		localVisitor := &jsonSchemaVistor{
+			includeStatus: v.includeStatus,
 			collect: func(schema apiextv1b1.JSONSchemaProps) {
 				kindSchema.Properties[key] = schema
 			},
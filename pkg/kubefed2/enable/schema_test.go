@@ -0,0 +1,179 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package enable
+
+import (
+	"reflect"
+	"testing"
+
+	apiextv1b1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/kube-openapi/pkg/util/proto"
+)
+
+func TestCRDSchemaAccessorStatusSchema(t *testing.T) {
+	statusSchema := apiextv1b1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextv1b1.JSONSchemaProps{
+			"replicas": {Type: "integer"},
+		},
+	}
+
+	testCases := map[string]struct {
+		validation     *apiextv1b1.CustomResourceValidation
+		expectedSchema *apiextv1b1.JSONSchemaProps
+	}{
+		"crd without validation": {},
+		"crd without an openapi schema": {
+			validation: &apiextv1b1.CustomResourceValidation{},
+		},
+		"crd without a status schema": {
+			validation: &apiextv1b1.CustomResourceValidation{
+				OpenAPIV3Schema: &apiextv1b1.JSONSchemaProps{
+					Properties: map[string]apiextv1b1.JSONSchemaProps{
+						"spec": {Type: "object"},
+					},
+				},
+			},
+		},
+		"crd with a status schema": {
+			validation: &apiextv1b1.CustomResourceValidation{
+				OpenAPIV3Schema: &apiextv1b1.JSONSchemaProps{
+					Properties: map[string]apiextv1b1.JSONSchemaProps{
+						"spec":   {Type: "object"},
+						"status": statusSchema,
+					},
+				},
+			},
+			expectedSchema: &statusSchema,
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			accessor := &crdSchemaAccessor{validation: testCase.validation}
+			schema := accessor.statusSchema()
+			if !reflect.DeepEqual(testCase.expectedSchema, schema) {
+				t.Fatalf("Expected status schema %#v, got %#v", testCase.expectedSchema, schema)
+			}
+		})
+	}
+}
+
+// testReference is an openapi reference to the given schema.
+type testReference struct {
+	proto.BaseSchema
+	schema proto.Schema
+}
+
+func (r *testReference) Accept(v proto.SchemaVisitor) {
+	v.VisitReference(r)
+}
+
+func (r *testReference) GetName() string {
+	return r.schema.GetName()
+}
+
+func (r *testReference) Reference() string {
+	return "io.k8s.api.apps.v1.Deployment"
+}
+
+func (r *testReference) SubSchema() proto.Schema {
+	return r.schema
+}
+
+func TestOpenAPISchemaAccessorStatusSchema(t *testing.T) {
+	withStatus := &proto.Kind{
+		Fields: map[string]proto.Schema{
+			"spec": &proto.Kind{
+				Fields: map[string]proto.Schema{
+					"replicas": &proto.Primitive{Type: "integer", Format: "int32"},
+				},
+			},
+			"status": &proto.Kind{
+				RequiredFields: []string{"replicas"},
+				Fields: map[string]proto.Schema{
+					"replicas": &proto.Primitive{Type: "integer", Format: "int32"},
+					"conditions": &proto.Array{
+						SubType: &proto.Kind{
+							Fields: map[string]proto.Schema{
+								"type": &proto.Primitive{Type: "string"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	withoutStatus := &proto.Kind{
+		Fields: map[string]proto.Schema{
+			"data": &proto.Map{
+				SubType: &proto.Primitive{Type: "string"},
+			},
+		},
+	}
+	expectedSchema := &apiextv1b1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"replicas"},
+		Properties: map[string]apiextv1b1.JSONSchemaProps{
+			"replicas": {Type: "integer", Format: "int32"},
+			"conditions": {
+				Type: "array",
+				Items: &apiextv1b1.JSONSchemaPropsOrArray{
+					Schema: &apiextv1b1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]apiextv1b1.JSONSchemaProps{
+							"type": {Type: "string"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		targetResource proto.Schema
+		expectedSchema *apiextv1b1.JSONSchemaProps
+	}{
+		"kind with a status": {
+			targetResource: withStatus,
+			expectedSchema: expectedSchema,
+		},
+		"reference to a kind with a status": {
+			targetResource: &testReference{schema: withStatus},
+			expectedSchema: expectedSchema,
+		},
+		"kind without a status": {
+			targetResource: withoutStatus,
+		},
+		"reference to a kind without a status": {
+			targetResource: &testReference{schema: withoutStatus},
+		},
+		"schema that is not a kind": {
+			targetResource: &proto.Primitive{Type: "string"},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			accessor := &openAPISchemaAccessor{targetResource: testCase.targetResource}
+			schema := accessor.statusSchema()
+			if !reflect.DeepEqual(testCase.expectedSchema, schema) {
+				t.Fatalf("Expected status schema %#v, got %#v", testCase.expectedSchema, schema)
+			}
+		})
+	}
+}
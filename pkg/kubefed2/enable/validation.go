@@ -24,6 +24,41 @@ func federatedTypeValidationSchema(templateSchema map[string]v1beta1.JSONSchemaP
 	schema := ValidationSchema(v1beta1.JSONSchemaProps{
 		Type: "object",
 		Properties: map[string]v1beta1.JSONSchemaProps{
+			"autoRollback": {
+				Type: "object",
+				Properties: map[string]v1beta1.JSONSchemaProps{
+					"failureThresholdPercent": {
+						Type:    "integer",
+						Minimum: float64Ptr(1),
+						Maximum: float64Ptr(100),
+					},
+				},
+			},
+			// A target resource is only created in a cluster
+			// once its dependencies exist in the cluster.
+			"dependsOn": {
+				Type: "array",
+				Items: &v1beta1.JSONSchemaPropsOrArray{
+					Schema: &v1beta1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]v1beta1.JSONSchemaProps{
+							"apiVersion": {
+								Type: "string",
+							},
+							"kind": {
+								Type: "string",
+							},
+							"name": {
+								Type: "string",
+							},
+						},
+						Required: []string{
+							"kind",
+							"name",
+						},
+					},
+				},
+			},
 			"placement": {
 				Type: "object",
 				Properties: map[string]v1beta1.JSONSchemaProps{
@@ -38,43 +73,54 @@ func federatedTypeValidationSchema(templateSchema map[string]v1beta1.JSONSchemaP
 							},
 						},
 					},
-					"clusterSelector": {
-						Type: "object",
-						Properties: map[string]v1beta1.JSONSchemaProps{
-							"matchExpressions": {
-								Type: "array",
-								Items: &v1beta1.JSONSchemaPropsOrArray{
-									Schema: &v1beta1.JSONSchemaProps{
-										Type: "object",
-										Properties: map[string]v1beta1.JSONSchemaProps{
-											"key": {
-												Type: "string",
-											},
-											"operator": {
-												Type: "string",
-											},
-											"values": {
-												Type: "array",
-												Items: &v1beta1.JSONSchemaPropsOrArray{
-													Schema: &v1beta1.JSONSchemaProps{
-														Type: "string",
-													},
-												},
-											},
-										},
-										Required: []string{
-											"key",
-											"operator",
+					"clusterSelector": labelSelectorSchema(),
+					// maxClusters, spreadConstraints and
+					// clusterPreferences constrain the selection
+					// to a subset of the clusters matching
+					// clusterNames or clusterSelector.
+					"maxClusters": {
+						Type:    "integer",
+						Minimum: float64Ptr(0),
+					},
+					"spreadConstraints": {
+						Type: "array",
+						Items: &v1beta1.JSONSchemaPropsOrArray{
+							Schema: &v1beta1.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]v1beta1.JSONSchemaProps{
+									"topologyKey": {
+										Type: "string",
+										Enum: []v1beta1.JSON{
+											{Raw: []byte(`"region"`)},
+											{Raw: []byte(`"zone"`)},
 										},
 									},
+									"minDomains": {
+										Type:    "integer",
+										Minimum: float64Ptr(1),
+									},
+								},
+								Required: []string{
+									"topologyKey",
+									"minDomains",
 								},
 							},
-							"matchLabels": {
+						},
+					},
+					"clusterPreferences": {
+						Type: "array",
+						Items: &v1beta1.JSONSchemaPropsOrArray{
+							Schema: &v1beta1.JSONSchemaProps{
 								Type: "object",
-								AdditionalProperties: &v1beta1.JSONSchemaPropsOrBool{
-									Schema: &v1beta1.JSONSchemaProps{
-										Type: "string",
+								Properties: map[string]v1beta1.JSONSchemaProps{
+									"weight": {
+										Type: "integer",
 									},
+									"clusterSelector": labelSelectorSchema(),
+								},
+								Required: []string{
+									"weight",
+									"clusterSelector",
 								},
 							},
 						},
@@ -87,15 +133,30 @@ func federatedTypeValidationSchema(templateSchema map[string]v1beta1.JSONSchemaP
 					Schema: &v1beta1.JSONSchemaProps{
 						Type: "object",
 						Properties: map[string]v1beta1.JSONSchemaProps{
+							// Exactly one of clusterName or
+							// clusterSelector must be provided.
 							"clusterName": {
 								Type: "string",
 							},
+							"clusterSelector": labelSelectorSchema(),
 							"clusterOverrides": {
 								Type: "array",
 								Items: &v1beta1.JSONSchemaPropsOrArray{
 									Schema: &v1beta1.JSONSchemaProps{
 										Type: "object",
 										Properties: map[string]v1beta1.JSONSchemaProps{
+											// If op is provided, path is a JSON
+											// Pointer and the override is applied
+											// as an RFC 6902 operation.
+											// Otherwise path is a dotted path.
+											"op": {
+												Type: "string",
+												Enum: []v1beta1.JSON{
+													{Raw: []byte(`"add"`)},
+													{Raw: []byte(`"remove"`)},
+													{Raw: []byte(`"replace"`)},
+												},
+											},
 											"path": {
 												Type: "string",
 											},
@@ -126,6 +187,48 @@ func federatedTypeValidationSchema(templateSchema map[string]v1beta1.JSONSchemaP
 									},
 								},
 							},
+							"strategicMergePatch": {
+								Type: "object",
+							},
+						},
+					},
+				},
+			},
+			"removalPolicy": {
+				Type: "string",
+				Enum: []v1beta1.JSON{
+					{Raw: []byte(`"Delete"`)},
+					{Raw: []byte(`"Orphan"`)},
+					{Raw: []byte(`"Retain"`)},
+				},
+			},
+			"rolloutStrategy": {
+				Type: "object",
+				Properties: map[string]v1beta1.JSONSchemaProps{
+					"maxUnavailableClusters": {
+						Type:    "integer",
+						Minimum: float64Ptr(1),
+					},
+					"pauseSeconds": {
+						Type:    "integer",
+						Minimum: float64Ptr(0),
+					},
+					"progressDeadlineSeconds": {
+						Type:    "integer",
+						Minimum: float64Ptr(1),
+					},
+					"waves": {
+						Type: "array",
+						Items: &v1beta1.JSONSchemaPropsOrArray{
+							Schema: &v1beta1.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]v1beta1.JSONSchemaProps{
+									"clusterSelector": labelSelectorSchema(),
+								},
+								Required: []string{
+									"clusterSelector",
+								},
+							},
 						},
 					},
 				},
@@ -141,6 +244,54 @@ func federatedTypeValidationSchema(templateSchema map[string]v1beta1.JSONSchemaP
 	return schema
 }
 
+func labelSelectorSchema() v1beta1.JSONSchemaProps {
+	return v1beta1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]v1beta1.JSONSchemaProps{
+			"matchExpressions": {
+				Type: "array",
+				Items: &v1beta1.JSONSchemaPropsOrArray{
+					Schema: &v1beta1.JSONSchemaProps{
+						Type: "object",
+						Properties: map[string]v1beta1.JSONSchemaProps{
+							"key": {
+								Type: "string",
+							},
+							"operator": {
+								Type: "string",
+							},
+							"values": {
+								Type: "array",
+								Items: &v1beta1.JSONSchemaPropsOrArray{
+									Schema: &v1beta1.JSONSchemaProps{
+										Type: "string",
+									},
+								},
+							},
+						},
+						Required: []string{
+							"key",
+							"operator",
+						},
+					},
+				},
+			},
+			"matchLabels": {
+				Type: "object",
+				AdditionalProperties: &v1beta1.JSONSchemaPropsOrBool{
+					Schema: &v1beta1.JSONSchemaProps{
+						Type: "string",
+					},
+				},
+			},
+		},
+	}
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
 func ValidationSchema(specProps v1beta1.JSONSchemaProps) *v1beta1.CustomResourceValidation {
 	return &v1beta1.CustomResourceValidation{
 		OpenAPIV3Schema: &v1beta1.JSONSchemaProps{
@@ -160,3 +311,102 @@ func ValidationSchema(specProps v1beta1.JSONSchemaProps) *v1beta1.CustomResource
 		},
 	}
 }
+
+// federatedStatusValidationSchema returns the validation of a status
+// type that records the status of the target type in each member
+// cluster.  The status of each cluster is validated with the given
+// schema of the target type's status, if any.  Fields are never
+// required since only some of the fields of the status may be
+// collected.
+func federatedStatusValidationSchema(statusSchema *v1beta1.JSONSchemaProps) *v1beta1.CustomResourceValidation {
+	clusterStatusSchema := v1beta1.JSONSchemaProps{
+		Type: "object",
+	}
+	if statusSchema != nil {
+		clusterStatusSchema = *statusSchema.DeepCopy()
+		removeRequired(&clusterStatusSchema)
+	}
+	return &v1beta1.CustomResourceValidation{
+		OpenAPIV3Schema: &v1beta1.JSONSchemaProps{
+			Properties: map[string]v1beta1.JSONSchemaProps{
+				"apiVersion": {
+					Type: "string",
+				},
+				"kind": {
+					Type: "string",
+				},
+				"metadata": {
+					Type: "object",
+				},
+				"clusterStatus": {
+					Type: "array",
+					Items: &v1beta1.JSONSchemaPropsOrArray{
+						Schema: &v1beta1.JSONSchemaProps{
+							Type: "object",
+							Properties: map[string]v1beta1.JSONSchemaProps{
+								"clusterName": {
+									Type: "string",
+								},
+								"state": {
+									Type: "string",
+								},
+								"status": clusterStatusSchema,
+								"lastUpdateTime": {
+									Type:   "string",
+									Format: "date-time",
+								},
+							},
+						},
+					},
+				},
+				// The schema of the aggregated status depends on
+				// the status aggregator of the type.
+				"aggregatedStatus": {
+					Type: "object",
+				},
+			},
+		},
+	}
+}
+
+// removeRequired removes the required fields, and the fields required
+// by the presence of other fields, from the given schema and all of
+// its nested schemas.
+func removeRequired(schema *v1beta1.JSONSchemaProps) {
+	schema.Required = nil
+	schema.Dependencies = nil
+	removeRequiredFromMap(schema.Properties)
+	removeRequiredFromMap(schema.PatternProperties)
+	removeRequiredFromMap(schema.Definitions)
+	removeRequiredFromSlice(schema.AllOf)
+	removeRequiredFromSlice(schema.OneOf)
+	removeRequiredFromSlice(schema.AnyOf)
+	if schema.Not != nil {
+		removeRequired(schema.Not)
+	}
+	if schema.Items != nil {
+		if schema.Items.Schema != nil {
+			removeRequired(schema.Items.Schema)
+		}
+		removeRequiredFromSlice(schema.Items.JSONSchemas)
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		removeRequired(schema.AdditionalProperties.Schema)
+	}
+	if schema.AdditionalItems != nil && schema.AdditionalItems.Schema != nil {
+		removeRequired(schema.AdditionalItems.Schema)
+	}
+}
+
+func removeRequiredFromMap(schemas map[string]v1beta1.JSONSchemaProps) {
+	for name, schema := range schemas {
+		removeRequired(&schema)
+		schemas[name] = schema
+	}
+}
+
+func removeRequiredFromSlice(schemas []v1beta1.JSONSchemaProps) {
+	for i := range schemas {
+		removeRequired(&schemas[i])
+	}
+}
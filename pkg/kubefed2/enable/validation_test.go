@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package enable
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+)
+
+func TestFederatedStatusValidationSchemaRemovesRequired(t *testing.T) {
+	conditionSchema := v1beta1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"type", "status"},
+		Properties: map[string]v1beta1.JSONSchemaProps{
+			"type":   {Type: "string"},
+			"status": {Type: "string"},
+		},
+	}
+	statusSchema := &v1beta1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"replicas"},
+		Properties: map[string]v1beta1.JSONSchemaProps{
+			"replicas": {Type: "integer"},
+			"conditions": {
+				Type:  "array",
+				Items: &v1beta1.JSONSchemaPropsOrArray{Schema: &conditionSchema},
+			},
+			"selectors": {
+				Type: "object",
+				AdditionalProperties: &v1beta1.JSONSchemaPropsOrBool{
+					Allows: true,
+					Schema: &conditionSchema,
+				},
+			},
+		},
+		Dependencies: v1beta1.JSONSchemaDependencies{
+			"replicas": {Property: []string{"conditions"}},
+		},
+	}
+	original := statusSchema.DeepCopy()
+
+	validation := federatedStatusValidationSchema(statusSchema)
+
+	clusterStatus := validation.OpenAPIV3Schema.Properties["clusterStatus"].Items.Schema.Properties["status"]
+	expectedConditionSchema := v1beta1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]v1beta1.JSONSchemaProps{
+			"type":   {Type: "string"},
+			"status": {Type: "string"},
+		},
+	}
+	expected := v1beta1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]v1beta1.JSONSchemaProps{
+			"replicas": {Type: "integer"},
+			"conditions": {
+				Type:  "array",
+				Items: &v1beta1.JSONSchemaPropsOrArray{Schema: &expectedConditionSchema},
+			},
+			"selectors": {
+				Type: "object",
+				AdditionalProperties: &v1beta1.JSONSchemaPropsOrBool{
+					Allows: true,
+					Schema: &expectedConditionSchema,
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(expected, clusterStatus) {
+		t.Fatalf("Expected status schema %#v, got %#v", expected, clusterStatus)
+	}
+	if !reflect.DeepEqual(original, statusSchema) {
+		t.Fatalf("Expected the schema of the target type to be unchanged")
+	}
+}
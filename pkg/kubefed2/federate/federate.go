@@ -197,11 +197,6 @@ func createFedResource(hostConfig *rest.Config, typeConfig *fedv1a1.FederatedTyp
 		return nil, errors.Wrapf(err, "Error creating client for %s", fedAPIResource.Kind)
 	}
 
-	targetKind := typeConfig.GetTarget().Kind
-	if targetKind == ctlutil.ServiceAccountKind {
-		unstructured.RemoveNestedField(template.Object, ctlutil.SecretsField)
-	}
-
 	qualifiedName := ctlutil.NewQualifiedName(template)
 	resourceNamespace := ""
 	if typeConfig.GetTarget().Kind == ctlutil.NamespaceKind {
@@ -211,7 +206,7 @@ func createFedResource(hostConfig *rest.Config, typeConfig *fedv1a1.FederatedTyp
 	}
 	fedResource := &unstructured.Unstructured{}
 	SetBasicMetaFields(fedResource, fedAPIResource, qualifiedName.Name, resourceNamespace, "")
-	RemoveUnwantedFields(template)
+	ctlutil.RemoveUnwantedFields(template.Object, typeConfig.GetTarget().Kind)
 
 	fedKind := fedAPIResource.Kind
 	qualifiedFedName := ctlutil.NewQualifiedName(fedResource)
@@ -22,18 +22,6 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-var systemMetadataFields = []string{"selfLink", "uid", "resourceVersion", "generation", "creationTimestamp", "deletionTimestamp", "deletionGracePeriodSeconds"}
-
-func RemoveUnwantedFields(resource *unstructured.Unstructured) {
-	for _, field := range systemMetadataFields {
-		unstructured.RemoveNestedField(resource.Object, "metadata", field)
-		// For resources with pod template subresource (jobs, deployments, replicasets)
-		unstructured.RemoveNestedField(resource.Object, "spec", "template", "metadata", field)
-	}
-	unstructured.RemoveNestedField(resource.Object, "metadata", "name")
-	unstructured.RemoveNestedField(resource.Object, "metadata", "namespace")
-}
-
 func SetBasicMetaFields(resource *unstructured.Unstructured, apiResource metav1.APIResource, name, namespace, generateName string) {
 	resource.SetKind(apiResource.Kind)
 	gv := schema.GroupVersion{Group: apiResource.Group, Version: apiResource.Version}
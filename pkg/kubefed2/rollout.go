@@ -0,0 +1,278 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubefed2
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubeclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+	genericclient "github.com/kubernetes-sigs/federation-v2/pkg/client/generic"
+	ctlutil "github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+	"github.com/kubernetes-sigs/federation-v2/pkg/kubefed2/options"
+	"github.com/kubernetes-sigs/federation-v2/pkg/kubefed2/util"
+)
+
+var (
+	rollout_long = `
+		Manages the revisions of the template and overrides of a
+		federated resource.  Revisions are only recorded for types
+		whose FederatedTypeConfig specifies a revisionHistoryLimit.
+
+		Current context is assumed to be a Kubernetes cluster hosting
+		the federation control plane. Please use the
+		--host-cluster-context flag otherwise.`
+
+	rollout_history_example = `
+		# List the revisions of the FederatedDeployment "my-dep" in namespace "my-ns"
+		kubefed2 rollout history deployments.apps my-dep -n my-ns`
+
+	rollout_undo_example = `
+		# Restore the previous revision of the FederatedDeployment "my-dep" in namespace "my-ns"
+		kubefed2 rollout undo deployments.apps my-dep -n my-ns
+
+		# Restore revision 3 of the FederatedDeployment "my-dep" in namespace "my-ns"
+		kubefed2 rollout undo deployments.apps my-dep -n my-ns --to-revision=3`
+)
+
+type rolloutOptions struct {
+	options.SubcommandOptions
+	typeName          string
+	resourceName      string
+	resourceNamespace string
+	toRevision        int64
+}
+
+// Bind adds the rollout specific arguments to the flagset passed in
+// as an argument.
+func (o *rolloutOptions) Bind(flags *pflag.FlagSet) {
+	flags.StringVarP(&o.resourceNamespace, "namespace", "n", "default", "The namespace of the federated resource.")
+}
+
+// Complete ensures that options are valid.
+func (o *rolloutOptions) Complete(args []string) error {
+	if len(args) == 0 {
+		return errors.New("FEDERATED-TYPE-NAME is required")
+	}
+	o.typeName = args[0]
+
+	if len(args) == 1 {
+		return errors.New("RESOURCE-NAME is required")
+	}
+	o.resourceName = args[1]
+
+	return nil
+}
+
+// NewCmdRollout defines the `rollout` command and its `history` and
+// `undo` subcommands.
+func NewCmdRollout(cmdOut io.Writer, config util.FedConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollout",
+		Short: "Manages the revisions of a federated resource",
+		Long:  rollout_long,
+		Run:   runHelp,
+	}
+	cmd.AddCommand(newCmdRolloutSubcommand(cmdOut, config, "history", "Lists the revisions of a federated resource", rollout_history_example,
+		func(o *rolloutOptions, flags *pflag.FlagSet) {},
+		(*rolloutOptions).RunHistory))
+	cmd.AddCommand(newCmdRolloutSubcommand(cmdOut, config, "undo", "Restores a previous revision of a federated resource", rollout_undo_example,
+		func(o *rolloutOptions, flags *pflag.FlagSet) {
+			flags.Int64Var(&o.toRevision, "to-revision", 0, "The revision to restore.  Defaults to the revision preceding the current one.")
+		},
+		(*rolloutOptions).RunUndo))
+	return cmd
+}
+
+func newCmdRolloutSubcommand(cmdOut io.Writer, config util.FedConfig, name, short, example string,
+	bind func(*rolloutOptions, *pflag.FlagSet), run func(*rolloutOptions, io.Writer, util.FedConfig) error) *cobra.Command {
+
+	opts := &rolloutOptions{}
+
+	cmd := &cobra.Command{
+		Use:     fmt.Sprintf("%s FEDERATED-TYPE-NAME RESOURCE-NAME", name),
+		Short:   short,
+		Long:    rollout_long,
+		Example: example,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := opts.Complete(args)
+			if err != nil {
+				glog.Fatalf("error: %v", err)
+			}
+
+			err = run(opts, cmdOut, config)
+			if err != nil {
+				glog.Fatalf("error: %v", err)
+			}
+		},
+	}
+
+	flags := cmd.Flags()
+	opts.CommonBind(flags)
+	opts.Bind(flags)
+	bind(opts, flags)
+
+	return cmd
+}
+
+// RunHistory is the implementation of the `rollout history` command.
+func (o *rolloutOptions) RunHistory(cmdOut io.Writer, config util.FedConfig) error {
+	hostConfig, err := config.HostConfig(o.HostClusterContext, o.Kubeconfig)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get host cluster config")
+	}
+	fedObject, _, err := o.getFederatedResource(hostConfig)
+	if err != nil {
+		return err
+	}
+	client, err := kubeclient.NewForConfig(hostConfig)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get kubernetes clientset")
+	}
+	revisions, err := ctlutil.ListRevisions(client, ctlutil.RevisionNamespace(fedObject, o.FederationNamespace), fedObject)
+	if err != nil {
+		return err
+	}
+	currentName, err := currentRevisionName(fedObject)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmdOut, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "REVISION\tNAME\tCURRENT")
+	for _, revision := range revisions {
+		current := ""
+		if revision.Name == currentName {
+			current = "*"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", revision.Revision, revision.Name, current)
+	}
+	return w.Flush()
+}
+
+// RunUndo is the implementation of the `rollout undo` command.
+func (o *rolloutOptions) RunUndo(cmdOut io.Writer, config util.FedConfig) error {
+	hostConfig, err := config.HostConfig(o.HostClusterContext, o.Kubeconfig)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get host cluster config")
+	}
+	fedObject, fedClient, err := o.getFederatedResource(hostConfig)
+	if err != nil {
+		return err
+	}
+	client, err := kubeclient.NewForConfig(hostConfig)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get kubernetes clientset")
+	}
+	revisions, err := ctlutil.ListRevisions(client, ctlutil.RevisionNamespace(fedObject, o.FederationNamespace), fedObject)
+	if err != nil {
+		return err
+	}
+	currentName, err := currentRevisionName(fedObject)
+	if err != nil {
+		return err
+	}
+
+	var revision *appsv1.ControllerRevision
+	if o.toRevision == 0 {
+		revision = ctlutil.PreviousRevision(revisions, currentName)
+		if revision == nil {
+			return errors.Errorf("No previous revision of %s %q was found", fedObject.GetKind(), fedObject.GetName())
+		}
+	} else {
+		for _, r := range revisions {
+			if r.Revision == o.toRevision {
+				revision = r
+			}
+		}
+		if revision == nil {
+			return errors.Errorf("Revision %d of %s %q was not found", o.toRevision, fedObject.GetKind(), fedObject.GetName())
+		}
+	}
+	if revision.Name == currentName {
+		fmt.Fprintf(cmdOut, "%s %q is already at revision %d\n", fedObject.GetKind(), fedObject.GetName(), revision.Revision)
+		return nil
+	}
+
+	err = ctlutil.ApplyRevision(fedObject, revision)
+	if err != nil {
+		return err
+	}
+	if o.DryRun {
+		return nil
+	}
+	_, err = fedClient.Resources(fedObject.GetNamespace()).Update(fedObject, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "Error updating %s %q", fedObject.GetKind(), fedObject.GetName())
+	}
+	fmt.Fprintf(cmdOut, "%s %q rolled back to revision %d\n", fedObject.GetKind(), fedObject.GetName(), revision.Revision)
+	return nil
+}
+
+// getFederatedResource retrieves the federated resource identified by
+// the options along with a client for its type.
+func (o *rolloutOptions) getFederatedResource(hostConfig *rest.Config) (*unstructured.Unstructured, ctlutil.ResourceClient, error) {
+	client, err := genericclient.New(hostConfig)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Failed to get federation client")
+	}
+	typeConfigName := ctlutil.QualifiedName{
+		Namespace: o.FederationNamespace,
+		Name:      o.typeName,
+	}
+	typeConfig := &fedv1a1.FederatedTypeConfig{}
+	err = client.Get(context.TODO(), typeConfig, typeConfigName.Namespace, typeConfigName.Name)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Error retrieving FederatedTypeConfig %q", typeConfigName)
+	}
+
+	fedAPIResource := typeConfig.GetFederatedType()
+	fedClient, err := ctlutil.NewResourceClient(hostConfig, &fedAPIResource)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Error creating client for %s", fedAPIResource.Kind)
+	}
+	namespace := o.resourceNamespace
+	if !typeConfig.GetFederatedNamespaced() {
+		namespace = ""
+	}
+	fedObject, err := fedClient.Resources(namespace).Get(o.resourceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Error retrieving %s %q", fedAPIResource.Kind, o.resourceName)
+	}
+	return fedObject, fedClient, nil
+}
+
+func currentRevisionName(fedObject *unstructured.Unstructured) (string, error) {
+	data, err := ctlutil.RevisionData(fedObject)
+	if err != nil {
+		return "", err
+	}
+	return ctlutil.RevisionName(fedObject, data), nil
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/pkg/errors"
 
+	"github.com/kubernetes-sigs/federation-v2/pkg/apis/core/typeconfig"
 	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
 	genericclient "github.com/kubernetes-sigs/federation-v2/pkg/client/generic"
 	controllerutil "github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
@@ -203,6 +204,16 @@ func UnjoinCluster(hostConfig, clusterConfig *rest.Config, federationNamespace,
 	if clusterClientset != nil {
 		deletionSucceeded = deleteRBACResources(clusterClientset, federationNamespace, unjoiningClusterName, hostClusterName, dryRun)
 
+		// Resources are orphaned once the access of the sync
+		// controller to the cluster has been revoked so that the
+		// controller cannot label them as managed again before the
+		// federated cluster is deleted.
+		err = orphanRetainedResources(hostConfig, clusterConfig, client, federationNamespace, unjoiningClusterName, dryRun)
+		if err != nil {
+			glog.Errorf("Error orphaning resources in unjoin cluster: %v", err)
+			deletionSucceeded = false
+		}
+
 		err = deleteFedNSFromUnjoinCluster(hostClientset, clusterClientset, federationNamespace, unjoiningClusterName, dryRun)
 		if err != nil {
 			glog.Errorf("Error deleting federation namespace from unjoin cluster: %v", err)
@@ -218,6 +229,86 @@ func UnjoinCluster(hostConfig, clusterConfig *rest.Config, federationNamespace,
 	return nil
 }
 
+// orphanRetainedResources removes the label marking a resource in the
+// unjoining cluster as managed by federation if the removal policy of
+// its federated resource is Orphan or Retain.  A retained resource is
+// also orphaned since the cluster is leaving the federation.
+func orphanRetainedResources(hostConfig, clusterConfig *rest.Config, client genericclient.Client,
+	federationNamespace, unjoiningClusterName string, dryRun bool) error {
+
+	typeConfigList := &fedv1a1.FederatedTypeConfigList{}
+	err := client.List(context.TODO(), typeConfigList, federationNamespace)
+	if err != nil {
+		return errors.Wrapf(err, "Error listing federated type configs in namespace %q", federationNamespace)
+	}
+	for i := range typeConfigList.Items {
+		err := orphanRetainedResourcesOfType(hostConfig, clusterConfig, &typeConfigList.Items[i], unjoiningClusterName, dryRun)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func orphanRetainedResourcesOfType(hostConfig, clusterConfig *rest.Config, typeConfig typeconfig.Interface,
+	unjoiningClusterName string, dryRun bool) error {
+
+	federatedAPIResource := typeConfig.GetFederatedType()
+	fedClient, err := controllerutil.NewResourceClient(hostConfig, &federatedAPIResource)
+	if err != nil {
+		return errors.Wrapf(err, "Error creating client for %s", federatedAPIResource.Kind)
+	}
+	targetAPIResource := typeConfig.GetTarget()
+	targetClient, err := controllerutil.NewResourceClient(clusterConfig, &targetAPIResource)
+	if err != nil {
+		return errors.Wrapf(err, "Error creating client for %s in unjoining cluster %q", targetAPIResource.Kind, unjoiningClusterName)
+	}
+	patchType, patch, err := controllerutil.OrphanPatch()
+	if err != nil {
+		return errors.Wrap(err, "Error creating orphan patch")
+	}
+
+	fedList, err := fedClient.Resources(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "Error listing %s resources", federatedAPIResource.Kind)
+	}
+	for i := range fedList.Items {
+		fedObject := &fedList.Items[i]
+		removalPolicy, err := controllerutil.GetRemovalPolicy(fedObject)
+		if err != nil {
+			return errors.Wrapf(err, "Error retrieving removal policy of %s %q", federatedAPIResource.Kind, controllerutil.NewQualifiedName(fedObject))
+		}
+		if removalPolicy == controllerutil.RemovalPolicyDelete {
+			continue
+		}
+
+		// The target resource shares the name and namespace of its
+		// federated resource.
+		qualifiedName := controllerutil.NewQualifiedName(fedObject)
+		clusterObj, err := targetClient.Resources(qualifiedName.Namespace).Get(qualifiedName.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "Error retrieving %s %q from unjoining cluster %q", targetAPIResource.Kind, qualifiedName, unjoiningClusterName)
+		}
+		if !controllerutil.IsManagedByFederation(clusterObj) {
+			continue
+		}
+
+		glog.V(2).Infof("Orphaning %s %q in unjoining cluster %q", targetAPIResource.Kind, qualifiedName, unjoiningClusterName)
+		if dryRun {
+			continue
+		}
+		_, err = targetClient.Resources(qualifiedName.Namespace).Patch(qualifiedName.Name, patchType, patch, metav1.UpdateOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "Error orphaning %s %q in unjoining cluster %q", targetAPIResource.Kind, qualifiedName, unjoiningClusterName)
+		}
+		glog.V(2).Infof("Orphaned %s %q in unjoining cluster %q", targetAPIResource.Kind, qualifiedName, unjoiningClusterName)
+	}
+	return nil
+}
+
 // removeFromClusterRegistry handles removing the cluster from the cluster registry and
 // reports progress.
 func removeFromClusterRegistry(hostConfig *rest.Config, clusterNamespace, unjoiningClusterName string,
@@ -0,0 +1,163 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubefed2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
+	ctlutil "github.com/kubernetes-sigs/federation-v2/pkg/controller/util"
+)
+
+func writeJSON(t *testing.T, w http.ResponseWriter, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(obj); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestOrphanRetainedResourcesOfType(t *testing.T) {
+	newFedObject := func(name string, removalPolicy ctlutil.RemovalPolicy) map[string]interface{} {
+		spec := map[string]interface{}{}
+		if len(removalPolicy) > 0 {
+			spec[ctlutil.RemovalPolicyField] = string(removalPolicy)
+		}
+		return map[string]interface{}{
+			"apiVersion": "types.federation.k8s.io/v1alpha1",
+			"kind":       "FederatedConfigMap",
+			"metadata":   map[string]interface{}{"namespace": "ns", "name": name},
+			"spec":       spec,
+		}
+	}
+	fedList := map[string]interface{}{
+		"apiVersion": "types.federation.k8s.io/v1alpha1",
+		"kind":       "FederatedConfigMapList",
+		"metadata":   map[string]interface{}{},
+		"items": []interface{}{
+			newFedObject("deleted", ""),
+			newFedObject("orphaned", ctlutil.RemovalPolicyOrphan),
+			newFedObject("retained", ctlutil.RemovalPolicyRetain),
+			newFedObject("unmanaged", ctlutil.RemovalPolicyOrphan),
+			newFedObject("missing", ctlutil.RemovalPolicyRetain),
+		},
+	}
+	hostServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/apis/types.federation.k8s.io/v1alpha1/federatedconfigmaps" {
+			t.Errorf("Unexpected host request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		writeJSON(t, w, fedList)
+	}))
+	defer hostServer.Close()
+
+	var lock sync.Mutex
+	patched := []string{}
+	clusterServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := "/api/v1/namespaces/ns/configmaps/"
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			t.Errorf("Unexpected cluster request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		name := path.Base(r.URL.Path)
+		if name == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			writeJSON(t, w, &metav1.Status{Status: metav1.StatusFailure, Reason: metav1.StatusReasonNotFound, Code: http.StatusNotFound})
+			return
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("ConfigMap")
+		obj.SetNamespace("ns")
+		obj.SetName(name)
+		if name != "unmanaged" {
+			ctlutil.AddManagedByFederationLabel(obj)
+		}
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPatch:
+			lock.Lock()
+			patched = append(patched, name)
+			lock.Unlock()
+		default:
+			t.Errorf("Unexpected cluster request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		writeJSON(t, w, obj.Object)
+	}))
+	defer clusterServer.Close()
+
+	typeConfig := &fedv1a1.FederatedTypeConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "configmaps"},
+		Spec: fedv1a1.FederatedTypeConfigSpec{
+			Namespaced: true,
+			Target: fedv1a1.APIResource{
+				Version:    "v1",
+				Kind:       "ConfigMap",
+				PluralName: "configmaps",
+			},
+			FederatedType: fedv1a1.APIResource{
+				Group:      "types.federation.k8s.io",
+				Version:    "v1alpha1",
+				Kind:       "FederatedConfigMap",
+				PluralName: "federatedconfigmaps",
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		dryRun          bool
+		expectedPatched []string
+	}{
+		"managed resources of orphaned and retained federated resources are orphaned": {
+			expectedPatched: []string{"orphaned", "retained"},
+		},
+		"nothing is orphaned in a dry run": {
+			dryRun:          true,
+			expectedPatched: []string{},
+		},
+	}
+
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			patched = []string{}
+			err := orphanRetainedResourcesOfType(&rest.Config{Host: hostServer.URL}, &rest.Config{Host: clusterServer.URL},
+				typeConfig, "cluster1", testCase.dryRun)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			sort.Strings(patched)
+			if !reflect.DeepEqual(testCase.expectedPatched, patched) {
+				t.Fatalf("Expected %v to be orphaned, got %v", testCase.expectedPatched, patched)
+			}
+		})
+	}
+}
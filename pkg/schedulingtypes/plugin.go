@@ -195,27 +195,27 @@ func setOverrides(obj *unstructured.Unstructured, overridesMap util.OverridesMap
 
 func updateOverridesMap(overridesMap util.OverridesMap, replicasMap map[string]int64) {
 	// Remove replicas override for clusters that are not scheduled
-	for clusterName, clusterOverridesMap := range overridesMap {
+	for clusterName, clusterOverrides := range overridesMap {
 		if _, ok := replicasMap[clusterName]; !ok {
-			delete(clusterOverridesMap, replicasPath)
+			clusterOverrides.RemoveValue(replicasPath)
 		}
 	}
 	// Add/update replicas override for clusters that are scheduled
 	for clusterName, replicas := range replicasMap {
-		clusterOverridesMap, ok := overridesMap[clusterName]
+		clusterOverrides, ok := overridesMap[clusterName]
 		if !ok {
-			clusterOverridesMap = make(util.ClusterOverridesMap)
-			overridesMap[clusterName] = clusterOverridesMap
+			clusterOverrides = &util.ClusterOverrides{}
+			overridesMap[clusterName] = clusterOverrides
 		}
-		clusterOverridesMap[replicasPath] = replicas
+		clusterOverrides.SetValue(replicasPath, replicas)
 	}
 }
 
 func OverrideUpdateNeeded(overridesMap util.OverridesMap, result map[string]int64) bool {
 	resultLen := len(result)
 	checkLen := 0
-	for clusterName, clusterOverridesMap := range overridesMap {
-		for path, rawValue := range clusterOverridesMap {
+	for clusterName, clusterOverrides := range overridesMap {
+		for path, rawValue := range clusterOverrides.Values() {
 			if path != replicasPath {
 				continue
 			}
@@ -210,14 +210,14 @@ func (c *FederatedTypeCrudTester) CheckUpdate(fedObject *unstructured.Unstructur
 		for clusterName := range c.testClusters {
 			clusterOverrides, ok := overrides[clusterName]
 			if !ok {
-				clusterOverrides = make(util.ClusterOverridesMap)
+				clusterOverrides = &util.ClusterOverrides{}
 				overrides[clusterName] = clusterOverrides
 			}
-			_, ok = clusterOverrides[key]
+			_, ok = clusterOverrides.Value(key)
 			if ok {
 				c.tl.Fatalf("An override for %q already exists for cluster %q", key, clusterName)
 			}
-			clusterOverrides[key] = value
+			clusterOverrides.SetValue(key, value)
 		}
 		util.SetOverrides(obj, overrides)
 	})
@@ -390,7 +390,10 @@ func (c *FederatedTypeCrudTester) CheckPropagation(fedObject *unstructured.Unstr
 		c.tl.Fatalf("Error computing template hash for %s %q: %v", federatedKind, qualifiedName, err)
 	}
 
-	overrideVersion, err := sync.GetOverrideHash(fedObject)
+	// Overrides targeting a cluster selector are not used by the
+	// crud tester, so the clusters are not required to compute the
+	// hash.
+	overrideVersion, err := sync.GetOverrideHash(fedObject, nil)
 	if err != nil {
 		c.tl.Fatalf("Error computing override hash for %s %q: %v", federatedKind, qualifiedName, err)
 	}
@@ -419,7 +422,11 @@ func (c *FederatedTypeCrudTester) CheckPropagation(fedObject *unstructured.Unstr
 		c.tl.Logf("Waiting for %s %q %s cluster %q", targetKind, qualifiedName, operation, clusterName)
 
 		if objExpected {
-			err := c.waitForResource(testCluster.Client, qualifiedName, overridesMap[clusterName], func() string {
+			var expectedOverrides util.ClusterOverridesMap
+			if clusterOverrides, ok := overridesMap[clusterName]; ok {
+				expectedOverrides = clusterOverrides.Values()
+			}
+			err := c.waitForResource(testCluster.Client, qualifiedName, expectedOverrides, func() string {
 				version, _ := c.expectedVersion(qualifiedName, templateVersion, overrideVersion, clusterName)
 				return version
 			})
@@ -632,6 +639,11 @@ func (c *FederatedTypeCrudTester) CheckStatusCreated(qualifiedName util.Qualifie
 		return
 	}
 
+	if c.typeConfig.GetStatusMode() == fedv1a1.StatusModeSubresource {
+		c.checkStatusSubresourceCreated(qualifiedName)
+		return
+	}
+
 	statusAPIResource := c.typeConfig.GetStatus()
 	statusKind := statusAPIResource.Kind
 
@@ -650,3 +662,27 @@ func (c *FederatedTypeCrudTester) CheckStatusCreated(qualifiedName util.Qualifie
 		c.tl.Fatalf("Timed out waiting for %s %q", statusKind, qualifiedName)
 	}
 }
+
+// checkStatusSubresourceCreated waits for the status collected from
+// member clusters to be written to the status of the federated
+// resource.
+func (c *FederatedTypeCrudTester) checkStatusSubresourceCreated(qualifiedName util.QualifiedName) {
+	federatedKind := c.typeConfig.GetFederatedType().Kind
+
+	c.tl.Logf("Checking collection of status for %s %q", federatedKind, qualifiedName)
+
+	client := c.resourceClient(c.typeConfig.GetFederatedType())
+	err := wait.PollImmediate(c.waitInterval, wait.ForeverTestTimeout, func() (bool, error) {
+		fedObject, err := client.Resources(qualifiedName.Namespace).Get(qualifiedName.Name, metav1.GetOptions{})
+		if err != nil {
+			c.tl.Errorf("An unexpected error occurred while polling for desired status: %v", err)
+			return false, nil
+		}
+		_, found, err := unstructured.NestedSlice(fedObject.Object, util.StatusField, util.ClusterStatusField)
+		return (err == nil && found), nil
+	})
+
+	if err != nil {
+		c.tl.Fatalf("Timed out waiting for the status of %s %q", federatedKind, qualifiedName)
+	}
+}
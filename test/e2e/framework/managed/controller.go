@@ -20,8 +20,11 @@ import (
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	pkgruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
 
 	"github.com/kubernetes-sigs/federation-v2/pkg/apis/core/typeconfig"
+	fedv1a1 "github.com/kubernetes-sigs/federation-v2/pkg/apis/core/v1alpha1"
 	"github.com/kubernetes-sigs/federation-v2/pkg/controller/dnsendpoint"
 	"github.com/kubernetes-sigs/federation-v2/pkg/controller/federatedcluster"
 	"github.com/kubernetes-sigs/federation-v2/pkg/controller/ingressdns"
@@ -43,7 +46,31 @@ func NewSyncControllerFixture(tl common.TestLogger, controllerConfig *util.Contr
 	f := &ControllerFixture{
 		stopChan: make(chan struct{}),
 	}
-	err := sync.StartFederationSyncController(controllerConfig, f.stopChan, typeConfig, namespacePlacement)
+	// The sync controller resolves the types of dependencies from
+	// the FederatedTypeConfigs cached by the controller manager.
+	typeConfigStore, typeConfigController, err := util.NewGenericInformer(
+		controllerConfig.KubeConfig,
+		controllerConfig.FederationNamespace,
+		&fedv1a1.FederatedTypeConfig{},
+		util.NoResyncPeriod,
+		func(pkgruntime.Object) {},
+	)
+	if err != nil {
+		tl.Fatalf("Error creating FederatedTypeConfig informer: %v", err)
+	}
+	go typeConfigController.Run(f.stopChan)
+	if !cache.WaitForCacheSync(f.stopChan, typeConfigController.HasSynced) {
+		tl.Fatalf("Timed out waiting for the FederatedTypeConfig informer to sync")
+	}
+	var placementPolicyManager *util.PlacementPolicyManager
+	if !controllerConfig.LimitedScope() {
+		placementPolicyManager, err = util.NewPlacementPolicyManager(controllerConfig.KubeConfig)
+		if err != nil {
+			tl.Fatalf("Error creating placement policy manager: %v", err)
+		}
+		placementPolicyManager.Run(f.stopChan)
+	}
+	err = sync.StartFederationSyncController(controllerConfig, f.stopChan, typeConfig, namespacePlacement, typeConfigStore, placementPolicyManager)
 	if err != nil {
 		tl.Fatalf("Error starting sync controller: %v", err)
 	}
@@ -376,6 +376,9 @@ func federateCoreTypes(tl common.TestLogger, config *rest.Config, namespace stri
 			tl.Fatalf("Error creating resources for EnableTypeDirective %q: %v", enableTypeDirective.Name, err)
 		}
 		crds = append(crds, resources.CRD)
+		if resources.StatusCRD != nil {
+			crds = append(crds, resources.StatusCRD)
+		}
 	}
 	return crds
 }
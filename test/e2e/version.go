@@ -153,6 +153,12 @@ func (r *testVersionedResource) TemplateVersion() (string, error) {
 func (r *testVersionedResource) OverrideVersion() (string, error) {
 	return r.overrideVersion, nil
 }
+func (r *testVersionedResource) ObservedGeneration() int64 {
+	return r.object.GetGeneration()
+}
+func (r *testVersionedResource) RenderedVersion(clusterName string) (string, error) {
+	return "", nil
+}
 
 func newTestVersionAdapter(client genericclient.Client, kubeClient kubeclientset.Interface, namespaced bool) testVersionAdapter {
 	adapter := version.NewVersionAdapter(namespaced)
@@ -287,7 +293,7 @@ var _ = Describe("VersionManager", func() {
 				expectedStatus = fedv1a1.PropagatedVersionStatus{
 					TemplateVersion: templateVersion,
 					OverrideVersion: "",
-					ClusterVersions: version.VersionMapToClusterVersions(versionMap),
+					ClusterVersions: version.VersionMapToClusterVersions(versionMap, fedObject.GetGeneration()),
 				}
 
 				versionManager = version.NewVersionManager(client, namespaced, federatedKind, targetKind, versionNamespace)
@@ -364,7 +370,7 @@ var _ = Describe("VersionManager", func() {
 				if err != nil {
 					tl.Fatalf("Error updating version status: %v", err)
 				}
-				expectedStatus.ClusterVersions = version.VersionMapToClusterVersions(versionMap)
+				expectedStatus.ClusterVersions = version.VersionMapToClusterVersions(versionMap, fedObject.GetGeneration())
 				waitForPropVer(tl, adapter, client, versionName, expectedStatus)
 			})
 
@@ -398,7 +404,7 @@ var _ = Describe("VersionManager", func() {
 				if err != nil {
 					tl.Fatalf("Error updating version status: %v", err)
 				}
-				expectedStatus.ClusterVersions = version.VersionMapToClusterVersions(versionMap)
+				expectedStatus.ClusterVersions = version.VersionMapToClusterVersions(versionMap, fedObject.GetGeneration())
 				waitForPropVer(tl, adapter, client, versionName, expectedStatus)
 			})
 
@@ -421,7 +427,7 @@ var _ = Describe("VersionManager", func() {
 				if err != nil {
 					tl.Fatalf("Error updating version status: %v", err)
 				}
-				expectedStatus.ClusterVersions = version.VersionMapToClusterVersions(versionMap)
+				expectedStatus.ClusterVersions = version.VersionMapToClusterVersions(versionMap, fedObject.GetGeneration())
 				waitForPropVer(tl, adapter, client, versionName, expectedStatus)
 			})
 